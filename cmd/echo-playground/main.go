@@ -1,30 +1,112 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
 
 	"echo-playground/internal"
+	"echo-playground/internal/chat"
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/logging"
+	"echo-playground/pkg/metrics"
 	custommiddleware "echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+	"echo-playground/pkg/scanner"
+	"echo-playground/pkg/signing"
+	"echo-playground/pkg/storage"
+	"echo-playground/pkg/tracing"
+	"echo-playground/pkg/utils"
 )
 
 func main() {
-	// Criar instância do Echo
-	e := echo.New()
+	// Carregar configuração
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "config/config.yaml"
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// Configurar logger
-	e.Logger.SetLevel(1) // Debug level
+	// Logger estruturado: nível e formato vêm de logging na configuração.
+	// Também recebe o que for escrito pelo pacote log.
+	logger, err := logging.New(cfg.Logging, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(logger)
 
-	// Middleware global
-	e.Use(echomiddleware.Logger())
-	e.Use(echomiddleware.Recover())
-	e.Use(echomiddleware.CORS())
-	e.Use(custommiddleware.CustomLogger())
+	// Tracing com OpenTelemetry: exportador stdout, OTLP ou desativado
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		fatal("configuração de tracing inválida", err)
+	}
+
+	converter, err := money.NewConverter(cfg.Currency.Base, cfg.Currency.Rates)
+	if err != nil {
+		fatal("configuração de moedas inválida", err)
+	}
+
+	store, err := storage.Open(cfg.Storage, cfg.Upload.Directory)
+	if err != nil {
+		fatal("falha ao abrir o armazenamento", err)
+	}
+	store = storage.WithTracing(store, cfg.Storage.Driver)
+
+	// Criar instância do Echo; o início do servidor é registrado pelo slog
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+
+	// Métricas no formato do Prometheus, expostas em /metrics
+	appMetrics := metrics.New()
+
+	// Middleware global. O RequestID e o span da requisição vêm primeiro,
+	// para que o request_id e o trace_id constem em todos os logs; as
+	// métricas e o AccessLog em seguida, para registrar o status final de
+	// cada requisição, inclusive após um panic.
+	e.Use(custommiddleware.RequestIDMiddleware())
+	e.Use(custommiddleware.TracingMiddleware())
+	e.Use(appMetrics.Middleware())
+	e.Use(custommiddleware.AccessLog(logger))
+	e.Use(echomiddleware.RecoverWithConfig(echomiddleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			logger.ErrorContext(c.Request().Context(), "panic recuperado", "error", err, "stack", string(stack))
+			return err
+		},
+	}))
+	e.Use(echomiddleware.CORSWithConfig(echomiddleware.CORSConfig{
+		AllowMethods: []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete},
+		ExposeHeaders: []string{
+			echo.HeaderLocation,
+			internal.HeaderTusResumable,
+			internal.HeaderTusVersion,
+			internal.HeaderTusExtension,
+			internal.HeaderTusMaxSize,
+			internal.HeaderUploadOffset,
+			internal.HeaderUploadLength,
+			internal.HeaderUploadExpires,
+			internal.HeaderFileID,
+			echo.HeaderXRequestID,
+		},
+	}))
 
 	// Configurar templates
 	t := internal.NewTemplate()
@@ -33,103 +115,405 @@ func main() {
 	// Configurar validação de dados
 	// e.Validator = &utils.CustomValidator{} // Comentado temporariamente
 
-	// Configurar tratamento de erros centralizado
+	// Configurar tratamento de erros centralizado; os corpos de erro em JSON
+	// levam o request_id
 	e.HTTPErrorHandler = internal.CustomErrorHandler
+	e.JSONSerializer = custommiddleware.JSONSerializer{}
+
+	// Criar repositórios em memória. Os workers param com SIGINT ou SIGTERM.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	seededProducts, err := seedProducts(converter)
+	if err != nil {
+		fatal("falha ao criar os produtos de demonstração", err)
+	}
+	productRepo := repository.NewProductRepository(seededProducts...)
+	productRepo.StartReservationJanitor(ctx, time.Minute, time.Hour)
+	cartRepo := repository.NewCartRepository(converter.Base())
+	orderRepo := repository.NewOrderRepository()
+	reviewRepo := repository.NewReviewRepository()
+	seededUsers, err := seedUsers()
+	if err != nil {
+		fatal("falha ao criar os usuários de demonstração", err)
+	}
+	userRepo := repository.NewUserRepository(seededUsers...)
+	fileRepo := repository.NewFileRepository()
+	webhookRepo := repository.NewWebhookRepository()
+
+	// Barramento de eventos de domínio: os repositórios publicam as
+	// alterações e os interessados (como o feed SSE) assinam
+	bus := eventbus.New(eventbus.Options{
+		OnError: func(err error) { logger.Error("evento não tratado", "error", err) },
+	})
+	defer bus.Close()
+	productRepo.SetEventBus(bus)
+	userRepo.SetEventBus(bus)
+
+	// Webhooks de saída: as alterações viram entregas assinadas, enviadas
+	// em segundo plano com novas tentativas
+	webhookDispatcher := internal.NewWebhookDispatcher(webhookRepo, bus, cfg.Webhooks)
+	webhookDispatcher.StartWorker(ctx, 5*time.Second)
 
 	// Criar handlers
 	handlers := internal.NewHandlers()
-	productHandlers := internal.NewProductHandlers()
+	userHandlers := internal.NewUserHandlers(userRepo)
+	fileScanner, err := scanner.Open(cfg.Upload.Scan)
+	if err != nil {
+		fatal("scanner de arquivos inválido", err)
+	}
+	fileService := internal.NewFileService(store, fileRepo, fileScanner, cfg.Upload)
+	fileService.SetMetrics(appMetrics)
+	fileService.StartScanWorker(ctx, time.Minute)
+	signer, err := newSigner(cfg.Upload)
+	if err != nil {
+		fatal("falha ao criar a chave de links assinados", err)
+	}
+	fileHandlers := internal.NewFileHandlers(fileService, signer)
+	tusHandlers, err := internal.NewTusHandlers(fileService, filepath.Join(cfg.Upload.Directory, ".tus"), "/api/v1/tus/files", cfg.Upload.TusExpiration)
+	if err != nil {
+		fatal("falha ao preparar uploads resumíveis", err)
+	}
+	tusHandlers.StartJanitor(ctx, time.Minute)
+	productHandlers := internal.NewProductHandlers(productRepo, reviewRepo, converter)
+	productEventHandlers := internal.NewProductEventHandlers(bus, internal.ProductEventsReplay, internal.ProductEventsHeartbeat)
+	productEventHandlers.SetMetrics(appMetrics)
+	inventoryHandlers := internal.NewInventoryHandlers(productRepo)
+	cartHandlers := internal.NewCartHandlers(productRepo, cartRepo)
+	orderHandlers := internal.NewOrderHandlers(productRepo, cartRepo, orderRepo)
+	reviewHandlers := internal.NewReviewHandlers(productRepo, reviewRepo)
+	streamHandlers := internal.NewStreamHandlers(productRepo, userRepo)
+	webhookHandlers := internal.NewWebhookHandlers(webhookRepo, webhookDispatcher)
+	wsHandlers := internal.NewWebSocketHandlers(cfg.WebSocket, chat.NewHub(cfg.WebSocket.ChatHistory))
+	wsHandlers.SetMetrics(appMetrics)
+
+	registerRoutes(e, appHandlers{
+		base:          handlers,
+		users:         userHandlers,
+		files:         fileHandlers,
+		tus:           tusHandlers,
+		products:      productHandlers,
+		productEvents: productEventHandlers,
+		inventory:     inventoryHandlers,
+		cart:          cartHandlers,
+		orders:        orderHandlers,
+		reviews:       reviewHandlers,
+		stream:        streamHandlers,
+		webhooks:      webhookHandlers,
+		ws:            wsHandlers,
+		metrics:       appMetrics.Handler(),
+	})
+
+	// Obter porta da variável de ambiente ou usar a da configuração
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = strconv.Itoa(cfg.Server.Port)
+	}
+
+	// Configurar servidor HTTP/2
+	server := &http.Server{
+		Addr:         cfg.Server.Host + ":" + port,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// Iniciar servidor
+	logger.Info("Echo Playground iniciado",
+		"addr", server.Addr,
+		"docs", "http://localhost:"+port+"/api/v1/",
+		"features", []string{
+			"Router otimizado",
+			"Middleware customizado",
+			"Data binding",
+			"Múltiplos formatos de resposta",
+			"Upload/Download de arquivos",
+			"Autenticação JWT",
+			"CRUD completo",
+			"Streaming",
+			"WebSocket",
+			"Webhooks assinados",
+			"Templates HTML",
+			"Tratamento de erros centralizado",
+			"Logs estruturados",
+			"Métricas Prometheus",
+			"Tracing OpenTelemetry",
+			"Arquitetura modular Go",
+		},
+	)
+
+	// Com SIGINT ou SIGTERM, aguarda as requisições em andamento e envia os
+	// spans pendentes antes de sair
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancelShutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logger.Error("falha ao encerrar o servidor", "error", err)
+		}
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error("falha ao enviar os spans pendentes", "error", err)
+		}
+	}()
+
+	if err := e.StartServer(server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatal("servidor encerrado", err)
+	}
+	<-stopped
+	logger.Info("Echo Playground encerrado")
+}
+
+// appHandlers reúne os handlers ligados às rotas da API
+type appHandlers struct {
+	base          *internal.Handlers
+	users         *internal.UserHandlers
+	files         *internal.FileHandlers
+	tus           *internal.TusHandlers
+	products      *internal.ProductHandlers
+	productEvents *internal.ProductEventHandlers
+	inventory     *internal.InventoryHandlers
+	cart          *internal.CartHandlers
+	orders        *internal.OrderHandlers
+	reviews       *internal.ReviewHandlers
+	stream        *internal.StreamHandlers
+	webhooks      *internal.WebhookHandlers
+	ws            *internal.WebSocketHandlers
+	metrics       http.Handler
+}
+
+// registerRoutes registra as rotas da API com seus middlewares de
+// autenticação e de papel
+func registerRoutes(e *echo.Echo, h appHandlers) {
+	auth := custommiddleware.AuthMiddleware()
+
+	// Métricas para o Prometheus (formato texto)
+	e.GET("/metrics", echo.WrapHandler(h.metrics))
 
 	// Grupo de rotas públicas
 	public := e.Group("/api/v1")
 
 	// Rotas básicas
-	public.GET("/", handlers.HomeHandler)
-	public.GET("/hello/:name", handlers.HelloHandler)
-	public.GET("/html", handlers.HTMLHandler)
-	public.GET("/xml", handlers.XMLHandler)
+	public.GET("/", h.base.HomeHandler)
+	public.GET("/hello/:name", h.base.HelloHandler)
+	public.GET("/html", h.base.HTMLHandler)
+	public.GET("/xml", h.base.XMLHandler)
 
 	// Documentação Swagger
-	public.GET("/docs", handlers.SwaggerHandler)
+	public.GET("/docs", h.base.SwaggerHandler)
 	public.File("/swagger.yaml", "api/swagger.yaml")
 	public.Static("/api-docs", "api")
 
-	// Demonstração de data binding
-	public.POST("/users", handlers.CreateUserHandler)
+	// Cadastro de usuários (demonstração de data binding)
+	public.POST("/users", h.users.CreateUserHandler)
+	public.POST("/users/:id/verify-email", h.users.VerifyEmailHandler)
 
 	// Endpoint de login para gerar token JWT
-	public.POST("/login", handlers.LoginHandler)
+	public.POST("/login", h.users.LoginHandler)
 
 	// Demonstração de query parameters
-	public.GET("/search", handlers.SearchHandler)
+	public.GET("/search", h.base.SearchHandler)
 
-	// Demonstração de upload de arquivo
-	public.POST("/upload", handlers.UploadHandler)
+	// Demonstração de upload de arquivo (autenticação opcional)
+	public.POST("/upload", h.files.UploadHandler, custommiddleware.OptionalAuth())
 
-	// Demonstração de download de arquivo
-	public.GET("/download/:filename", handlers.DownloadHandler)
+	// Demonstração de download de arquivo pelo ID do catálogo; arquivos com
+	// dono exigem autenticação ou um link assinado
+	public.GET("/download/:id", h.files.DownloadHandler, custommiddleware.OptionalAuth())
+	public.GET("/files/:id/download", h.files.DownloadHandler, custommiddleware.OptionalAuth())
+	public.GET("/files/:id/thumb", h.files.ThumbnailHandler, custommiddleware.OptionalAuth())
 
-	// Demonstração de streaming
-	public.GET("/stream", handlers.StreamHandler)
+	// Uploads resumíveis com o protocolo tus 1.0 (autenticação opcional)
+	tus := e.Group("/api/v1/tus/files", custommiddleware.OptionalAuth(), h.tus.TusResumable)
+	tus.OPTIONS("", h.tus.OptionsHandler)
+	tus.POST("", h.tus.CreateHandler)
+	tus.HEAD("/:id", h.tus.HeadHandler)
+	tus.PATCH("/:id", h.tus.PatchHandler)
+	tus.DELETE("/:id", h.tus.DeleteHandler)
 
-	// Demonstração de WebSocket (simulado)
-	public.GET("/ws", handlers.WebSocketHandler)
+	// Demonstração de streaming (?count=, ?interval= e ?format=ndjson)
+	public.GET("/stream", h.stream.StreamHandler)
+
+	// WebSocket com eco, comandos JSON e salas de chat (token no header ou
+	// em ?token=)
+	public.GET("/ws", h.ws.WebSocketHandler)
+	e.GET("/api/v1/chat/rooms", h.ws.ChatRoomsHandler, auth)
 
 	// Grupo de rotas protegidas (com autenticação)
 	protected := e.Group("/api/v1/protected")
-	protected.Use(custommiddleware.AuthMiddleware())
-
-	protected.GET("/profile", handlers.ProfileHandler)
+	protected.Use(auth)
+
+	protected.GET("/profile", h.base.ProfileHandler)
+
+	// Gerenciamento de usuários: cada usuário acessa o próprio registro e
+	// administradores acessam todos
+	users := e.Group("/api/v1/users", auth)
+	users.GET("", h.users.ListUsersHandler, custommiddleware.RequireRole(custommiddleware.RoleAdmin))
+	users.GET("/stream", h.stream.UsersStreamHandler, custommiddleware.RequireRole(custommiddleware.RoleAdmin))
+	users.GET("/:id", h.users.GetUserHandler)
+	users.PUT("/:id", h.users.UpdateUserHandler)
+	users.PATCH("/:id", h.users.PatchUserHandler)
+	users.DELETE("/:id", h.users.DeleteUserHandler)
+
+	// Catálogo de arquivos enviados
+	files := e.Group("/api/v1/files", auth)
+	files.GET("", h.files.ListFilesHandler)
+	files.GET("/archive", h.files.ArchiveHandler)
+	files.GET("/usage", h.files.UsageHandler)
+	files.GET("/:id", h.files.GetFileHandler)
+	files.POST("/:id/signed-url", h.files.SignURLHandler)
+	files.DELETE("/:id", h.files.DeleteFileHandler)
 
 	// Demonstração de CRUD completo
 	products := e.Group("/api/v1/products")
 
 	// Listar produtos
-	products.GET("", productHandlers.ListProductsHandler)
+	products.GET("", h.products.ListProductsHandler)
+
+	// Feed de alterações de produtos (Server-Sent Events)
+	products.GET("/events", h.productEvents.EventsHandler)
+
+	// Todos os produtos em NDJSON, um por linha
+	products.GET("/stream", h.stream.ProductsStreamHandler)
 
 	// Obter produto específico
-	products.GET("/:id", productHandlers.GetProductHandler)
+	products.GET("/:id", h.products.GetProductHandler)
 
 	// Criar produto
-	products.POST("", productHandlers.CreateProductHandler)
+	products.POST("", h.products.CreateProductHandler)
 
 	// Atualizar produto
-	products.PUT("/:id", productHandlers.UpdateProductHandler)
+	products.PUT("/:id", h.products.UpdateProductHandler)
 
 	// Deletar produto
-	products.DELETE("/:id", productHandlers.DeleteProductHandler)
+	products.DELETE("/:id", h.products.DeleteProductHandler)
+
+	// Estoque e reservas
+	products.GET("/:id/stock", h.inventory.GetStockHandler)
+	products.POST("/:id/stock/adjustments", h.inventory.AdjustStockHandler, auth, custommiddleware.RequireRole(custommiddleware.RoleAdmin))
+	products.GET("/:id/stock/movements", h.inventory.ListStockMovementsHandler, auth)
+	products.POST("/:id/reservations", h.inventory.CreateReservationHandler, auth)
+
+	// Avaliações
+	products.GET("/:id/reviews", h.reviews.ListReviewsHandler)
+	products.POST("/:id/reviews", h.reviews.CreateReviewHandler, auth)
+
+	reservations := e.Group("/api/v1/reservations", auth)
+	reservations.GET("/:id", h.inventory.GetReservationHandler)
+	reservations.DELETE("/:id", h.inventory.ReleaseReservationHandler)
+
+	// Carrinho de compras
+	cart := e.Group("/api/v1/cart", auth)
+	cart.GET("", h.cart.GetCartHandler)
+	cart.DELETE("", h.cart.ClearCartHandler)
+	cart.POST("/items", h.cart.AddCartItemHandler)
+	cart.PUT("/items/:product_id", h.cart.UpdateCartItemHandler)
+	cart.DELETE("/items/:product_id", h.cart.RemoveCartItemHandler)
+
+	// Checkout e pedidos
+	orders := e.Group("/api/v1/orders", auth)
+	orders.POST("", h.orders.CheckoutHandler)
+	orders.GET("", h.orders.ListOrdersHandler)
+	orders.GET("/:id", h.orders.GetOrderHandler)
+	orders.PATCH("/:id", h.orders.UpdateOrderStatusHandler)
+
+	// Grupo de rotas administrativas
+	admin := e.Group("/api/v1/admin", auth, custommiddleware.RequireRole(custommiddleware.RoleAdmin))
+
+	// Moderação de avaliações
+	admin.GET("/reviews", h.reviews.ListModerationReviewsHandler)
+	admin.POST("/reviews/:id/hide", h.reviews.HideReviewHandler)
+	admin.POST("/reviews/:id/unhide", h.reviews.UnhideReviewHandler)
+	admin.POST("/files/:id/rescan", h.files.RescanHandler)
+
+	// Webhooks e registro de entregas
+	admin.GET("/webhooks", h.webhooks.ListWebhooksHandler)
+	admin.POST("/webhooks", h.webhooks.CreateWebhookHandler)
+	admin.GET("/webhooks/:id", h.webhooks.GetWebhookHandler)
+	admin.PUT("/webhooks/:id", h.webhooks.UpdateWebhookHandler)
+	admin.DELETE("/webhooks/:id", h.webhooks.DeleteWebhookHandler)
+	admin.POST("/webhooks/:id/ping", h.webhooks.PingWebhookHandler)
+	admin.GET("/webhooks/:id/deliveries", h.webhooks.ListDeliveriesHandler)
+	admin.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", h.webhooks.RedeliverHandler)
+}
 
-	// Obter porta da variável de ambiente ou usar padrão
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+// fatal registra o erro e encerra o processo
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// seedProducts retorna os produtos iniciais do catálogo de demonstração,
+// com preços convertidos para a moeda base configurada. Uma falha na
+// conversão, como uma moeda sem cotação, impede a inicialização em vez de
+// deixar o produto com o preço errado.
+func seedProducts(converter *money.Converter) ([]*models.Product, error) {
+	products := []*models.Product{
+		models.NewProduct("Laptop", "Laptop de alta performance", "Eletrônicos", money.MustParse("2999.99", "BRL")),
+		models.NewProduct("Mouse", "Mouse sem fio", "Acessórios", money.MustParse("89.99", "BRL")),
+		models.NewProduct("Teclado", "Teclado mecânico", "Acessórios", money.MustParse("199.99", "BRL")),
 	}
+	for _, p := range products {
+		price, err := converter.Convert(p.Price, converter.Base())
+		if err != nil {
+			return nil, fmt.Errorf("preço de %s: %w", p.Name, err)
+		}
+		p.Price = price
+	}
+	products[0].Stock = 10
+	products[1].Stock = 50
+	products[2].Stock = 25
+	return products, nil
+}
 
-	// Configurar servidor HTTP/2
-	server := &http.Server{
-		Addr:         ":" + port,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  120 * time.Second,
+// seedUsers retorna os usuários iniciais de demonstração, incluindo um
+// administrador que pode fazer login com o e-mail admin@exemplo.com e a
+// senha de ADMIN_PASSWORD. Sem ela, uma senha aleatória é gerada e escrita
+// uma única vez em stderr, fora dos logs estruturados, que registram apenas
+// o aviso. Os demais usuários não têm senha até que um administrador
+// defina uma.
+func seedUsers() ([]*models.User, error) {
+	users := []*models.User{
+		models.NewUser("Administrador", "admin@exemplo.com", 35),
+		models.NewUser("Maria Silva", "maria.silva@exemplo.com", 28),
+		models.NewUser("João Santos", "joao.santos@exemplo.com", 32),
+		models.NewUser("Ana Costa", "ana.costa@exemplo.com", 25),
 	}
+	for _, u := range users {
+		u.Role = custommiddleware.RoleUser
+		u.EmailVerified = true
+	}
+	users[0].Role = custommiddleware.RoleAdmin
 
-	// Iniciar servidor
-	log.Printf("🚀 Echo Playground iniciado na porta %s", port)
-	log.Printf("📖 Documentação disponível em: http://localhost:%s/api/v1/", port)
-	log.Println("🔧 Recursos demonstrados:")
-	log.Println("   - Router otimizado")
-	log.Println("   - Middleware customizado")
-	log.Println("   - Data binding")
-	log.Println("   - Múltiplos formatos de resposta")
-	log.Println("   - Upload/Download de arquivos")
-	log.Println("   - Autenticação JWT")
-	log.Println("   - CRUD completo")
-	log.Println("   - Streaming")
-	log.Println("   - Templates HTML")
-	log.Println("   - Tratamento de erros centralizado")
-	log.Println("   - Arquitetura modular Go")
-
-	if err := e.StartServer(server); err != nil {
-		log.Fatal(err)
+	password := os.Getenv("ADMIN_PASSWORD")
+	if password == "" {
+		password = utils.NewID()
+		slog.Warn("ADMIN_PASSWORD não configurada; a senha temporária do administrador foi escrita em stderr", "email", users[0].Email)
+		fmt.Fprintf(os.Stderr, "\n*** SENHA TEMPORÁRIA DO ADMINISTRADOR (%s): %s ***\n*** Exibida apenas uma vez; defina ADMIN_PASSWORD para fixá-la. ***\n\n", users[0].Email, password)
+	}
+	if err := users[0].SetPassword(password); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// newSigner cria o assinador dos links de download. A chave vem da
+// configuração ou de DOWNLOAD_SIGNING_KEY; sem nenhuma das duas, é gerada
+// uma chave aleatória, válida apenas enquanto o processo estiver rodando.
+func newSigner(cfg config.UploadConfig) (*signing.Signer, error) {
+	key := cfg.SigningKey
+	if key == "" {
+		key = os.Getenv("DOWNLOAD_SIGNING_KEY")
+	}
+	if key != "" {
+		return signing.NewSigner([]byte(key)), nil
+	}
+
+	slog.Warn("chave de links assinados não configurada; usando uma chave temporária")
+	random, err := signing.RandomKey()
+	if err != nil {
+		return nil, err
 	}
+	return signing.NewSigner(random), nil
 }
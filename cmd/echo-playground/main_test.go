@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+
+	"echo-playground/internal"
+	"echo-playground/internal/repository"
+	custommiddleware "echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+)
+
+func TestRegisterRoutes_AdminOnly(t *testing.T) {
+	p := models.NewProduct("Laptop", "Laptop de alta performance", "Eletrônicos", money.MustParse("2999.99", "BRL"))
+	p.Stock = 2
+	products := repository.NewProductRepository(p)
+
+	// Só o handler de estoque é real; os demais ficam nulos e, se a rota
+	// chegar até eles, o Recover transforma o panic em 500
+	e := echo.New()
+	e.Use(echomiddleware.Recover())
+	registerRoutes(e, appHandlers{inventory: internal.NewInventoryHandlers(products)})
+
+	routes := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{http.MethodPost, "/api/v1/products/1/stock/adjustments", `{"delta":5,"reason":"restock"}`},
+		{http.MethodGet, "/api/v1/users", ""},
+		{http.MethodGet, "/api/v1/users/stream", ""},
+		{http.MethodGet, "/api/v1/admin/reviews", ""},
+		{http.MethodGet, "/api/v1/admin/webhooks", ""},
+	}
+	for _, route := range routes {
+		req := httptest.NewRequest(route.method, route.path, strings.NewReader(route.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+custommiddleware.DemoToken)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for non-admin on %s %s, got %d", route.method, route.path, rec.Code)
+		}
+	}
+
+	if level, _ := products.Stock(context.Background(), 1); level.OnHand != 2 {
+		t.Errorf("Expected stock to stay at 2, got %d", level.OnHand)
+	}
+}
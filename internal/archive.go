@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MaxArchiveFiles é o número máximo de arquivos em um pacote zip
+const MaxArchiveFiles = 100
+
+// ArchiveName é o nome sugerido para o pacote zip baixado
+const ArchiveName = "arquivos.zip"
+
+// ArchiveHandler envia um pacote zip com os arquivos informados em ?ids=
+// (separados por vírgula ou repetidos). O zip é montado enquanto é enviado,
+// sem arquivo temporário; por isso todos os arquivos são validados antes do
+// primeiro byte da resposta.
+func (h *FileHandlers) ArchiveHandler(c echo.Context) error {
+	ids := archiveIDs(c.QueryParams()["ids"])
+	if len(ids) == 0 {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Informe os arquivos em ids", ""))
+	}
+	if len(ids) > MaxArchiveFiles {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(
+			fmt.Sprintf("Máximo de %d arquivos por pacote", MaxArchiveFiles), ""))
+	}
+
+	files := make([]*models.File, 0, len(ids))
+	for _, id := range ids {
+		file, ok := h.ownedFileByID(c, id)
+		if !ok {
+			return c.JSON(http.StatusNotFound, api.NewErrorResponse("Arquivo não encontrado", id))
+		}
+		if !file.Downloadable() {
+			status, message := unavailableFile(file)
+			return c.JSON(status, api.NewErrorResponse(message, id))
+		}
+		files = append(files, file)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, "application/zip")
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": ArchiveName}))
+	header.Set("X-Content-Type-Options", "nosniff")
+	disableWriteDeadline(c.Response())
+	c.Response().WriteHeader(http.StatusOK)
+
+	// A partir daqui o status já foi enviado; um erro apenas interrompe o
+	// zip, que o cliente detecta como arquivo truncado
+	zw := zip.NewWriter(c.Response())
+	names := make(map[string]int, len(files))
+	for _, file := range files {
+		if err := h.addToArchive(c, zw, file, uniqueArchiveName(names, file.OriginalName)); err != nil {
+			return err
+		}
+		c.Response().Flush()
+	}
+	return zw.Close()
+}
+
+// addToArchive copia o conteúdo de um arquivo para uma nova entrada do zip
+func (h *FileHandlers) addToArchive(c echo.Context, zw *zip.Writer, file *models.File, name string) error {
+	content, _, err := h.files.Open(c.Request().Context(), file)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	w, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   archiveMethod(file.ContentType),
+		Modified: file.UploadedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, content)
+	return err
+}
+
+// archiveIDs junta os IDs informados, aceitando listas separadas por
+// vírgula, e descarta repetições
+func archiveIDs(values []string) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, value := range values {
+		for _, id := range strings.Split(value, ",") {
+			id = strings.TrimSpace(id)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// uniqueArchiveName evita entradas com o mesmo nome no zip, numerando as
+// repetições como "nome (2).ext"
+func uniqueArchiveName(names map[string]int, name string) string {
+	names[name]++
+	if names[name] == 1 {
+		return name
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for {
+		candidate := fmt.Sprintf("%s (%d)%s", base, names[name], ext)
+		if names[candidate] == 0 {
+			names[candidate]++
+			return candidate
+		}
+		names[name]++
+	}
+}
+
+// archiveMethod armazena sem compressão os formatos que já são comprimidos
+func archiveMethod(contentType string) uint16 {
+	switch {
+	case strings.HasPrefix(contentType, "image/jpeg"),
+		strings.HasPrefix(contentType, "image/png"),
+		strings.HasPrefix(contentType, "image/gif"),
+		strings.HasPrefix(contentType, "application/zip"):
+		return zip.Store
+	}
+	return zip.Deflate
+}
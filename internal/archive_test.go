@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/middleware"
+)
+
+func archive(h *FileHandlers, query string, userID int, role string) *httptest.ResponseRecorder {
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodGet, "/files/archive?"+query, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middleware.ContextKeyUserID, userID)
+	c.Set(middleware.ContextKeyRole, role)
+
+	_ = h.ArchiveHandler(c)
+	return rec
+}
+
+func TestFileHandlers_ArchiveHandler(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+	first := uploadFile(t, h, "nota.txt", []byte("primeira"))
+	second := uploadFile(t, h, "nota.txt", []byte("segunda"))
+	image := uploadFile(t, h, "foto.png", pngImage)
+
+	rec := archive(h, "ids="+first.ID+","+second.ID+"&ids="+image.ID+"&ids="+first.ID, 1, middleware.RoleAdmin)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Expected application/zip, got %s", ct)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+
+	expected := []struct {
+		name    string
+		content string
+		method  uint16
+	}{
+		{"nota.txt", "primeira", zip.Deflate},
+		{"nota (2).txt", "segunda", zip.Deflate},
+		{"foto.png", string(pngImage), zip.Store},
+	}
+	if len(zr.File) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(zr.File))
+	}
+	for i, want := range expected {
+		entry := zr.File[i]
+		if entry.Name != want.name || entry.Method != want.method {
+			t.Errorf("Expected entry %s (method %d), got %s (method %d)", want.name, want.method, entry.Name, entry.Method)
+		}
+		r, _ := entry.Open()
+		content, _ := io.ReadAll(r)
+		r.Close()
+		if string(content) != want.content {
+			t.Errorf("Expected %q in %s, got %q", want.content, entry.Name, content)
+		}
+	}
+}
+
+func TestFileHandlers_ArchiveHandler_Rejections(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+	file := uploadFile(t, h, "nota.txt", []byte("anônimo"))
+
+	if rec := archive(h, "", 1, middleware.RoleAdmin); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without ids, got %d", rec.Code)
+	}
+	if rec := archive(h, "ids="+file.ID+",inexistente", 1, middleware.RoleAdmin); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown id, got %d", rec.Code)
+	}
+	if rec := archive(h, "ids="+file.ID, 7, middleware.RoleUser); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another user's file, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"net/http"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CartHandlers contém os handlers do carrinho de compras
+type CartHandlers struct {
+	products *repository.ProductRepository
+	carts    *repository.CartRepository
+}
+
+// NewCartHandlers cria uma nova instância de handlers do carrinho
+func NewCartHandlers(products *repository.ProductRepository, carts *repository.CartRepository) *CartHandlers {
+	return &CartHandlers{products: products, carts: carts}
+}
+
+// CartItemRequest representa a inclusão ou alteração de uma linha do carrinho
+type CartItemRequest struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// GetCartHandler retorna o carrinho do usuário autenticado
+func (h *CartHandlers) GetCartHandler(c echo.Context) error {
+	userID, _ := middleware.UserID(c)
+	cart := h.carts.Get(c.Request().Context(), userID)
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Carrinho consultado com sucesso", cart))
+}
+
+// AddCartItemHandler adiciona um produto ao carrinho
+func (h *CartHandlers) AddCartItemHandler(c echo.Context) error {
+	req := new(CartItemRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar item do carrinho", err.Error()))
+	}
+
+	ctx := c.Request().Context()
+	product, err := h.products.Get(ctx, req.ProductID)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	userID, _ := middleware.UserID(c)
+	cart, err := h.carts.AddItem(ctx, userID, models.CartItem{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Quantity:  req.Quantity,
+		UnitPrice: product.Price,
+	})
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Item adicionado ao carrinho", cart))
+}
+
+// UpdateCartItemHandler altera a quantidade de uma linha do carrinho
+func (h *CartHandlers) UpdateCartItemHandler(c echo.Context) error {
+	productID, err := intParam(c, "product_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	req := new(CartItemRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar item do carrinho", err.Error()))
+	}
+
+	userID, _ := middleware.UserID(c)
+	cart, err := h.carts.SetItem(c.Request().Context(), userID, productID, req.Quantity)
+	if err != nil {
+		return repositoryError(c, err, "Item não encontrado no carrinho")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Carrinho atualizado com sucesso", cart))
+}
+
+// RemoveCartItemHandler remove uma linha do carrinho
+func (h *CartHandlers) RemoveCartItemHandler(c echo.Context) error {
+	productID, err := intParam(c, "product_id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	userID, _ := middleware.UserID(c)
+	cart, err := h.carts.RemoveItem(c.Request().Context(), userID, productID)
+	if err != nil {
+		return repositoryError(c, err, "Item não encontrado no carrinho")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Item removido do carrinho", cart))
+}
+
+// ClearCartHandler esvazia o carrinho do usuário autenticado
+func (h *CartHandlers) ClearCartHandler(c echo.Context) error {
+	userID, _ := middleware.UserID(c)
+	h.carts.Clear(c.Request().Context(), userID)
+
+	return c.JSON(http.StatusOK, api.NewSuccessMessage("Carrinho esvaziado com sucesso"))
+}
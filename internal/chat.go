@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"echo-playground/internal/chat"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// Comandos de chat do protocolo de /ws
+const (
+	WSTypeJoin     = "join"
+	WSTypeJoined   = "joined"
+	WSTypeLeave    = "leave"
+	WSTypeLeft     = "left"
+	WSTypeSend     = "send"
+	WSTypePresence = "presence"
+	WSTypeRooms    = "rooms"
+)
+
+// ChatCommand é o campo data dos comandos de chat
+type ChatCommand struct {
+	Room string `json:"room"`
+	Text string `json:"text,omitempty"`
+}
+
+// wsChatClient liga uma conexão WebSocket ao hub de chat. Eventos são
+// enfileirados sem bloquear; um cliente com a fila cheia é desconectado
+// com o código 1008.
+type wsChatClient struct {
+	*wsConn
+}
+
+// Deliver implementa chat.Client
+func (c *wsChatClient) Deliver(e chat.Event) bool {
+	return c.SendJSON(WSReply{Type: e.Type, Data: e})
+}
+
+// Disconnect implementa chat.Client
+func (c *wsChatClient) Disconnect(reason string) {
+	c.CloseWith(websocket.ClosePolicyViolation, reason)
+}
+
+// handleChat executa um comando de chat e preenche a resposta. Retorna false
+// quando não há resposta direta: uma mensagem enviada chega ao remetente
+// como evento da sala.
+func (h *WebSocketHandlers) handleChat(client *wsChatClient, claims *middleware.Claims, msg WSMessage, reply *WSReply) bool {
+	var cmd ChatCommand
+	if msg.Type != WSTypeRooms {
+		if err := json.Unmarshal(msg.Data, &cmd); err != nil {
+			reply.Type = WSTypeError
+			reply.Error = "Dados do comando inválidos"
+			return true
+		}
+	}
+
+	var err error
+	switch msg.Type {
+	case WSTypeJoin:
+		var history []chat.Message
+		var members []chat.Member
+		member := chat.Member{UserID: claims.UserID, Username: claims.Username}
+		history, members, err = h.hub.Join(cmd.Room, client, member)
+		if err == nil {
+			room, _ := chat.RoomName(cmd.Room)
+			reply.Type = WSTypeJoined
+			reply.Data = map[string]interface{}{"room": room, "members": members, "history": history}
+		}
+	case WSTypeLeave:
+		if err = h.hub.Leave(cmd.Room, client); err == nil {
+			room, _ := chat.RoomName(cmd.Room)
+			reply.Type = WSTypeLeft
+			reply.Data = map[string]interface{}{"room": room}
+		}
+	case WSTypeSend:
+		if _, err = h.hub.Broadcast(cmd.Room, client, cmd.Text); err == nil {
+			return false
+		}
+	case WSTypePresence:
+		var members []chat.Member
+		if members, err = h.hub.Presence(cmd.Room); err == nil {
+			room, _ := chat.RoomName(cmd.Room)
+			reply.Data = map[string]interface{}{"room": room, "members": members}
+		}
+	case WSTypeRooms:
+		reply.Data = h.hub.Rooms()
+	}
+
+	if err != nil {
+		reply.Type = WSTypeError
+		reply.Error = err.Error()
+	}
+	return true
+}
+
+// ChatRoomsHandler lista as salas de chat ativas e o número de membros
+func (h *WebSocketHandlers) ChatRoomsHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Salas de chat", h.hub.Rooms()))
+}
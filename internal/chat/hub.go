@@ -0,0 +1,346 @@
+// Package chat implementa salas de bate-papo com presença e histórico
+// recente, independentes do transporte usado pelos clientes.
+package chat
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTextLength é o número máximo de caracteres de uma mensagem
+const MaxTextLength = 2000
+
+// Erros retornados pelo hub
+var (
+	ErrInvalidRoom    = errors.New("nome de sala inválido")
+	ErrNotMember      = errors.New("cliente não está na sala")
+	ErrAlreadyInRoom  = errors.New("cliente já está na sala")
+	ErrTooManyRooms   = errors.New("limite de salas por cliente atingido")
+	ErrEmptyMessage   = errors.New("mensagem vazia")
+	ErrMessageTooLong = errors.New("mensagem muito longa")
+	ErrClientClosed   = errors.New("cliente desconectado")
+)
+
+// MaxRoomsPerClient limita as salas em que uma conexão pode estar ao mesmo tempo
+const MaxRoomsPerClient = 20
+
+// SlowConsumer é o motivo informado ao desconectar um cliente lento
+const SlowConsumer = "fila de envio cheia"
+
+var roomNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Tipos de evento entregues aos clientes
+const (
+	EventMessage = "message"
+	EventJoined  = "member_joined"
+	EventLeft    = "member_left"
+)
+
+// Member identifica um usuário presente em uma sala
+type Member struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// Message é uma mensagem enviada a uma sala
+type Message struct {
+	ID     int64     `json:"id"`
+	Room   string    `json:"room"`
+	From   Member    `json:"from"`
+	Text   string    `json:"text"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+// Event é entregue aos membros de uma sala: uma nova mensagem ou a entrada
+// ou saída de um usuário. Type fica a cargo do transporte, que o usa para
+// identificar o evento.
+type Event struct {
+	Type    string   `json:"-"`
+	Room    string   `json:"room"`
+	Message *Message `json:"message,omitempty"`
+	Member  *Member  `json:"member,omitempty"`
+}
+
+// RoomInfo resume uma sala ativa
+type RoomInfo struct {
+	Name    string `json:"name"`
+	Members int    `json:"members"`
+}
+
+// Client é uma conexão participante do hub
+type Client interface {
+	// Deliver enfileira um evento sem bloquear; false indica que a fila do
+	// cliente está cheia
+	Deliver(Event) bool
+	// Disconnect encerra a conexão de um cliente lento
+	Disconnect(reason string)
+}
+
+// room guarda as conexões de uma sala e suas mensagens recentes
+type room struct {
+	clients map[Client]Member
+	users   map[int]int // conexões por usuário
+	history []Message
+}
+
+// Hub distribui mensagens entre os clientes de cada sala. Um cliente cuja
+// fila está cheia é desconectado em vez de bloquear o hub.
+type Hub struct {
+	mu      sync.Mutex
+	rooms   map[string]*room
+	joined  map[Client]map[string]bool
+	history int
+	nextID  int64
+	now     func() time.Time
+}
+
+// NewHub cria um hub que guarda as últimas history mensagens de cada sala
+func NewHub(history int) *Hub {
+	return &Hub{
+		rooms:   make(map[string]*room),
+		joined:  make(map[Client]map[string]bool),
+		history: history,
+		nextID:  1,
+		now:     time.Now,
+	}
+}
+
+// RoomName normaliza e valida o nome de uma sala: letras minúsculas,
+// números, "-" e "_", com até 64 caracteres
+func RoomName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !roomNamePattern.MatchString(name) {
+		return "", ErrInvalidRoom
+	}
+	return name, nil
+}
+
+// Join adiciona o cliente à sala e retorna o histórico recente e os membros
+// presentes. A entrada é anunciada apenas na primeira conexão do usuário.
+func (h *Hub) Join(name string, c Client, m Member) ([]Message, []Member, error) {
+	name, err := RoomName(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rooms := h.joined[c]
+	if rooms[name] {
+		return nil, nil, ErrAlreadyInRoom
+	}
+	if len(rooms) >= MaxRoomsPerClient {
+		return nil, nil, ErrTooManyRooms
+	}
+
+	r := h.rooms[name]
+	if r == nil {
+		r = &room{clients: make(map[Client]Member), users: make(map[int]int)}
+		h.rooms[name] = r
+	}
+	if rooms == nil {
+		rooms = make(map[string]bool)
+		h.joined[c] = rooms
+	}
+	rooms[name] = true
+	r.clients[c] = m
+	r.users[m.UserID]++
+
+	if r.users[m.UserID] == 1 {
+		member := m
+		h.broadcastLocked(name, Event{Type: EventJoined, Room: name, Member: &member}, c)
+	}
+
+	// O próprio cliente pode ter sido desconectado durante o anúncio
+	if h.rooms[name] == nil || !h.joined[c][name] {
+		return nil, nil, ErrClientClosed
+	}
+	history := append([]Message(nil), r.history...)
+	return history, presence(r), nil
+}
+
+// Leave remove o cliente da sala
+func (h *Hub) Leave(name string, c Client) error {
+	name, err := RoomName(name)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.joined[c][name] {
+		return ErrNotMember
+	}
+	h.leaveLocked(name, c)
+	return nil
+}
+
+// LeaveAll remove o cliente de todas as salas; usado ao fechar a conexão
+func (h *Hub) LeaveAll(c Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.dropLocked(c)
+}
+
+// Broadcast envia uma mensagem do cliente a todos os membros da sala,
+// inclusive ao próprio remetente, e a guarda no histórico
+func (h *Hub) Broadcast(name string, c Client, text string) (*Message, error) {
+	name, err := RoomName(name)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(text) > MaxTextLength {
+		return nil, ErrMessageTooLong
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r := h.rooms[name]
+	if r == nil || !h.joined[c][name] {
+		return nil, ErrNotMember
+	}
+
+	msg := Message{
+		ID:     h.nextID,
+		Room:   name,
+		From:   r.clients[c],
+		Text:   text,
+		SentAt: h.now().UTC(),
+	}
+	h.nextID++
+
+	if h.history > 0 {
+		r.history = append(r.history, msg)
+		if len(r.history) > h.history {
+			r.history = append([]Message(nil), r.history[len(r.history)-h.history:]...)
+		}
+	}
+
+	h.broadcastLocked(name, Event{Type: EventMessage, Room: name, Message: &msg}, nil)
+	return &msg, nil
+}
+
+// Presence retorna os usuários presentes na sala, ordenados pelo nome
+func (h *Hub) Presence(name string) ([]Member, error) {
+	name, err := RoomName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r := h.rooms[name]
+	if r == nil {
+		return []Member{}, nil
+	}
+	return presence(r), nil
+}
+
+// Rooms lista as salas com ao menos um membro, ordenadas pelo nome
+func (h *Hub) Rooms() []RoomInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rooms := make([]RoomInfo, 0, len(h.rooms))
+	for name, r := range h.rooms {
+		rooms = append(rooms, RoomInfo{Name: name, Members: len(r.users)})
+	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].Name < rooms[j].Name })
+	return rooms
+}
+
+// broadcastLocked entrega o evento a todos os clientes da sala, exceto skip.
+// Clientes com a fila cheia são desconectados e removidos de todas as salas.
+func (h *Hub) broadcastLocked(name string, event Event, skip Client) {
+	r := h.rooms[name]
+	if r == nil {
+		return
+	}
+
+	var slow []Client
+	for c := range r.clients {
+		if c != skip && !c.Deliver(event) {
+			slow = append(slow, c)
+		}
+	}
+	for _, c := range slow {
+		// Um cliente pode já ter sido removido ao anunciar a saída de outro
+		if h.joined[c] != nil {
+			c.Disconnect(SlowConsumer)
+			h.dropLocked(c)
+		}
+	}
+}
+
+// dropLocked remove o cliente de todas as salas em que está
+func (h *Hub) dropLocked(c Client) {
+	rooms := h.joined[c]
+	names := make([]string, 0, len(rooms))
+	for name := range rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h.leaveLocked(name, c)
+	}
+}
+
+// leaveLocked remove o cliente da sala, anuncia a saída quando era a última
+// conexão do usuário e descarta a sala, com seu histórico, quando fica vazia
+func (h *Hub) leaveLocked(name string, c Client) {
+	if !h.joined[c][name] {
+		return
+	}
+	delete(h.joined[c], name)
+	if len(h.joined[c]) == 0 {
+		delete(h.joined, c)
+	}
+
+	r := h.rooms[name]
+	m := r.clients[c]
+	delete(r.clients, c)
+	r.users[m.UserID]--
+	if r.users[m.UserID] > 0 {
+		return
+	}
+	delete(r.users, m.UserID)
+
+	if len(r.clients) == 0 {
+		delete(h.rooms, name)
+		return
+	}
+	h.broadcastLocked(name, Event{Type: EventLeft, Room: name, Member: &m}, nil)
+}
+
+// presence lista os usuários distintos da sala
+func presence(r *room) []Member {
+	seen := make(map[int]bool, len(r.users))
+	members := make([]Member, 0, len(r.users))
+	for _, m := range r.clients {
+		if !seen[m.UserID] {
+			seen[m.UserID] = true
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Username != members[j].Username {
+			return members[i].Username < members[j].Username
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members
+}
@@ -0,0 +1,218 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeClient guarda os eventos recebidos até o limite da fila
+type fakeClient struct {
+	capacity     int
+	events       []Event
+	disconnected string
+}
+
+func newFakeClient(capacity int) *fakeClient {
+	return &fakeClient{capacity: capacity}
+}
+
+func (f *fakeClient) Deliver(e Event) bool {
+	if len(f.events) >= f.capacity {
+		return false
+	}
+	f.events = append(f.events, e)
+	return true
+}
+
+func (f *fakeClient) Disconnect(reason string) {
+	f.disconnected = reason
+}
+
+func (f *fakeClient) types() []string {
+	types := make([]string, len(f.events))
+	for i, e := range f.events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestRoomName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"geral", "geral", false},
+		{" Suporte_TI ", "suporte_ti", false},
+		{"sala-1", "sala-1", false},
+		{"", "", true},
+		{"-sala", "", true},
+		{"sala geral", "", true},
+		{strings.Repeat("a", 65), "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := RoomName(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("RoomName(%q): expected %q (error %v), got %q (%v)", tt.name, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
+
+func TestHub_JoinBroadcastLeave(t *testing.T) {
+	hub := NewHub(10)
+	ana, bruno := newFakeClient(10), newFakeClient(10)
+
+	if _, members, err := hub.Join("geral", ana, Member{UserID: 1, Username: "ana"}); err != nil || len(members) != 1 {
+		t.Fatalf("Expected ana to join alone, got %v (%v)", members, err)
+	}
+	_, members, err := hub.Join("Geral", bruno, Member{UserID: 2, Username: "bruno"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(members) != 2 || members[0].Username != "ana" || members[1].Username != "bruno" {
+		t.Errorf("Expected presence [ana bruno], got %v", members)
+	}
+	if got := ana.types(); len(got) != 1 || got[0] != EventJoined {
+		t.Errorf("Expected ana to be told about bruno, got %v", got)
+	}
+
+	msg, err := hub.Broadcast("geral", bruno, "  olá  ")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if msg.Text != "olá" || msg.From.UserID != 2 || msg.Room != "geral" {
+		t.Errorf("Unexpected message: %+v", msg)
+	}
+	for _, c := range []*fakeClient{ana, bruno} {
+		last := c.events[len(c.events)-1]
+		if last.Type != EventMessage || last.Message.ID != msg.ID {
+			t.Errorf("Expected message delivered to every member, got %+v", last)
+		}
+	}
+
+	if err := hub.Leave("geral", bruno); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	last := ana.events[len(ana.events)-1]
+	if last.Type != EventLeft || last.Member.UserID != 2 {
+		t.Errorf("Expected member_left for bruno, got %+v", last)
+	}
+	if err := hub.Leave("geral", bruno); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Expected ErrNotMember, got %v", err)
+	}
+	if _, err := hub.Broadcast("geral", bruno, "oi"); !errors.Is(err, ErrNotMember) {
+		t.Errorf("Expected ErrNotMember for non-member, got %v", err)
+	}
+
+	hub.LeaveAll(ana)
+	if rooms := hub.Rooms(); len(rooms) != 0 {
+		t.Errorf("Expected empty rooms to be discarded, got %v", rooms)
+	}
+}
+
+func TestHub_History(t *testing.T) {
+	hub := NewHub(3)
+	ana := newFakeClient(100)
+	hub.Join("geral", ana, Member{UserID: 1, Username: "ana"})
+
+	for _, text := range []string{"1", "2", "3", "4", "5"} {
+		if _, err := hub.Broadcast("geral", ana, text); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	history, _, err := hub.Join("geral", newFakeClient(10), Member{UserID: 2, Username: "bruno"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(history) != 3 || history[0].Text != "3" || history[2].Text != "5" {
+		t.Errorf("Expected last 3 messages, got %+v", history)
+	}
+}
+
+func TestHub_Validation(t *testing.T) {
+	hub := NewHub(10)
+	ana := newFakeClient(10)
+	if _, _, err := hub.Join("sala inválida", ana, Member{UserID: 1}); !errors.Is(err, ErrInvalidRoom) {
+		t.Errorf("Expected ErrInvalidRoom, got %v", err)
+	}
+
+	hub.Join("geral", ana, Member{UserID: 1})
+	if _, _, err := hub.Join("geral", ana, Member{UserID: 1}); !errors.Is(err, ErrAlreadyInRoom) {
+		t.Errorf("Expected ErrAlreadyInRoom, got %v", err)
+	}
+	if _, err := hub.Broadcast("geral", ana, "   "); !errors.Is(err, ErrEmptyMessage) {
+		t.Errorf("Expected ErrEmptyMessage, got %v", err)
+	}
+	if _, err := hub.Broadcast("geral", ana, strings.Repeat("a", MaxTextLength+1)); !errors.Is(err, ErrMessageTooLong) {
+		t.Errorf("Expected ErrMessageTooLong, got %v", err)
+	}
+
+	for i := 1; i < MaxRoomsPerClient; i++ {
+		if _, _, err := hub.Join("sala-"+strings.Repeat("x", i), ana, Member{UserID: 1}); err != nil {
+			t.Fatalf("Expected join %d to succeed, got %v", i, err)
+		}
+	}
+	if _, _, err := hub.Join("extra", ana, Member{UserID: 1}); !errors.Is(err, ErrTooManyRooms) {
+		t.Errorf("Expected ErrTooManyRooms, got %v", err)
+	}
+}
+
+func TestHub_PresenceAcrossConnections(t *testing.T) {
+	hub := NewHub(10)
+	watcher := newFakeClient(10)
+	hub.Join("geral", watcher, Member{UserID: 1, Username: "ana"})
+
+	tab1, tab2 := newFakeClient(10), newFakeClient(10)
+	hub.Join("geral", tab1, Member{UserID: 2, Username: "bruno"})
+	hub.Join("geral", tab2, Member{UserID: 2, Username: "bruno"})
+
+	members, _ := hub.Presence("geral")
+	if len(members) != 2 {
+		t.Errorf("Expected 2 distinct members, got %v", members)
+	}
+	if got := watcher.types(); len(got) != 1 {
+		t.Errorf("Expected a single member_joined for two connections, got %v", got)
+	}
+
+	hub.LeaveAll(tab1)
+	if got := watcher.types(); len(got) != 1 {
+		t.Errorf("Expected no member_left while another connection remains, got %v", got)
+	}
+	hub.LeaveAll(tab2)
+	if got := watcher.types(); len(got) != 2 || got[1] != EventLeft {
+		t.Errorf("Expected member_left after the last connection, got %v", got)
+	}
+}
+
+func TestHub_SlowConsumerIsDisconnected(t *testing.T) {
+	hub := NewHub(10)
+	fast := newFakeClient(100)
+	slow := newFakeClient(1)
+	hub.Join("geral", fast, Member{UserID: 1, Username: "ana"})
+	hub.Join("geral", slow, Member{UserID: 2, Username: "bruno"})
+	hub.Join("outra", slow, Member{UserID: 2, Username: "bruno"})
+
+	for i := 0; i < 3; i++ {
+		if _, err := hub.Broadcast("geral", fast, "mensagem"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if slow.disconnected != SlowConsumer {
+		t.Errorf("Expected slow client to be disconnected, got %q", slow.disconnected)
+	}
+	if members, _ := hub.Presence("geral"); len(members) != 1 {
+		t.Errorf("Expected slow client removed from the room, got %v", members)
+	}
+	if rooms := hub.Rooms(); len(rooms) != 1 || rooms[0].Name != "geral" {
+		t.Errorf("Expected slow client removed from every room, got %v", rooms)
+	}
+
+	got := fast.types()
+	if got[len(got)-1] != EventMessage || got[len(got)-2] != EventLeft {
+		t.Errorf("Expected member_left for the slow client, got %v", got)
+	}
+}
@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"echo-playground/internal/chat"
+	"echo-playground/pkg/middleware"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+func chatToken(t *testing.T, userID int, username string) http.Header {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":  userID,
+		"username": username,
+	}).SignedString(middleware.JWTSecret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
+
+func sendCommand(t *testing.T, conn *websocket.Conn, msgType string, data interface{}) {
+	t.Helper()
+	raw, _ := json.Marshal(data)
+	if err := conn.WriteJSON(WSMessage{Type: msgType, Data: raw}); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+}
+
+func expectReply(t *testing.T, conn *websocket.Conn, msgType string, v interface{}) {
+	t.Helper()
+	reply := readReply(t, conn)
+	if reply.Type != msgType {
+		t.Fatalf("Expected %q, got %+v", msgType, reply)
+	}
+	if v != nil {
+		if err := json.Unmarshal(reply.Data, v); err != nil {
+			t.Fatalf("Failed to decode %s: %v", reply.Data, err)
+		}
+	}
+}
+
+type joinedReply struct {
+	Room    string         `json:"room"`
+	Members []chat.Member  `json:"members"`
+	History []chat.Message `json:"history"`
+}
+
+func TestChat_RoomsOverWebSocket(t *testing.T) {
+	url := newWebSocketTestServer(t, testWebSocketConfig())
+	ana := dialWebSocket(t, url, chatToken(t, 1, "ana"))
+	bruno := dialWebSocket(t, url, chatToken(t, 2, "bruno"))
+
+	var joined joinedReply
+	sendCommand(t, ana, WSTypeJoin, ChatCommand{Room: "Geral"})
+	expectReply(t, ana, WSTypeJoined, &joined)
+	if joined.Room != "geral" || len(joined.Members) != 1 || len(joined.History) != 0 {
+		t.Errorf("Unexpected join reply: %+v", joined)
+	}
+
+	sendCommand(t, ana, WSTypeSend, ChatCommand{Room: "geral", Text: "primeira"})
+	var event chat.Event
+	expectReply(t, ana, chat.EventMessage, &event)
+	if event.Message == nil || event.Message.Text != "primeira" || event.Message.From.Username != "ana" {
+		t.Errorf("Expected sender to receive its message, got %+v", event)
+	}
+
+	sendCommand(t, bruno, WSTypeJoin, ChatCommand{Room: "geral"})
+	expectReply(t, bruno, WSTypeJoined, &joined)
+	if len(joined.Members) != 2 || len(joined.History) != 1 || joined.History[0].Text != "primeira" {
+		t.Errorf("Expected presence and history on join, got %+v", joined)
+	}
+	expectReply(t, ana, chat.EventJoined, &event)
+	if event.Member == nil || event.Member.Username != "bruno" {
+		t.Errorf("Expected member_joined for bruno, got %+v", event)
+	}
+
+	sendCommand(t, bruno, WSTypeSend, ChatCommand{Room: "geral", Text: "oi"})
+	for _, conn := range []*websocket.Conn{ana, bruno} {
+		expectReply(t, conn, chat.EventMessage, &event)
+		if event.Message.Text != "oi" || event.Message.From.UserID != 2 {
+			t.Errorf("Expected broadcast from bruno, got %+v", event.Message)
+		}
+	}
+
+	var presence struct {
+		Members []chat.Member `json:"members"`
+	}
+	sendCommand(t, ana, WSTypePresence, ChatCommand{Room: "geral"})
+	expectReply(t, ana, WSTypePresence, &presence)
+	if len(presence.Members) != 2 {
+		t.Errorf("Expected 2 members, got %v", presence.Members)
+	}
+
+	// Fechar a conexão remove bruno da sala
+	bruno.Close()
+	expectReply(t, ana, chat.EventLeft, &event)
+	if event.Member == nil || event.Member.UserID != 2 {
+		t.Errorf("Expected member_left for bruno, got %+v", event)
+	}
+
+	sendCommand(t, ana, WSTypeLeave, ChatCommand{Room: "geral"})
+	expectReply(t, ana, WSTypeLeft, nil)
+	sendCommand(t, ana, WSTypeRooms, nil)
+	var rooms []chat.RoomInfo
+	expectReply(t, ana, WSTypeRooms, &rooms)
+	if len(rooms) != 0 {
+		t.Errorf("Expected no active rooms, got %v", rooms)
+	}
+}
+
+func TestChat_CommandErrors(t *testing.T) {
+	conn := dialWebSocket(t, newWebSocketTestServer(t, testWebSocketConfig()), authHeader())
+
+	tests := []struct {
+		msgType string
+		data    interface{}
+	}{
+		{WSTypeJoin, ChatCommand{Room: "sala inválida"}},
+		{WSTypeJoin, "não é objeto"},
+		{WSTypeSend, ChatCommand{Room: "geral", Text: "fora da sala"}},
+		{WSTypeLeave, ChatCommand{Room: "geral"}},
+	}
+
+	for _, tt := range tests {
+		sendCommand(t, conn, tt.msgType, tt.data)
+		if reply := readReply(t, conn); reply.Type != WSTypeError || reply.Error == "" {
+			t.Errorf("Expected error for %s %v, got %+v", tt.msgType, tt.data, reply)
+		}
+	}
+}
+
+func TestChat_RoomsHandler(t *testing.T) {
+	hub := chat.NewHub(10)
+	h := NewWebSocketHandlers(testWebSocketConfig(), hub)
+	hub.Join("geral", &wsChatClient{wsConn: newWSConn(nil, testWebSocketConfig(), 1)}, chat.Member{UserID: 1})
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	if err := h.ChatRoomsHandler(e.NewContext(httptest.NewRequest(http.MethodGet, "/chat/rooms", nil), rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var resp struct {
+		Data []chat.RoomInfo `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusOK || len(resp.Data) != 1 || resp.Data[0].Name != "geral" || resp.Data[0].Members != 1 {
+		t.Errorf("Expected room geral with 1 member, got %d %s", rec.Code, rec.Body.String())
+	}
+}
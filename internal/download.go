@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// serveContent envia o conteúdo como anexo usando http.ServeContent, que
+// trata Range, If-Range, If-None-Match e If-Modified-Since. Um
+// Content-Disposition já definido pelo handler é preservado. Sem um ETag
+// explícito, é usado um ETag forte derivado do tamanho e da data de
+// modificação. O prazo de escrita do servidor é removido, para que
+// downloads grandes não sejam interrompidos no meio.
+func serveContent(c echo.Context, name, etag string, modTime time.Time, size int64, content io.ReadSeeker) {
+	header := c.Response().Header()
+
+	if etag == "" {
+		etag = fmt.Sprintf(`"%x-%x"`, modTime.UnixNano(), size)
+	}
+	header.Set("ETag", etag)
+
+	if header.Get(echo.HeaderContentType) == "" {
+		if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+			header.Set(echo.HeaderContentType, contentType)
+		}
+	}
+	if header.Get(echo.HeaderContentDisposition) == "" {
+		header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+
+	disableWriteDeadline(c.Response())
+	http.ServeContent(c.Response(), c.Request(), name, modTime, content)
+}
@@ -1,8 +1,11 @@
 package internal
 
 import (
+	"log/slog"
 	"net/http"
 
+	"echo-playground/pkg/middleware"
+
 	"github.com/labstack/echo/v4"
 )
 
@@ -20,26 +23,26 @@ func CustomErrorHandler(err error, c echo.Context) {
 		}
 	}
 
-	// Log do erro
-	c.Logger().Error(err)
-
+	// O erro é registrado na linha de acesso da requisição (AccessLog)
 	// Resposta de erro
 	if !c.Response().Committed {
 		if c.Request().Header.Get("Content-Type") == "application/xml" {
 			if err := c.XML(code, map[string]interface{}{
-				"error":   message,
-				"code":    code,
-				"success": false,
+				"error":      message,
+				"code":       code,
+				"success":    false,
+				"request_id": middleware.RequestID(c),
 			}); err != nil {
-				c.Logger().Error(err)
+				slog.ErrorContext(c.Request().Context(), "falha ao enviar resposta de erro", "error", err)
 			}
 		} else {
 			if err := c.JSON(code, map[string]interface{}{
-				"success": false,
-				"message": message,
-				"error":   "",
+				"success":    false,
+				"message":    message,
+				"error":      "",
+				"request_id": middleware.RequestID(c),
 			}); err != nil {
-				c.Logger().Error(err)
+				slog.ErrorContext(c.Request().Context(), "falha ao enviar resposta de erro", "error", err)
 			}
 		}
 	}
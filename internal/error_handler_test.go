@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"echo-playground/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCustomErrorHandler_RequestID(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = CustomErrorHandler
+	e.Use(middleware.RequestIDMiddleware())
+	e.GET("/falha", func(c echo.Context) error { return errors.New("banco indisponível") })
+	e.GET("/proibido", func(c echo.Context) error { return echo.NewHTTPError(http.StatusForbidden, "Acesso negado") })
+
+	tests := []struct {
+		path    string
+		status  int
+		message string
+	}{
+		{"/falha", http.StatusInternalServerError, "Erro interno do servidor"},
+		{"/proibido", http.StatusForbidden, "Acesso negado"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-"+tt.path[1:])
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var body map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &body)
+		if rec.Code != tt.status || body["message"] != tt.message {
+			t.Errorf("%s: expected %d %q, got %d %v", tt.path, tt.status, tt.message, rec.Code, body)
+		}
+		if body["request_id"] != "req-"+tt.path[1:] || rec.Header().Get(echo.HeaderXRequestID) != "req-"+tt.path[1:] {
+			t.Errorf("%s: expected request ID in body and header, got %v", tt.path, body)
+		}
+	}
+}
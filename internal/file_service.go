@@ -0,0 +1,329 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/imaging"
+	"echo-playground/pkg/metrics"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/scanner"
+	"echo-playground/pkg/storage"
+	"echo-playground/pkg/utils"
+)
+
+// FileService concentra a validação, a gravação e a catalogação dos
+// arquivos, compartilhadas por todos os caminhos de upload. O conteúdo é
+// armazenado uma única vez por SHA-256; o número de registros do catálogo
+// que apontam para um conteúdo funciona como contagem de referências.
+type FileService struct {
+	store    storage.Storage
+	files    *repository.FileRepository
+	maxSize  config.ByteSize
+	maxFiles int
+	quota    config.ByteSize
+	allowed  map[string]bool
+	blobs    keyLocks
+
+	thumbSizes []int
+
+	scanner        scanner.Scanner
+	rejectInfected bool
+	scanRequests   chan struct{}
+
+	metrics *metrics.Metrics
+}
+
+// NewFileService cria o serviço de arquivos sobre o armazenamento e o
+// catálogo informados, aplicando os limites da configuração de upload. Com
+// um scanner, os arquivos ficam em quarentena até serem verificados; nil
+// desativa a verificação.
+func NewFileService(store storage.Storage, files *repository.FileRepository, sc scanner.Scanner, cfg config.UploadConfig) *FileService {
+	allowed := make(map[string]bool, len(cfg.AllowedTypes))
+	for _, t := range cfg.AllowedTypes {
+		allowed[fileExtension("."+t)] = true
+	}
+	maxFiles := cfg.MaxFiles
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+	return &FileService{
+		store:    store,
+		files:    files,
+		maxSize:  cfg.MaxSize,
+		maxFiles: maxFiles,
+		quota:    cfg.UserQuota,
+		allowed:  allowed,
+
+		thumbSizes: thumbnailSizes(cfg.ThumbnailSizes),
+
+		scanner:        sc,
+		rejectInfected: cfg.Scan.OnInfected == "reject",
+		scanRequests:   make(chan struct{}, 1),
+	}
+}
+
+// MaxSize retorna o tamanho máximo aceito por arquivo
+func (s *FileService) MaxSize() config.ByteSize {
+	return s.maxSize
+}
+
+// MaxFiles retorna quantos arquivos são aceitos em uma única requisição
+func (s *FileService) MaxFiles() int {
+	return s.maxFiles
+}
+
+// Check valida nome e tamanho declarados antes de receber o conteúdo,
+// inclusive contra a cota do usuário. size negativo indica tamanho ainda
+// desconhecido.
+func (s *FileService) Check(ctx context.Context, originalName string, size int64, uploaderID int) error {
+	if !s.allowed[fileExtension(sanitizeFilename(originalName))] {
+		return ErrTypeNotAllowed
+	}
+	if size > int64(s.maxSize) {
+		return ErrFileTooLarge
+	}
+	return s.checkQuota(ctx, uploaderID, size)
+}
+
+// Usage retorna o espaço ocupado pelos arquivos do usuário. O tamanho de
+// cada arquivo conta para quem o enviou, mesmo que o conteúdo seja
+// compartilhado com outros arquivos.
+func (s *FileService) Usage(ctx context.Context, uploaderID int) *models.FileUsage {
+	used, count := s.files.Usage(ctx, uploaderID)
+	usage := &models.FileUsage{
+		UserID:     uploaderID,
+		Files:      count,
+		UsedBytes:  used,
+		QuotaBytes: int64(s.quota),
+	}
+	if s.quota > 0 && used < int64(s.quota) {
+		usage.AvailableBytes = int64(s.quota) - used
+	}
+	return usage
+}
+
+// Ingest valida o conteúdo pela assinatura e registra seus metadados no
+// catálogo. O conteúdo só é gravado se ainda não houver outro arquivo com o
+// mesmo SHA-256.
+func (s *FileService) Ingest(ctx context.Context, originalName string, r io.Reader, size int64, uploaderID int) (*models.File, error) {
+	original := sanitizeFilename(originalName)
+	if err := s.Check(ctx, original, size, uploaderID); err != nil {
+		return nil, err
+	}
+	ext := fileExtension(original)
+
+	contentType, body, err := sniffContent(ext, r)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = stripMetadata(contentType, body, int64(s.maxSize))
+	if err != nil {
+		return nil, err
+	}
+
+	spool, sum, n, err := s.spool(body)
+	if err != nil {
+		return nil, err
+	}
+	defer removeSpool(spool)
+
+	if err := s.checkQuota(ctx, uploaderID, n); err != nil {
+		return nil, err
+	}
+
+	unlock := s.blobs.Lock(sum)
+	defer unlock()
+
+	key := blobKey(sum)
+	stored := false
+	if s.files.References(ctx, sum) == 0 {
+		if _, err := s.store.Put(ctx, key, spool, n, contentType); err != nil {
+			return nil, err
+		}
+		stored = true
+
+		// Falhas ao gerar miniaturas não impedem o upload: elas são geradas
+		// novamente quando solicitadas
+		if imaging.Supported(contentType) {
+			if _, err := spool.Seek(0, io.SeekStart); err == nil {
+				_ = s.generateThumbnails(ctx, sum, contentType, spool)
+			}
+		}
+	}
+
+	status := models.FileStatusUnscanned
+	if s.scanner != nil {
+		status = models.FileStatusPending
+	}
+
+	id := utils.NewID()
+	file, err := s.files.CreateWithinQuota(ctx, &models.File{
+		ID:           id,
+		Name:         key,
+		OriginalName: original,
+		Size:         n,
+		ContentType:  contentType,
+		SHA256:       sum,
+		UploaderID:   uploaderID,
+		UploadedAt:   time.Now(),
+		Status:       status,
+		URL:          "/api/v1/files/" + id + "/download",
+	}, int64(s.quota))
+	if err != nil {
+		if stored {
+			_ = s.store.Delete(ctx, key)
+			_ = s.deleteThumbnails(ctx, sum, contentType)
+		}
+		return nil, err
+	}
+
+	s.metrics.UploadAccepted(n)
+	if status == models.FileStatusPending {
+		s.requestScan()
+	}
+	return file, nil
+}
+
+// SetMetrics passa a contar os arquivos e bytes aceitos em m
+func (s *FileService) SetMetrics(m *metrics.Metrics) {
+	s.metrics = m
+}
+
+// Get retorna os metadados de um arquivo do catálogo
+func (s *FileService) Get(ctx context.Context, id string) (*models.File, error) {
+	return s.files.Get(ctx, id)
+}
+
+// List lista os arquivos do catálogo
+func (s *FileService) List(ctx context.Context, filter repository.FileFilter, page, perPage int) ([]*models.File, int) {
+	return s.files.List(ctx, filter, page, perPage)
+}
+
+// Open abre o conteúdo de um arquivo do catálogo
+func (s *FileService) Open(ctx context.Context, file *models.File) (io.ReadSeekCloser, *storage.Object, error) {
+	content, obj, err := s.store.Get(ctx, file.Name)
+	if errors.Is(err, storage.ErrInvalidKey) {
+		err = storage.ErrNotFound
+	}
+	return content, obj, err
+}
+
+// Delete remove o arquivo do catálogo e, se nenhum outro arquivo
+// compartilhar o conteúdo, também do armazenamento
+func (s *FileService) Delete(ctx context.Context, id string) error {
+	file, err := s.files.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	unlock := s.blobs.Lock(file.SHA256)
+	defer unlock()
+
+	if _, err := s.files.Delete(ctx, id); err != nil {
+		return err
+	}
+	if s.files.References(ctx, file.SHA256) > 0 {
+		return nil
+	}
+	if err := s.store.Delete(ctx, file.Name); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return s.deleteThumbnails(ctx, file.SHA256, file.ContentType)
+}
+
+// checkQuota recusa o arquivo se ele ultrapassar a cota do usuário. Os
+// uploads anônimos (uploaderID 0) dividem uma única cota. Tamanhos
+// desconhecidos não são verificados.
+func (s *FileService) checkQuota(ctx context.Context, uploaderID int, size int64) error {
+	if s.quota == 0 || size < 0 {
+		return nil
+	}
+	if used, _ := s.files.Usage(ctx, uploaderID); used+size > int64(s.quota) {
+		return repository.ErrQuotaExceeded
+	}
+	return nil
+}
+
+// spool grava o conteúdo em um arquivo temporário enquanto calcula o
+// SHA-256, já que a chave de armazenamento só é conhecida ao final da
+// leitura. Conteúdo acima do tamanho máximo é recusado.
+func (s *FileService) spool(r io.Reader) (*os.File, string, int64, error) {
+	f, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, hash), io.LimitReader(r, int64(s.maxSize)+1))
+	if err == nil && n > int64(s.maxSize) {
+		err = ErrFileTooLarge
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		removeSpool(f)
+		return nil, "", 0, err
+	}
+	return f, hex.EncodeToString(hash.Sum(nil)), n, nil
+}
+
+// removeSpool fecha e apaga o arquivo temporário criado por spool
+func removeSpool(f *os.File) {
+	f.Close()
+	os.Remove(f.Name())
+}
+
+// blobKey retorna a chave de armazenamento de um conteúdo, agrupada pelos
+// dois primeiros dígitos do hash para não concentrar tudo em um diretório
+func blobKey(sum string) string {
+	return "blobs/" + sum[:2] + "/" + sum
+}
+
+// keyLocks serializa as operações sobre uma mesma chave sem bloquear as
+// demais. Entradas sem uso são removidas ao destravar.
+type keyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// Lock trava a chave e retorna a função que a destrava
+func (k *keyLocks) Lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyLock{}
+		k.locks[key] = l
+	}
+	l.waiters++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		l.waiters--
+		if l.waiters == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
@@ -0,0 +1,312 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/imaging"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/signing"
+	"echo-playground/pkg/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FileHandlers contém os handlers de upload e download de arquivos
+type FileHandlers struct {
+	files  *FileService
+	signer *signing.Signer
+}
+
+// NewFileHandlers cria os handlers de arquivos sobre o serviço informado.
+// O signer assina e valida os links de download temporários.
+func NewFileHandlers(files *FileService, signer *signing.Signer) *FileHandlers {
+	return &FileHandlers{files: files, signer: signer}
+}
+
+// UploadResult é o resultado de um arquivo em um upload múltiplo
+type UploadResult struct {
+	Filename string       `json:"filename"`
+	Success  bool         `json:"success"`
+	Status   int          `json:"status"`
+	File     *models.File `json:"file,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// UploadHandler faz upload de um ou mais arquivos enviados no campo "file".
+// Cada arquivo é gravado com um nome gerado e registrado no catálogo; se a
+// requisição estiver autenticada, o usuário é registrado como responsável
+// pelo envio. Com vários arquivos, a resposta traz o resultado de cada um e
+// usa 207 Multi-Status se algum deles for recusado.
+func (h *FileHandlers) UploadHandler(c echo.Context) error {
+	maxSize, maxFiles := h.files.MaxSize(), h.files.MaxFiles()
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, int64(maxSize)*int64(maxFiles)+multipartOverhead)
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return uploadError(c, ErrFileTooLarge, maxSize)
+		}
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "Erro ao processar arquivo",
+			"error":   err.Error(),
+		})
+	}
+
+	headers := form.File["file"]
+	switch {
+	case len(headers) == 0:
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "Erro ao processar arquivo",
+			"error":   http.ErrMissingFile.Error(),
+		})
+	case len(headers) > maxFiles:
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(
+			fmt.Sprintf("Máximo de %d arquivos por requisição", maxFiles), ""))
+	case len(headers) == 1:
+		stored, err := h.ingest(c, headers[0])
+		if err != nil {
+			return uploadError(c, err, maxSize)
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success": true,
+			"message": "Arquivo enviado com sucesso",
+			"data":    stored,
+		})
+	}
+
+	results := make([]UploadResult, 0, len(headers))
+	failed := 0
+	for _, header := range headers {
+		result := UploadResult{Filename: sanitizeFilename(header.Filename), Success: true, Status: http.StatusCreated}
+		stored, err := h.ingest(c, header)
+		if err != nil {
+			failed++
+			result.Success = false
+			result.Status, result.Error = uploadErrorStatus(err, maxSize)
+		}
+		result.File = stored
+		results = append(results, result)
+	}
+
+	status := http.StatusOK
+	if failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	return c.JSON(status, map[string]interface{}{
+		"success": failed == 0,
+		"message": fmt.Sprintf("%d de %d arquivos enviados com sucesso", len(headers)-failed, len(headers)),
+		"data":    results,
+	})
+}
+
+// ListFilesHandler lista os arquivos do usuário autenticado, opcionalmente
+// filtrados pela situação da verificação com ?status=. Administradores veem
+// todos os arquivos e podem filtrar com ?uploader_id=
+func (h *FileHandlers) ListFilesHandler(c echo.Context) error {
+	filter := repository.FileFilter{Status: c.QueryParam("status")}
+	if middleware.IsAdmin(c) {
+		if value := c.QueryParam("uploader_id"); value != "" {
+			id, err := strconv.Atoi(value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Parâmetro uploader_id inválido", err.Error()))
+			}
+			filter.UploaderID = id
+		}
+	} else {
+		filter.UploaderID, _ = middleware.UserID(c)
+	}
+
+	page, perPage := paginationParams(c)
+	files, total := h.files.List(c.Request().Context(), filter, page, perPage)
+
+	return c.JSON(http.StatusOK, api.NewPaginatedResponse("Arquivos listados com sucesso", files, api.NewPagination(page, perPage, total)))
+}
+
+// GetFileHandler retorna os metadados de um arquivo
+func (h *FileHandlers) GetFileHandler(c echo.Context) error {
+	file, ok := h.ownedFile(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Arquivo não encontrado", ""))
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Arquivo encontrado", file))
+}
+
+// DeleteFileHandler remove o arquivo do catálogo e do armazenamento
+func (h *FileHandlers) DeleteFileHandler(c echo.Context) error {
+	file, ok := h.ownedFile(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Arquivo não encontrado", ""))
+	}
+
+	if err := h.files.Delete(c.Request().Context(), file.ID); err != nil {
+		return repositoryError(c, err, "Arquivo não encontrado")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Arquivo removido com sucesso", nil))
+}
+
+// UsageHandler informa o espaço ocupado pelo usuário autenticado e sua
+// cota. Administradores podem consultar outro usuário com ?user_id=
+func (h *FileHandlers) UsageHandler(c echo.Context) error {
+	userID, _ := middleware.UserID(c)
+	if value := c.QueryParam("user_id"); value != "" && middleware.IsAdmin(c) {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Parâmetro user_id inválido", err.Error()))
+		}
+		userID = id
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Uso de armazenamento", h.files.Usage(c.Request().Context(), userID)))
+}
+
+// DownloadHandler faz download de um arquivo pelo ID do catálogo. Suporta
+// requisições Range (inclusive múltiplos intervalos), ETag, Last-Modified e
+// If-Range, para que downloads interrompidos possam ser retomados. Arquivos
+// com dono exigem o usuário autenticado ou um link assinado; um link de uso
+// único é consumido pela primeira resposta com conteúdo.
+func (h *FileHandlers) DownloadHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	file, err := h.files.Get(ctx, c.Param("id"))
+	if err != nil {
+		return repositoryError(c, err, "Arquivo não encontrado")
+	}
+	if status, message := h.downloadAccess(c, file); status != 0 {
+		return c.JSON(status, api.NewErrorResponse(message, ""))
+	}
+	release, err := h.consumeSignedURL(c)
+	if err != nil {
+		return c.JSON(http.StatusGone, api.NewErrorResponse("Link de download já utilizado", ""))
+	}
+	defer release()
+
+	content, obj, err := h.files.Open(ctx, file)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return c.JSON(http.StatusNotFound, api.NewErrorResponse("Arquivo não encontrado", ""))
+		}
+		return err
+	}
+	defer content.Close()
+
+	c.Response().Header().Set(echo.HeaderContentType, file.ContentType)
+	serveContent(c, file.OriginalName, `"`+file.SHA256+`"`, obj.ModTime, obj.Size, content)
+	return nil
+}
+
+// ThumbnailHandler envia a miniatura de uma imagem no tamanho ?size=, que
+// deve ser um dos configurados (o menor, se omitido). Segue as mesmas
+// regras de acesso do download.
+func (h *FileHandlers) ThumbnailHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	file, err := h.files.Get(ctx, c.Param("id"))
+	if err != nil {
+		return repositoryError(c, err, "Arquivo não encontrado")
+	}
+	if status, message := h.downloadAccess(c, file); status != 0 {
+		return c.JSON(status, api.NewErrorResponse(message, ""))
+	}
+
+	sizes := h.files.ThumbnailSizes()
+	if len(sizes) == 0 {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Miniaturas desativadas", ""))
+	}
+	size := sizes[0]
+	if value := c.QueryParam("size"); value != "" {
+		if size, err = strconv.Atoi(value); err != nil {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Parâmetro size inválido", err.Error()))
+		}
+	}
+
+	content, obj, err := h.files.Thumbnail(ctx, file, size)
+	switch {
+	case errors.Is(err, ErrNoThumbnail):
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Arquivo não possui miniatura", err.Error()))
+	case errors.Is(err, ErrInvalidThumbnailSize):
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(
+			fmt.Sprintf("Tamanho de miniatura inválido; use um de %v", sizes), err.Error()))
+	case errors.Is(err, storage.ErrNotFound):
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Arquivo não encontrado", ""))
+	case err != nil:
+		return err
+	}
+	defer content.Close()
+
+	name := strings.TrimSuffix(file.OriginalName, path.Ext(file.OriginalName)) + "-" + strconv.Itoa(size) + path.Ext(obj.Key)
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, imaging.ThumbnailType(file.ContentType))
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType("inline", map[string]string{"filename": name}))
+	serveContent(c, name, fmt.Sprintf(`"%s-%d"`, file.SHA256, size), obj.ModTime, obj.Size, content)
+	return nil
+}
+
+// ownedFile busca o arquivo da rota, visível apenas para quem o enviou e
+// para administradores
+func (h *FileHandlers) ownedFile(c echo.Context) (*models.File, bool) {
+	return h.ownedFileByID(c, c.Param("id"))
+}
+
+// ownedFileByID busca um arquivo visível para o usuário autenticado
+func (h *FileHandlers) ownedFileByID(c echo.Context, id string) (*models.File, bool) {
+	file, err := h.files.Get(c.Request().Context(), id)
+	if err != nil {
+		return nil, false
+	}
+
+	userID, _ := middleware.UserID(c)
+	if (file.UploaderID == 0 || file.UploaderID != userID) && !middleware.IsAdmin(c) {
+		return nil, false
+	}
+	return file, true
+}
+
+// ingest grava e cataloga um arquivo recebido no formulário multipart
+func (h *FileHandlers) ingest(c echo.Context, header *multipart.FileHeader) (*models.File, error) {
+	src, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	uploaderID, _ := middleware.UserID(c)
+	return h.files.Ingest(c.Request().Context(), header.Filename, src, header.Size, uploaderID)
+}
+
+// uploadError converte erros de validação de upload em respostas HTTP
+func uploadError(c echo.Context, err error, maxSize config.ByteSize) error {
+	status, message := uploadErrorStatus(err, maxSize)
+	return c.JSON(status, api.NewErrorResponse(message, err.Error()))
+}
+
+// uploadErrorStatus retorna o status HTTP e a mensagem de um erro de upload
+func uploadErrorStatus(err error, maxSize config.ByteSize) (int, string) {
+	switch {
+	case errors.Is(err, ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge, fmt.Sprintf("Arquivo excede o tamanho máximo de %s", maxSize)
+	case errors.Is(err, ErrTypeNotAllowed):
+		return http.StatusUnsupportedMediaType, "Tipo de arquivo não permitido"
+	case errors.Is(err, ErrContentMismatch):
+		return http.StatusUnsupportedMediaType, "Conteúdo do arquivo não corresponde ao tipo informado"
+	case errors.Is(err, repository.ErrQuotaExceeded):
+		return http.StatusRequestEntityTooLarge, "Cota de armazenamento excedida"
+	case errors.Is(err, ErrEmptyFile):
+		return http.StatusBadRequest, "Arquivo vazio"
+	}
+	return http.StatusInternalServerError, "Erro ao salvar arquivo"
+}
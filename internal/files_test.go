@@ -0,0 +1,497 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/signing"
+	"echo-playground/pkg/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// pngImage é um PNG 1x1 válido
+var pngImage = func() []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	return bytes.Clone(buf.Bytes())
+}()
+
+func newTestFileHandlers(t *testing.T, maxSize config.ByteSize) (*FileHandlers, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return NewFileHandlers(NewFileService(store, repository.NewFileRepository(), nil, config.UploadConfig{
+		MaxSize:      maxSize,
+		MaxFiles:     3,
+		AllowedTypes: []string{"png", "txt"},
+		Directory:    dir,
+	}), signing.NewSigner([]byte("test"))), dir
+}
+
+type testUpload struct {
+	filename string
+	content  []byte
+}
+
+func upload(h *FileHandlers, filename string, content []byte) *httptest.ResponseRecorder {
+	return uploadMany(h, testUpload{filename, content})
+}
+
+func uploadMany(h *FileHandlers, files ...testUpload) *httptest.ResponseRecorder {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	for _, f := range files {
+		part, _ := w.CreateFormFile("file", f.filename)
+		_, _ = part.Write(f.content)
+	}
+	_ = w.Close()
+
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/upload", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	_ = h.UploadHandler(c)
+	return rec
+}
+
+func TestFileHandlers_UploadHandler(t *testing.T) {
+	h, dir := newTestFileHandlers(t, config.Kilobyte)
+
+	rec := upload(h, "../../etc/relatorio.txt", []byte("conteúdo de teste"))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data models.File `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Data.OriginalName != "relatorio.txt" {
+		t.Errorf("Expected sanitized original name, got %q", response.Data.OriginalName)
+	}
+	if response.Data.Name != blobKey(response.Data.SHA256) {
+		t.Errorf("Expected content-addressed stored name, got %q", response.Data.Name)
+	}
+	if !strings.HasPrefix(response.Data.ContentType, "text/plain") {
+		t.Errorf("Expected text/plain, got %s", response.Data.ContentType)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, response.Data.Name)); err != nil {
+		t.Errorf("Expected file inside upload dir: %v", err)
+	}
+}
+
+func TestFileHandlers_UploadHandler_Rejections(t *testing.T) {
+	h, dir := newTestFileHandlers(t, config.Kilobyte)
+
+	tests := []struct {
+		name     string
+		filename string
+		content  []byte
+		expected int
+	}{
+		{"extensão não permitida", "script.exe", []byte("MZ"), http.StatusUnsupportedMediaType},
+		{"conteúdo divergente", "foto.png", []byte("apenas texto"), http.StatusUnsupportedMediaType},
+		{"html disfarçado", "nota.txt", []byte("<html><script>alert(1)</script></html>"), http.StatusUnsupportedMediaType},
+		{"arquivo grande", "foto.png", append(pngImage, make([]byte, 2048)...), http.StatusRequestEntityTooLarge},
+		{"corpo acima do limite", "foto.png", append(pngImage, make([]byte, 256<<10)...), http.StatusRequestEntityTooLarge},
+		{"arquivo vazio", "vazio.txt", nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := upload(h, tt.filename, tt.content); rec.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("Expected no files to be stored, got %d", len(entries))
+	}
+}
+
+func TestFileHandlers_UploadHandler_MultipleFiles(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+
+	rec := uploadMany(h,
+		testUpload{"a.txt", []byte("primeiro")},
+		testUpload{"foto.png", pngImage},
+		testUpload{"script.exe", []byte("MZ")},
+	)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("Expected status 207, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Success bool           `json:"success"`
+		Data    []UploadResult `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Success || len(response.Data) != 3 {
+		t.Fatalf("Expected 3 results with a failure, got %+v", response)
+	}
+	if !response.Data[0].Success || response.Data[0].File == nil || response.Data[0].File.OriginalName != "a.txt" {
+		t.Errorf("Expected first file to be stored, got %+v", response.Data[0])
+	}
+	if !response.Data[1].Success {
+		t.Errorf("Expected second file to be stored, got %+v", response.Data[1])
+	}
+	if response.Data[2].Success || response.Data[2].Status != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected third file to be rejected with 415, got %+v", response.Data[2])
+	}
+
+	if _, total := h.files.List(context.Background(), repository.FileFilter{}, 1, 10); total != 2 {
+		t.Errorf("Expected 2 files in catalog, got %d", total)
+	}
+
+	rec = uploadMany(h, testUpload{"a.txt", []byte("a")}, testUpload{"b.txt", []byte("b")})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 when all files succeed, got %d", rec.Code)
+	}
+}
+
+func TestFileHandlers_UploadHandler_TooManyFiles(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+
+	files := make([]testUpload, h.files.MaxFiles()+1)
+	for i := range files {
+		files[i] = testUpload{"nota.txt", []byte("x")}
+	}
+
+	if rec := uploadMany(h, files...); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if _, total := h.files.List(context.Background(), repository.FileFilter{}, 1, 10); total != 0 {
+		t.Errorf("Expected no files in catalog, got %d", total)
+	}
+}
+
+func TestFileHandlers_Deduplication(t *testing.T) {
+	h, dir := newTestFileHandlers(t, config.Kilobyte)
+	first := uploadFile(t, h, "contrato.txt", []byte("mesmo conteúdo"))
+	second := uploadFile(t, h, "copia.txt", []byte("mesmo conteúdo"))
+
+	if first.ID == second.ID || first.Name != second.Name {
+		t.Fatalf("Expected distinct records sharing content, got %+v and %+v", first, second)
+	}
+	blob := filepath.Join(dir, first.Name)
+
+	rec, c := fileRequest(http.MethodDelete, first.ID, 1, middleware.RoleAdmin, nil)
+	_ = h.DeleteFileHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if _, err := os.Stat(blob); err != nil {
+		t.Errorf("Expected shared content to be kept, got %v", err)
+	}
+	if rec := download(h, second.ID, nil); rec.Body.String() != "mesmo conteúdo" {
+		t.Errorf("Expected remaining file to be downloadable, got %q", rec.Body.String())
+	}
+
+	rec, c = fileRequest(http.MethodDelete, second.ID, 1, middleware.RoleAdmin, nil)
+	_ = h.DeleteFileHandler(c)
+	if _, err := os.Stat(blob); !os.IsNotExist(err) {
+		t.Errorf("Expected content to be removed with the last reference, got %v", err)
+	}
+}
+
+func TestFileHandlers_Quota(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := storage.NewLocal(dir)
+	h := NewFileHandlers(NewFileService(store, repository.NewFileRepository(), nil, config.UploadConfig{
+		MaxSize:      config.Kilobyte,
+		MaxFiles:     3,
+		UserQuota:    10,
+		AllowedTypes: []string{"txt"},
+	}), signing.NewSigner([]byte("test")))
+
+	uploadAs := func(userID int, content string) *httptest.ResponseRecorder {
+		rec, c := fileRequest(http.MethodPost, "", userID, middleware.RoleUser, nil)
+		body := new(bytes.Buffer)
+		w := multipart.NewWriter(body)
+		part, _ := w.CreateFormFile("file", "nota.txt")
+		_, _ = part.Write([]byte(content))
+		_ = w.Close()
+		c.Request().Body = io.NopCloser(body)
+		c.Request().Header.Set("Content-Type", w.FormDataContentType())
+		_ = h.UploadHandler(c)
+		return rec
+	}
+
+	if rec := uploadAs(7, "123456"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := uploadAs(7, "123456"); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 above quota, got %d", rec.Code)
+	}
+	if rec := uploadAs(8, "123456"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another user to have its own quota, got %d", rec.Code)
+	}
+
+	// Uploads anônimos dividem uma cota própria
+	if rec := uploadAs(0, "123456"); rec.Code != http.StatusOK {
+		t.Errorf("Expected anonymous upload within quota, got %d", rec.Code)
+	}
+	if rec := uploadAs(0, "123456"); rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413 above anonymous quota, got %d", rec.Code)
+	}
+
+	rec, c := fileRequest(http.MethodGet, "", 7, middleware.RoleUser, nil)
+	_ = h.UsageHandler(c)
+	var response struct {
+		Data models.FileUsage `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	expected := models.FileUsage{UserID: 7, Files: 1, UsedBytes: 6, QuotaBytes: 10, AvailableBytes: 4}
+	if response.Data != expected {
+		t.Errorf("Expected %+v, got %+v", expected, response.Data)
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := map[string]string{
+		"../../etc/passwd":    "passwd",
+		`..\..\windows\a.txt`: "a.txt",
+		"foto\x00.png":        "foto.png",
+		".htaccess":           "htaccess",
+		"..":                  "arquivo",
+		"":                    "arquivo",
+		"relatório final.pdf": "relatório final.pdf",
+	}
+
+	for input, expected := range tests {
+		if got := sanitizeFilename(input); got != expected {
+			t.Errorf("sanitizeFilename(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
+
+func uploadFile(t *testing.T, h *FileHandlers, filename string, content []byte) models.File {
+	t.Helper()
+	rec := upload(h, filename, content)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data models.File `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return response.Data
+}
+
+func fileRequest(method, id string, userID int, role string, headers map[string]string) (*httptest.ResponseRecorder, echo.Context) {
+	e := setupTestEcho()
+	req := httptest.NewRequest(method, "/files/"+id, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	if userID != 0 {
+		c.Set(middleware.ContextKeyUserID, userID)
+		c.Set(middleware.ContextKeyRole, role)
+	}
+	return rec, c
+}
+
+func download(h *FileHandlers, id string, headers map[string]string) *httptest.ResponseRecorder {
+	rec, c := fileRequest(http.MethodGet, id, 0, "", headers)
+	_ = h.DownloadHandler(c)
+	return rec
+}
+
+func TestFileHandlers_Catalog(t *testing.T) {
+	h, dir := newTestFileHandlers(t, config.Kilobyte)
+
+	rec, c := fileRequest(http.MethodPost, "", 7, middleware.RoleUser, nil)
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	part, _ := w.CreateFormFile("file", "nota.txt")
+	_, _ = part.Write([]byte("abc"))
+	_ = w.Close()
+	c.Request().Body = io.NopCloser(body)
+	c.Request().Header.Set("Content-Type", w.FormDataContentType())
+	_ = h.UploadHandler(c)
+
+	var response struct {
+		Data models.File `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	file := response.Data
+
+	if file.SHA256 != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Errorf("Unexpected SHA-256: %s", file.SHA256)
+	}
+	if file.UploaderID != 7 || file.Size != 3 {
+		t.Errorf("Unexpected metadata: %+v", file)
+	}
+	uploadFile(t, h, "anonimo.txt", []byte("anônimo"))
+
+	rec, c = fileRequest(http.MethodGet, "", 7, middleware.RoleUser, nil)
+	_ = h.ListFilesHandler(c)
+	var list struct {
+		Data []models.File `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list.Data) != 1 || list.Data[0].ID != file.ID {
+		t.Errorf("Expected only the user's file, got %+v", list.Data)
+	}
+
+	rec, c = fileRequest(http.MethodGet, "", 1, middleware.RoleAdmin, nil)
+	_ = h.ListFilesHandler(c)
+	_ = json.Unmarshal(rec.Body.Bytes(), &list)
+	if len(list.Data) != 2 {
+		t.Errorf("Expected admin to see 2 files, got %d", len(list.Data))
+	}
+
+	rec, c = fileRequest(http.MethodGet, file.ID, 8, middleware.RoleUser, nil)
+	_ = h.GetFileHandler(c)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for another user's file, got %d", rec.Code)
+	}
+
+	rec, c = fileRequest(http.MethodDelete, file.ID, 7, middleware.RoleUser, nil)
+	_ = h.DeleteFileHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, file.Name)); !os.IsNotExist(err) {
+		t.Errorf("Expected stored file to be removed, got %v", err)
+	}
+	if rec := download(h, file.ID, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after delete, got %d", rec.Code)
+	}
+}
+
+func TestFileHandlers_DownloadHandler_UnknownID(t *testing.T) {
+	h, dir := newTestFileHandlers(t, config.Kilobyte)
+
+	if err := os.WriteFile(filepath.Join(dir, "solto.txt"), []byte("fora do catálogo"), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	for _, id := range []string{"solto.txt", "../segredo.txt", "..", "", "inexistente"} {
+		if rec := download(h, id, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %q, got %d", id, rec.Code)
+		}
+	}
+}
+
+// slowReader atrasa cada leitura, simulando um download demorado
+type slowReader struct {
+	*bytes.Reader
+	delay time.Duration
+}
+
+func (r slowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+	return r.Reader.Read(p[:min(len(p), 1024)])
+}
+
+func TestServeContent_OutlivesWriteTimeout(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 8*1024)
+	e := echo.New()
+	e.GET("/download", func(c echo.Context) error {
+		serveContent(c, "grande.txt", "", time.Now(), int64(len(content)), slowReader{bytes.NewReader(content), 20 * time.Millisecond})
+		return nil
+	})
+
+	server := httptest.NewUnstartedServer(e)
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/download")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || len(body) != len(content) {
+		t.Errorf("Expected %d bytes after the write timeout, got %d (%v)", len(content), len(body), err)
+	}
+}
+
+func TestFileHandlers_DownloadHandler_Range(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+	file := uploadFile(t, h, "dados.txt", []byte("0123456789"))
+
+	rec := download(h, file.ID, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" {
+		t.Fatalf("Expected full content, got %d %q", rec.Code, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+	if etag != `"`+file.SHA256+`"` || rec.Header().Get("Last-Modified") == "" {
+		t.Errorf("Expected SHA-256 ETag and Last-Modified, got %q", etag)
+	}
+	if !strings.Contains(rec.Header().Get("Content-Disposition"), `filename=dados.txt`) {
+		t.Errorf("Unexpected Content-Disposition: %s", rec.Header().Get("Content-Disposition"))
+	}
+
+	rec = download(h, file.ID, map[string]string{"Range": "bytes=2-5"})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "2345" {
+		t.Errorf("Expected partial content 2345, got %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Errorf("Unexpected Content-Range: %s", got)
+	}
+
+	rec = download(h, file.ID, map[string]string{"Range": "bytes=0-1,8-9"})
+	if rec.Code != http.StatusPartialContent || !strings.HasPrefix(rec.Header().Get("Content-Type"), "multipart/byteranges") {
+		t.Errorf("Expected multipart/byteranges, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	rec = download(h, file.ID, map[string]string{"Range": "bytes=5-", "If-Range": etag})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "56789" {
+		t.Errorf("Expected resumed download, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = download(h, file.ID, map[string]string{"Range": "bytes=5-", "If-Range": `"antigo"`})
+	if rec.Code != http.StatusOK || rec.Body.Len() != 10 {
+		t.Errorf("Expected full content for stale If-Range, got %d", rec.Code)
+	}
+
+	rec = download(h, file.ID, map[string]string{"If-None-Match": etag})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected status 304, got %d", rec.Code)
+	}
+}
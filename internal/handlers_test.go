@@ -95,72 +95,6 @@ func TestHandlers_XMLHandler(t *testing.T) {
 	}
 }
 
-func TestHandlers_CreateUserHandler(t *testing.T) {
-	e := setupTestEcho()
-
-	userJSON := `{"name":"Test User","email":"test@example.com","age":25}`
-	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(userJSON))
-	req.Header.Set("Content-Type", "application/json")
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
-
-	h := NewHandlers()
-	err := h.CreateUserHandler(c)
-
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
-
-	if rec.Code != http.StatusCreated {
-		t.Errorf("Expected status 201, got %d", rec.Code)
-	}
-
-	var response map[string]interface{}
-	err = json.Unmarshal(rec.Body.Bytes(), &response)
-	if err != nil {
-		t.Errorf("Failed to unmarshal response: %v", err)
-	}
-
-	if response["success"] != true {
-		t.Error("Expected success to be true")
-	}
-
-	if response["message"] != "Usuário criado com sucesso" {
-		t.Errorf("Unexpected message: %v", response["message"])
-	}
-}
-
-func TestHandlers_CreateUserHandler_InvalidJSON(t *testing.T) {
-	e := setupTestEcho()
-
-	invalidJSON := `{"name":"Test User","email":}`
-	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(invalidJSON))
-	req.Header.Set("Content-Type", "application/json")
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
-
-	h := NewHandlers()
-	err := h.CreateUserHandler(c)
-
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
-
-	if rec.Code != http.StatusBadRequest {
-		t.Errorf("Expected status 400, got %d", rec.Code)
-	}
-
-	var response map[string]interface{}
-	err = json.Unmarshal(rec.Body.Bytes(), &response)
-	if err != nil {
-		t.Errorf("Failed to unmarshal response: %v", err)
-	}
-
-	if response["success"] == true {
-		t.Error("Expected success to be false")
-	}
-}
-
 func TestHandlers_SearchHandler(t *testing.T) {
 	e := setupTestEcho()
 	req := httptest.NewRequest(http.MethodGet, "/search?q=test&category=framework&limit=5", nil)
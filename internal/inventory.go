@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"net/http"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Limites de duração das reservas de estoque
+const (
+	DefaultReservationTTL = 15 * time.Minute
+	MaxReservationTTL     = 24 * time.Hour
+)
+
+// InventoryHandlers contém os handlers de estoque e reservas
+type InventoryHandlers struct {
+	products *repository.ProductRepository
+}
+
+// NewInventoryHandlers cria uma nova instância de handlers de estoque
+func NewInventoryHandlers(products *repository.ProductRepository) *InventoryHandlers {
+	return &InventoryHandlers{products: products}
+}
+
+// StockAdjustmentRequest representa um ajuste manual de estoque
+type StockAdjustmentRequest struct {
+	Delta  int                `json:"delta"`
+	Reason models.StockReason `json:"reason"`
+	Note   string             `json:"note"`
+}
+
+// ReservationRequest representa um pedido de reserva de estoque
+type ReservationRequest struct {
+	Quantity   int `json:"quantity"`
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+// GetStockHandler retorna o estoque atual de um produto
+func (h *InventoryHandlers) GetStockHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	level, err := h.products.Stock(c.Request().Context(), id)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Estoque consultado com sucesso", level))
+}
+
+// AdjustStockHandler aplica um ajuste de estoque com código de motivo
+func (h *InventoryHandlers) AdjustStockHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	req := new(StockAdjustmentRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar ajuste de estoque", err.Error()))
+	}
+
+	movement, err := h.products.AdjustStock(c.Request().Context(), id, req.Delta, req.Reason, req.Note)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	return c.JSON(http.StatusCreated, api.NewSuccessResponse("Estoque ajustado com sucesso", movement))
+}
+
+// ListStockMovementsHandler lista o histórico de movimentações de um produto
+func (h *InventoryHandlers) ListStockMovementsHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	movements, err := h.products.Movements(c.Request().Context(), id)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Movimentações listadas com sucesso", movements))
+}
+
+// CreateReservationHandler reserva estoque para o usuário autenticado
+func (h *InventoryHandlers) CreateReservationHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	req := new(ReservationRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar reserva", err.Error()))
+	}
+
+	ttl := DefaultReservationTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > MaxReservationTTL {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Duração da reserva excede o máximo permitido", ""))
+	}
+
+	userID, _ := middleware.UserID(c)
+	reservation, err := h.products.Reserve(c.Request().Context(), id, userID, req.Quantity, ttl)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	return c.JSON(http.StatusCreated, api.NewSuccessResponse("Reserva criada com sucesso", reservation))
+}
+
+// GetReservationHandler retorna uma reserva do usuário autenticado
+func (h *InventoryHandlers) GetReservationHandler(c echo.Context) error {
+	reservation, ok := h.ownedReservation(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Reserva não encontrada", ""))
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Reserva encontrada", reservation))
+}
+
+// ReleaseReservationHandler cancela uma reserva e devolve o estoque
+func (h *InventoryHandlers) ReleaseReservationHandler(c echo.Context) error {
+	reservation, ok := h.ownedReservation(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Reserva não encontrada", ""))
+	}
+
+	if err := h.products.ReleaseReservation(c.Request().Context(), reservation.ID); err != nil {
+		return repositoryError(c, err, "Reserva não encontrada")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessMessage("Reserva liberada com sucesso"))
+}
+
+// ownedReservation carrega a reserva da rota se ela pertencer ao usuário autenticado
+func (h *InventoryHandlers) ownedReservation(c echo.Context) (*models.Reservation, bool) {
+	reservation, err := h.products.GetReservation(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return nil, false
+	}
+
+	userID, _ := middleware.UserID(c)
+	if reservation.UserID != userID && !middleware.IsAdmin(c) {
+		return nil, false
+	}
+	return reservation, true
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+)
+
+func newInventoryTestRepo() *repository.ProductRepository {
+	p := models.NewProduct("Laptop", "Laptop de alta performance", "Eletrônicos", money.MustParse("2999.99", "BRL"))
+	p.Stock = 2
+	return repository.NewProductRepository(p)
+}
+
+func TestInventoryHandlers_AdjustStock(t *testing.T) {
+	e := setupTestEcho()
+	h := NewInventoryHandlers(newInventoryTestRepo())
+
+	body := `{"delta":5,"reason":"restock","note":"fornecedor"}`
+	req := httptest.NewRequest(http.MethodPost, "/products/1/stock/adjustments", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.AdjustStockHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", rec.Code)
+	}
+
+	var response struct {
+		Data models.StockMovement `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Data.Balance != 7 {
+		t.Errorf("Expected balance 7, got %d", response.Data.Balance)
+	}
+}
+
+func TestInventoryHandlers_AdjustStock_InvalidReason(t *testing.T) {
+	e := setupTestEcho()
+	h := NewInventoryHandlers(newInventoryTestRepo())
+
+	req := httptest.NewRequest(http.MethodPost, "/products/1/stock/adjustments", strings.NewReader(`{"delta":5,"reason":"gift"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.AdjustStockHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestInventoryHandlers_CreateReservation_Insufficient(t *testing.T) {
+	e := setupTestEcho()
+	h := NewInventoryHandlers(newInventoryTestRepo())
+
+	req := httptest.NewRequest(http.MethodPost, "/products/1/reservations", strings.NewReader(`{"quantity":3}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set(middleware.ContextKeyUserID, 7)
+
+	if err := h.CreateReservationHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", rec.Code)
+	}
+}
+
+func TestInventoryHandlers_ReleaseReservation_OtherUser(t *testing.T) {
+	e := setupTestEcho()
+	repo := newInventoryTestRepo()
+	h := NewInventoryHandlers(repo)
+
+	res, err := repo.Reserve(context.Background(), 1, 7, 1, DefaultReservationTTL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/reservations/"+res.ID, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(res.ID)
+	c.Set(middleware.ContextKeyUserID, 8)
+
+	if err := h.ReleaseReservationHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OrderHandlers contém os handlers de checkout e pedidos
+type OrderHandlers struct {
+	products *repository.ProductRepository
+	carts    *repository.CartRepository
+	orders   *repository.OrderRepository
+}
+
+// NewOrderHandlers cria uma nova instância de handlers de pedidos
+func NewOrderHandlers(products *repository.ProductRepository, carts *repository.CartRepository, orders *repository.OrderRepository) *OrderHandlers {
+	return &OrderHandlers{products: products, carts: carts, orders: orders}
+}
+
+// OrderStatusRequest representa uma mudança de status de pedido
+type OrderStatusRequest struct {
+	Status models.OrderStatus `json:"status"`
+}
+
+// CheckoutHandler transforma o carrinho do usuário em um pedido. A baixa no
+// estoque, a criação do pedido e a limpeza do carrinho acontecem na mesma
+// operação do repositório de carrinhos.
+func (h *OrderHandlers) CheckoutHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID, _ := middleware.UserID(c)
+
+	var order *models.Order
+	err := h.carts.Checkout(ctx, userID, func(cart *models.Cart) error {
+		pending, err := models.NewOrder(userID, cart.Total.Currency, cart.Items)
+		if err != nil {
+			return err
+		}
+		if err := h.products.Checkout(ctx, userID, cart.Items, fmt.Sprintf("checkout do usuário %d", userID)); err != nil {
+			return err
+		}
+		order = h.orders.Create(ctx, pending)
+		return nil
+	})
+
+	if errors.Is(err, repository.ErrEmptyCart) {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Carrinho vazio", ""))
+	}
+
+	var priceErr *repository.PriceChangedError
+	if errors.As(err, &priceErr) {
+		if err := h.carts.UpdatePrice(ctx, userID, priceErr.ProductID, priceErr.Current); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"message": "Preço do produto foi alterado; o carrinho foi atualizado",
+			"error":   priceErr.Error(),
+			"data": map[string]interface{}{
+				"product_id":     priceErr.ProductID,
+				"expected_price": priceErr.Expected,
+				"current_price":  priceErr.Current,
+			},
+		})
+	}
+
+	var stockErr *repository.StockShortageError
+	if errors.As(err, &stockErr) {
+		return c.JSON(http.StatusConflict, map[string]interface{}{
+			"success": false,
+			"message": "Estoque insuficiente",
+			"error":   stockErr.Error(),
+			"data": map[string]interface{}{
+				"product_id": stockErr.ProductID,
+				"requested":  stockErr.Requested,
+				"available":  stockErr.Available,
+			},
+		})
+	}
+
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	return c.JSON(http.StatusCreated, api.NewSuccessResponse("Pedido criado com sucesso", order))
+}
+
+// ListOrdersHandler lista os pedidos do usuário autenticado
+func (h *OrderHandlers) ListOrdersHandler(c echo.Context) error {
+	userID, _ := middleware.UserID(c)
+	orders := h.orders.ListByUser(c.Request().Context(), userID)
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Pedidos listados com sucesso", orders))
+}
+
+// GetOrderHandler retorna um pedido do usuário autenticado
+func (h *OrderHandlers) GetOrderHandler(c echo.Context) error {
+	order, ok := h.ownedOrder(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Pedido não encontrado", ""))
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Pedido encontrado", order))
+}
+
+// UpdateOrderStatusHandler move o pedido para um novo status. Clientes
+// podem apenas cancelar seus pedidos; marcar como pago ou enviado é
+// exclusivo de administradores.
+func (h *OrderHandlers) UpdateOrderStatusHandler(c echo.Context) error {
+	order, ok := h.ownedOrder(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Pedido não encontrado", ""))
+	}
+
+	req := new(OrderStatusRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar status do pedido", err.Error()))
+	}
+
+	if req.Status != models.OrderCancelled && !middleware.IsAdmin(c) {
+		return c.JSON(http.StatusForbidden, api.NewErrorResponse("Clientes podem apenas cancelar pedidos", ""))
+	}
+
+	ctx := c.Request().Context()
+	updated, err := h.orders.UpdateStatus(ctx, order.ID, req.Status)
+	if err != nil {
+		return repositoryError(c, err, "Pedido não encontrado")
+	}
+
+	if updated.Status == models.OrderCancelled {
+		h.products.Restock(ctx, updated.Items, fmt.Sprintf("pedido %d cancelado", updated.ID))
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Status do pedido atualizado", updated))
+}
+
+// ownedOrder carrega o pedido da rota se ele pertencer ao usuário autenticado
+func (h *OrderHandlers) ownedOrder(c echo.Context) (*models.Order, bool) {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return nil, false
+	}
+
+	order, err := h.orders.Get(c.Request().Context(), id)
+	if err != nil {
+		return nil, false
+	}
+
+	userID, _ := middleware.UserID(c)
+	if order.UserID != userID && !middleware.IsAdmin(c) {
+		return nil, false
+	}
+	return order, true
+}
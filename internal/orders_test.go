@@ -0,0 +1,259 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+)
+
+type orderTestFixture struct {
+	products *repository.ProductRepository
+	carts    *repository.CartRepository
+	orders   *repository.OrderRepository
+	handlers *OrderHandlers
+}
+
+func newOrderTestFixture(stock int) *orderTestFixture {
+	p := models.NewProduct("Mouse", "Mouse sem fio", "Acessórios", money.MustParse("89.99", "BRL"))
+	p.Stock = stock
+
+	f := &orderTestFixture{
+		products: repository.NewProductRepository(p),
+		carts:    repository.NewCartRepository("BRL"),
+		orders:   repository.NewOrderRepository(),
+	}
+	f.handlers = NewOrderHandlers(f.products, f.carts, f.orders)
+	return f
+}
+
+func (f *orderTestFixture) addToCart(t *testing.T, userID, quantity int) {
+	t.Helper()
+	product, _ := f.products.Get(context.Background(), 1)
+	_, err := f.carts.AddItem(context.Background(), userID, models.CartItem{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Quantity:  quantity,
+		UnitPrice: product.Price,
+	})
+	if err != nil {
+		t.Fatalf("Failed to add item to cart: %v", err)
+	}
+}
+
+func (f *orderTestFixture) checkout(userID int) *httptest.ResponseRecorder {
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middleware.ContextKeyUserID, userID)
+
+	_ = f.handlers.CheckoutHandler(c)
+	return rec
+}
+
+func TestOrderHandlers_Checkout(t *testing.T) {
+	f := newOrderTestFixture(5)
+	f.addToCart(t, 7, 2)
+
+	rec := f.checkout(7)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data models.Order `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Data.Status != models.OrderPending {
+		t.Errorf("Expected status pending, got %s", response.Data.Status)
+	}
+
+	level, _ := f.products.Stock(context.Background(), 1)
+	if level.OnHand != 3 {
+		t.Errorf("Expected 3 units on hand, got %d", level.OnHand)
+	}
+
+	if cart := f.carts.Get(context.Background(), 7); len(cart.Items) != 0 {
+		t.Error("Expected cart to be cleared after checkout")
+	}
+}
+
+func TestOrderHandlers_Checkout_InsufficientStock(t *testing.T) {
+	f := newOrderTestFixture(1)
+	f.addToCart(t, 7, 2)
+
+	rec := f.checkout(7)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", rec.Code)
+	}
+
+	level, _ := f.products.Stock(context.Background(), 1)
+	if level.OnHand != 1 {
+		t.Errorf("Expected stock to be untouched, got %d", level.OnHand)
+	}
+}
+
+func TestOrderHandlers_Checkout_OwnReservation(t *testing.T) {
+	f := newOrderTestFixture(2)
+	if _, err := f.products.Reserve(context.Background(), 1, 7, 2, time.Minute); err != nil {
+		t.Fatalf("Failed to reserve: %v", err)
+	}
+	f.addToCart(t, 7, 2)
+
+	if rec := f.checkout(7); rec.Code != http.StatusCreated {
+		t.Fatalf("Expected the reserved units to be checked out, got %d: %s", rec.Code, rec.Body.String())
+	}
+	level, _ := f.products.Stock(context.Background(), 1)
+	if level.OnHand != 0 || level.Reserved != 0 {
+		t.Errorf("Expected reservation to be committed, got %+v", level)
+	}
+}
+
+func TestOrderHandlers_Checkout_PriceChanged(t *testing.T) {
+	f := newOrderTestFixture(5)
+	f.addToCart(t, 7, 1)
+
+	_, err := f.products.Update(context.Background(), 1, &models.Product{Name: "Mouse", Price: money.MustParse("99.99", "BRL")})
+	if err != nil {
+		t.Fatalf("Failed to update product: %v", err)
+	}
+
+	rec := f.checkout(7)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", rec.Code)
+	}
+
+	cart := f.carts.Get(context.Background(), 7)
+	if cart.Items[0].UnitPrice.Decimal() != "99.99" {
+		t.Errorf("Expected cart price to be refreshed, got %s", cart.Items[0].UnitPrice)
+	}
+
+	if rec := f.checkout(7); rec.Code != http.StatusCreated {
+		t.Errorf("Expected second checkout to succeed, got %d", rec.Code)
+	}
+}
+
+func TestOrderHandlers_CancelRestocks(t *testing.T) {
+	f := newOrderTestFixture(5)
+	f.addToCart(t, 7, 2)
+	f.checkout(7)
+
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPatch, "/orders/1", strings.NewReader(`{"status":"cancelled"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set(middleware.ContextKeyUserID, 7)
+
+	if err := f.handlers.UpdateOrderStatusHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	level, _ := f.products.Stock(context.Background(), 1)
+	if level.OnHand != 5 {
+		t.Errorf("Expected stock to be restored to 5, got %d", level.OnHand)
+	}
+}
+
+func TestOrderHandlers_ShipRequiresAdmin(t *testing.T) {
+	f := newOrderTestFixture(5)
+	f.addToCart(t, 7, 1)
+	f.checkout(7)
+	_, _ = f.orders.UpdateStatus(context.Background(), 1, models.OrderPaid)
+
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPatch, "/orders/1", strings.NewReader(`{"status":"shipped"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	c.Set(middleware.ContextKeyUserID, 7)
+
+	if err := f.handlers.UpdateOrderStatusHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", rec.Code)
+	}
+}
+
+func TestOrderHandlers_CustomerCanOnlyCancel(t *testing.T) {
+	f := newOrderTestFixture(5)
+	f.addToCart(t, 7, 1)
+	f.checkout(7)
+
+	update := func(status, role string) int {
+		e := setupTestEcho()
+		req := httptest.NewRequest(http.MethodPatch, "/orders/1", strings.NewReader(`{"status":"`+status+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set(middleware.ContextKeyUserID, 7)
+		c.Set(middleware.ContextKeyRole, role)
+		_ = f.handlers.UpdateOrderStatusHandler(c)
+		return rec.Code
+	}
+
+	if code := update("paid", middleware.RoleUser); code != http.StatusForbidden {
+		t.Errorf("Expected status 403 when a customer pays, got %d", code)
+	}
+	if order, _ := f.orders.Get(context.Background(), 1); order.Status != models.OrderPending {
+		t.Errorf("Expected order to stay pending, got %s", order.Status)
+	}
+	if code := update("paid", middleware.RoleAdmin); code != http.StatusOK {
+		t.Errorf("Expected admin to mark as paid, got %d", code)
+	}
+}
+
+func TestOrderHandlers_Checkout_Concurrent(t *testing.T) {
+	f := newOrderTestFixture(10)
+	f.addToCart(t, 7, 2)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- f.checkout(7).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	created := 0
+	for code := range codes {
+		if code == http.StatusCreated {
+			created++
+		}
+	}
+	if created != 1 || len(f.orders.ListByUser(context.Background(), 7)) != 1 {
+		t.Errorf("Expected a single order from concurrent checkouts, got %d", created)
+	}
+	if level, _ := f.products.Stock(context.Background(), 1); level.OnHand != 8 {
+		t.Errorf("Expected 8 units on hand, got %d", level.OnHand)
+	}
+}
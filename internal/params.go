@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+
+	"github.com/labstack/echo/v4"
+)
+
+// intParam converte um parâmetro de rota para inteiro positivo
+func intParam(c echo.Context, name string) (int, error) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil {
+		return 0, err
+	}
+	if id <= 0 {
+		return 0, errors.New("o valor deve ser positivo")
+	}
+	return id, nil
+}
+
+// Limites de paginação das listagens
+const (
+	DefaultPerPage = 10
+	MaxPerPage     = 100
+)
+
+// paginationParams lê ?page= e ?per_page=, aplicando padrões e limites
+func paginationParams(c echo.Context) (page, perPage int) {
+	page, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	perPage, err = strconv.Atoi(c.QueryParam("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = DefaultPerPage
+	}
+	if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+
+	return page, perPage
+}
+
+// repositoryError converte erros dos repositórios em respostas HTTP
+func repositoryError(c echo.Context, err error, notFound string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse(notFound, err.Error()))
+	case errors.Is(err, repository.ErrInsufficientStock):
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Estoque insuficiente", err.Error()))
+	case errors.Is(err, repository.ErrPriceChanged):
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Preço do produto foi alterado", err.Error()))
+	case errors.Is(err, repository.ErrInvalidTransition):
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Mudança de status não permitida", err.Error()))
+	case errors.Is(err, repository.ErrDuplicate):
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Registro já existe", err.Error()))
+	case errors.Is(err, repository.ErrReservationClosed):
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Reserva não está ativa", err.Error()))
+	case errors.Is(err, repository.ErrInvalidToken):
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Token de verificação inválido", err.Error()))
+	case errors.Is(err, repository.ErrInvalidQuantity), errors.Is(err, repository.ErrInvalidReason):
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Dados inválidos", err.Error()))
+	}
+	return err
+}
@@ -0,0 +1,236 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/metrics"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Valores padrão do feed de eventos de produtos
+const (
+	ProductEventsReplay    = 256
+	ProductEventsHeartbeat = 15 * time.Second
+)
+
+// productEventsBuffer é o número de eventos aguardando envio a cada
+// cliente; um cliente mais lento que isso é desconectado e deve retomar o
+// feed com Last-Event-ID
+const productEventsBuffer = 64
+
+// sseRetry é o intervalo de reconexão sugerido aos clientes
+const sseRetry = 3 * time.Second
+
+// ProductEvent é um evento do feed de produtos, com os dados já codificados
+type ProductEvent struct {
+	ID   uint64
+	Type repository.ChangeType
+	Data []byte
+}
+
+// productEventData é o conteúdo JSON de um evento
+type productEventData struct {
+	Type    repository.ChangeType `json:"type"`
+	Product models.Product        `json:"product"`
+	At      time.Time             `json:"at"`
+}
+
+// productSubscriber é um cliente conectado ao feed
+type productSubscriber struct {
+	events chan ProductEvent
+}
+
+// ProductEventHandlers publica as alterações do catálogo como Server-Sent
+// Events. Os últimos eventos ficam guardados para que um cliente reconectado
+// retome o feed a partir do header Last-Event-ID.
+type ProductEventHandlers struct {
+	mu          sync.Mutex
+	nextID      uint64
+	replay      []ProductEvent
+	replaySize  int
+	subscribers map[*productSubscriber]struct{}
+	heartbeat   time.Duration
+	metrics     *metrics.Metrics
+}
+
+// NewProductEventHandlers cria o feed, assinando as alterações de produtos
+// no barramento de eventos. replay é o número de eventos guardados para
+// retomada e heartbeat o intervalo entre comentários que mantêm a conexão
+// aberta.
+func NewProductEventHandlers(bus *eventbus.Bus, replay int, heartbeat time.Duration) *ProductEventHandlers {
+	if heartbeat <= 0 {
+		heartbeat = ProductEventsHeartbeat
+	}
+	h := &ProductEventHandlers{
+		nextID:      1,
+		replaySize:  replay,
+		subscribers: make(map[*productSubscriber]struct{}),
+		heartbeat:   heartbeat,
+	}
+	eventbus.Subscribe(bus, "product-events", h.publish)
+	return h
+}
+
+// SetMetrics passa a contar as conexões SSE abertas em m
+func (h *ProductEventHandlers) SetMetrics(m *metrics.Metrics) {
+	h.metrics = m
+}
+
+// EventsHandler envia o feed de alterações de produtos. Cada evento tem um
+// ID crescente; com Last-Event-ID (header ou ?last_event_id=) os eventos
+// seguintes guardados são reenviados antes dos novos. Se eles já foram
+// descartados, o evento "reset" avisa que o cliente deve recarregar a lista.
+func (h *ProductEventHandlers) EventsHandler(c echo.Context) error {
+	lastID, resume := lastEventID(c)
+	sub, replay, reset := h.subscribe(lastID, resume)
+	defer h.unsubscribe(sub)
+	defer h.metrics.ConnectionOpened(metrics.ConnectionSSE)()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	disableWriteDeadline(res)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+		return nil
+	}
+	if reset {
+		if _, err := fmt.Fprint(res, "event: reset\ndata: {}\n\n"); err != nil {
+			return nil
+		}
+	}
+	for _, event := range replay {
+		if err := writeProductEvent(res, event); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case event, ok := <-sub.events:
+			if !ok {
+				// Cliente lento descartado pelo publish
+				return nil
+			}
+			if err := writeProductEvent(res, event); err != nil {
+				return nil
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// publish registra uma alteração do repositório e a entrega aos clientes.
+// Não bloqueia: clientes sem espaço na fila são desconectados.
+func (h *ProductEventHandlers) publish(ctx context.Context, change repository.ProductChange) error {
+	data, err := json.Marshal(productEventData{Type: change.Type, Product: change.Product, At: change.At.UTC()})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	event := ProductEvent{ID: h.nextID, Type: change.Type, Data: data}
+	h.nextID++
+
+	if h.replaySize > 0 {
+		h.replay = append(h.replay, event)
+		if len(h.replay) > h.replaySize {
+			h.replay = append([]ProductEvent(nil), h.replay[len(h.replay)-h.replaySize:]...)
+		}
+	}
+
+	for sub := range h.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			delete(h.subscribers, sub)
+			close(sub.events)
+		}
+	}
+	return nil
+}
+
+// subscribe registra um cliente e, na retomada, retorna os eventos após
+// lastID. reset indica que parte dos eventos perdidos já foi descartada.
+func (h *ProductEventHandlers) subscribe(lastID uint64, resume bool) (sub *productSubscriber, replay []ProductEvent, reset bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &productSubscriber{events: make(chan ProductEvent, productEventsBuffer)}
+	h.subscribers[sub] = struct{}{}
+
+	if !resume {
+		return sub, nil, false
+	}
+	oldest := h.nextID
+	if len(h.replay) > 0 {
+		oldest = h.replay[0].ID
+	}
+	if lastID >= h.nextID || lastID+1 < oldest {
+		return sub, nil, true
+	}
+	for _, event := range h.replay {
+		if event.ID > lastID {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, false
+}
+
+// unsubscribe remove um cliente do feed
+func (h *ProductEventHandlers) unsubscribe(sub *productSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers, sub)
+}
+
+// lastEventID lê o último evento recebido pelo cliente. O header é enviado
+// pelo EventSource ao reconectar; o parâmetro permite retomar em uma nova
+// conexão.
+func lastEventID(c echo.Context) (uint64, bool) {
+	value := c.Request().Header.Get("Last-Event-ID")
+	if value == "" {
+		value = c.QueryParam("last_event_id")
+	}
+	if value == "" {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// writeProductEvent escreve um evento no formato text/event-stream
+func writeProductEvent(w http.ResponseWriter, event ProductEvent) error {
+	_, err := fmt.Fprintf(w, "id: %d\nevent: product.%s\ndata: %s\n\n", event.ID, event.Type, event.Data)
+	return err
+}
@@ -0,0 +1,257 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/metrics"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+
+	"github.com/labstack/echo/v4"
+)
+
+// sseEvent é um bloco recebido do feed; heartbeats vêm como comentário
+type sseEvent struct {
+	id, event, data, comment, retry string
+}
+
+type productEventsFixture struct {
+	url      string
+	repo     *repository.ProductRepository
+	handlers *ProductEventHandlers
+}
+
+func newProductEventsFixture(t *testing.T, replay int, heartbeat time.Duration) *productEventsFixture {
+	t.Helper()
+	repo := repository.NewProductRepository()
+	bus := eventbus.New(eventbus.Options{})
+	repo.SetEventBus(bus)
+	h := NewProductEventHandlers(bus, replay, heartbeat)
+
+	e := echo.New()
+	e.GET("/products/events", h.EventsHandler)
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return &productEventsFixture{url: server.URL + "/products/events", repo: repo, handlers: h}
+}
+
+// connect abre o feed e entrega os blocos recebidos no canal retornado
+func (f *productEventsFixture) connect(t *testing.T, lastEventID string) (<-chan sseEvent, context.CancelFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if ct := resp.Header.Get(echo.HeaderContentType); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	events := make(chan sseEvent, 100)
+	go func() {
+		defer resp.Body.Close()
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		var ev sseEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if ev != (sseEvent{}) {
+					events <- ev
+				}
+				ev = sseEvent{}
+			case strings.HasPrefix(line, ":"):
+				ev.comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "id: "):
+				ev.id = line[4:]
+			case strings.HasPrefix(line, "event: "):
+				ev.event = line[7:]
+			case strings.HasPrefix(line, "data: "):
+				ev.data = line[6:]
+			case strings.HasPrefix(line, "retry: "):
+				ev.retry = line[7:]
+			}
+		}
+	}()
+
+	// O primeiro bloco é a sugestão de retry
+	if ev := f.next(t, events); ev.retry == "" {
+		t.Fatalf("Expected retry block first, got %+v", ev)
+	}
+	return events, cancel
+}
+
+func (f *productEventsFixture) next(t *testing.T, events <-chan sseEvent) sseEvent {
+	t.Helper()
+	select {
+	case ev, ok := <-events:
+		if !ok {
+			t.Fatal("Expected event, got closed stream")
+		}
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for event")
+	}
+	return sseEvent{}
+}
+
+// waitSubscribers espera o número de clientes conectados chegar a n
+func (f *productEventsFixture) waitSubscribers(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		f.handlers.mu.Lock()
+		got := len(f.handlers.subscribers)
+		f.handlers.mu.Unlock()
+		if got == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Expected %d subscribers", n)
+}
+
+func testProduct(name string) *models.Product {
+	return models.NewProduct(name, "", "Teste", money.MustParse("10.00", "BRL"))
+}
+
+func TestProductEvents_PublishesChanges(t *testing.T) {
+	f := newProductEventsFixture(t, 10, time.Minute)
+	events, _ := f.connect(t, "")
+	ctx := context.Background()
+
+	created, _ := f.repo.Create(ctx, testProduct("Caneta"))
+	f.repo.Update(ctx, created.ID, testProduct("Caneta azul"))
+	f.repo.Delete(ctx, created.ID)
+
+	for i, want := range []string{"product.created", "product.updated", "product.deleted"} {
+		ev := f.next(t, events)
+		if ev.event != want || ev.id != []string{"1", "2", "3"}[i] {
+			t.Errorf("Expected %s with id %d, got %+v", want, i+1, ev)
+		}
+		var data struct {
+			Type    string         `json:"type"`
+			Product models.Product `json:"product"`
+		}
+		if err := json.Unmarshal([]byte(ev.data), &data); err != nil {
+			t.Fatalf("Failed to decode %q: %v", ev.data, err)
+		}
+		if data.Product.ID != created.ID || "product."+data.Type != want {
+			t.Errorf("Unexpected data for %s: %s", want, ev.data)
+		}
+	}
+}
+
+func TestProductEvents_ResumeFromLastEventID(t *testing.T) {
+	f := newProductEventsFixture(t, 3, time.Minute)
+	ctx := context.Background()
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		f.repo.Create(ctx, testProduct(name))
+	}
+
+	// Eventos 3, 4 e 5 estão guardados; retomar após o 3 reenvia 4 e 5
+	events, _ := f.connect(t, "3")
+	for _, want := range []string{"4", "5"} {
+		if ev := f.next(t, events); ev.id != want {
+			t.Errorf("Expected replayed event %s, got %+v", want, ev)
+		}
+	}
+	f.repo.Create(ctx, testProduct("F"))
+	if ev := f.next(t, events); ev.id != "6" {
+		t.Errorf("Expected live event 6 after replay, got %+v", ev)
+	}
+
+	// Nada perdido: nenhum evento reenviado
+	events, _ = f.connect(t, "6")
+	f.repo.Create(ctx, testProduct("G"))
+	if ev := f.next(t, events); ev.id != "7" {
+		t.Errorf("Expected live event 7, got %+v", ev)
+	}
+
+	for _, lastID := range []string{"1", "99"} {
+		events, _ = f.connect(t, lastID)
+		if ev := f.next(t, events); ev.event != "reset" {
+			t.Errorf("Expected reset for Last-Event-ID %s, got %+v", lastID, ev)
+		}
+	}
+}
+
+func TestProductEvents_Heartbeat(t *testing.T) {
+	f := newProductEventsFixture(t, 10, 20*time.Millisecond)
+	events, _ := f.connect(t, "")
+
+	if ev := f.next(t, events); ev.comment != "heartbeat" {
+		t.Errorf("Expected heartbeat comment, got %+v", ev)
+	}
+}
+
+func TestProductEvents_ClientDisconnect(t *testing.T) {
+	f := newProductEventsFixture(t, 10, time.Minute)
+	_, cancel := f.connect(t, "")
+	f.waitSubscribers(t, 1)
+
+	cancel()
+	f.waitSubscribers(t, 0)
+}
+
+func TestProductEvents_ConnectionMetrics(t *testing.T) {
+	f := newProductEventsFixture(t, 10, time.Minute)
+	m := metrics.New()
+	f.handlers.SetMetrics(m)
+	scrape := func() string {
+		rec := httptest.NewRecorder()
+		m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		return rec.Body.String()
+	}
+
+	_, cancel := f.connect(t, "")
+	f.waitSubscribers(t, 1)
+	if got := scrape(); !strings.Contains(got, `echo_playground_active_connections{type="sse"} 1`) {
+		t.Errorf("Expected one open SSE connection, got:\n%s", got)
+	}
+
+	cancel()
+	f.waitSubscribers(t, 0)
+	if got := scrape(); !strings.Contains(got, `echo_playground_active_connections{type="sse"} 0`) {
+		t.Errorf("Expected no open SSE connection, got:\n%s", got)
+	}
+}
+
+func TestProductEvents_SlowSubscriberIsDropped(t *testing.T) {
+	repo := repository.NewProductRepository()
+	bus := eventbus.New(eventbus.Options{})
+	repo.SetEventBus(bus)
+	h := NewProductEventHandlers(bus, 10, time.Minute)
+	sub, _, _ := h.subscribe(0, false)
+
+	for i := 0; i <= productEventsBuffer; i++ {
+		repo.Create(context.Background(), testProduct("P"))
+	}
+
+	if _, ok := h.subscribers[sub]; ok {
+		t.Error("Expected slow subscriber to be removed")
+	}
+	n := 0
+	for range sub.events {
+		n++
+	}
+	if n != productEventsBuffer {
+		t.Errorf("Expected %d buffered events before the channel closed, got %d", productEventsBuffer, n)
+	}
+}
@@ -4,31 +4,35 @@ import (
 	"fmt"
 	"net/http"
 
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
 	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
 
 	"github.com/labstack/echo/v4"
 )
 
-// ProductHandlers contém os handlers relacionados a produtos
-type ProductHandlers struct{}
+// ProductHandlers contém os handlers relacionados a produtos. Os preços são
+// armazenados na moeda base do conversor e podem ser exibidos em outra
+// moeda com o parâmetro ?currency=
+type ProductHandlers struct {
+	products  *repository.ProductRepository
+	reviews   *repository.ReviewRepository
+	converter *money.Converter
+}
 
 // NewProductHandlers cria uma nova instância de handlers de produtos
-func NewProductHandlers() *ProductHandlers {
-	return &ProductHandlers{}
+func NewProductHandlers(products *repository.ProductRepository, reviews *repository.ReviewRepository, converter *money.Converter) *ProductHandlers {
+	return &ProductHandlers{products: products, reviews: reviews, converter: converter}
 }
 
 // ListProductsHandler lista todos os produtos
 func (h *ProductHandlers) ListProductsHandler(c echo.Context) error {
-	products := []*models.Product{
-		models.NewProduct("Laptop", "Laptop de alta performance", "Eletrônicos", 2999.99),
-		models.NewProduct("Mouse", "Mouse sem fio", "Acessórios", 89.99),
-		models.NewProduct("Teclado", "Teclado mecânico", "Acessórios", 199.99),
+	products := h.products.List(c.Request().Context())
+	if err := h.convertPrices(c.QueryParam("currency"), products...); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Moeda não suportada", err.Error()))
 	}
-
-	// Definir IDs
-	products[0].SetID(1)
-	products[1].SetID(2)
-	products[2].SetID(3)
+	h.attachRatings(c, products...)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
@@ -39,10 +43,19 @@ func (h *ProductHandlers) ListProductsHandler(c echo.Context) error {
 
 // GetProductHandler obtém um produto específico
 func (h *ProductHandlers) GetProductHandler(c echo.Context) error {
-	_ = c.Param("id") // Usar o parâmetro para evitar warning
-	// Simular busca no banco de dados
-	product := models.NewProduct("Laptop", "Laptop de alta performance", "Eletrônicos", 2999.99)
-	product.SetID(1)
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	product, err := h.products.Get(c.Request().Context(), id)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+	if err := h.convertPrices(c.QueryParam("currency"), product); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Moeda não suportada", err.Error()))
+	}
+	h.attachRatings(c, product)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
@@ -62,18 +75,38 @@ func (h *ProductHandlers) CreateProductHandler(c echo.Context) error {
 		})
 	}
 
-	product.SetID(4) // Simular ID gerado
+	if msg := h.normalizeProduct(product); msg != "" {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(msg, ""))
+	}
+
+	currency := c.QueryParam("currency")
+	if err := h.checkCurrency(currency); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Moeda não suportada", err.Error()))
+	}
+
+	created, err := h.products.Create(c.Request().Context(), product)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+	if err := h.convertPrices(currency, created); err != nil {
+		return c.JSON(http.StatusInternalServerError, api.NewErrorResponse("Erro ao converter preço", err.Error()))
+	}
+	h.attachRatings(c, created)
 
 	return c.JSON(http.StatusCreated, map[string]interface{}{
 		"success": true,
 		"message": "Produto criado com sucesso",
-		"data":    product,
+		"data":    created,
 	})
 }
 
 // UpdateProductHandler atualiza um produto existente
 func (h *ProductHandlers) UpdateProductHandler(c echo.Context) error {
-	_ = c.Param("id") // Usar o parâmetro para evitar warning
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
 	product := new(models.Product)
 	if err := c.Bind(product); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]interface{}{
@@ -83,21 +116,104 @@ func (h *ProductHandlers) UpdateProductHandler(c echo.Context) error {
 		})
 	}
 
-	product.SetID(1) // Simular ID do produto atualizado
+	if msg := h.normalizeProduct(product); msg != "" {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(msg, ""))
+	}
+
+	currency := c.QueryParam("currency")
+	if err := h.checkCurrency(currency); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Moeda não suportada", err.Error()))
+	}
+
+	updated, err := h.products.Update(c.Request().Context(), id, product)
+	if err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+	if err := h.convertPrices(currency, updated); err != nil {
+		return c.JSON(http.StatusInternalServerError, api.NewErrorResponse("Erro ao converter preço", err.Error()))
+	}
+	h.attachRatings(c, updated)
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
 		"message": "Produto atualizado com sucesso",
-		"data":    product,
+		"data":    updated,
 	})
 }
 
 // DeleteProductHandler remove um produto
 func (h *ProductHandlers) DeleteProductHandler(c echo.Context) error {
-	id := c.Param("id")
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	if err := h.products.Delete(c.Request().Context(), id); err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
 		"success": true,
-		"message": fmt.Sprintf("Produto com ID %s deletado com sucesso", id),
+		"message": fmt.Sprintf("Produto com ID %d deletado com sucesso", id),
 	})
 }
+
+// normalizeProduct valida o produto e converte seu preço para a moeda base.
+// Retorna uma mensagem de erro se o produto for inválido.
+func (h *ProductHandlers) normalizeProduct(p *models.Product) string {
+	p.Rating = nil
+	if p.Name == "" {
+		return "Nome é obrigatório"
+	}
+	if !p.Price.Valid() {
+		return "Preço deve informar amount e uma moeda ISO 4217 suportada"
+	}
+	if !p.Price.IsPositive() {
+		return "Preço deve ser maior que zero"
+	}
+	if p.Stock < 0 {
+		return "Estoque não pode ser negativo"
+	}
+
+	price, err := h.converter.Convert(p.Price, h.converter.Base())
+	if err != nil {
+		return "Moeda do preço sem cotação configurada"
+	}
+	p.Price = price
+	return ""
+}
+
+// checkCurrency verifica se há cotação para a moeda de exibição informada.
+// Usado antes de gravar, para que uma moeda inválida não deixe alterações feitas
+func (h *ProductHandlers) checkCurrency(currency string) error {
+	if currency == "" {
+		return nil
+	}
+	_, err := h.converter.Convert(money.Money{Currency: h.converter.Base()}, currency)
+	return err
+}
+
+// convertPrices converte os preços dos produtos para a moeda informada
+func (h *ProductHandlers) convertPrices(currency string, products ...*models.Product) error {
+	if currency == "" {
+		return nil
+	}
+
+	for _, p := range products {
+		price, err := h.converter.Convert(p.Price, currency)
+		if err != nil {
+			return err
+		}
+		p.Price = price
+	}
+	return nil
+}
+
+// attachRatings inclui nos produtos o resumo das avaliações visíveis
+func (h *ProductHandlers) attachRatings(c echo.Context, products ...*models.Product) {
+	summaries := h.reviews.Summaries(c.Request().Context())
+	for _, p := range products {
+		summary := summaries[p.ID]
+		p.Rating = &summary
+	}
+}
@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+)
+
+func newProductTestHandlers(t *testing.T) *ProductHandlers {
+	t.Helper()
+	converter, err := money.NewConverter("BRL", map[string]string{"USD": "0.20"})
+	if err != nil {
+		t.Fatalf("Failed to create converter: %v", err)
+	}
+
+	repo := repository.NewProductRepository(
+		models.NewProduct("Laptop", "Laptop de alta performance", "Eletrônicos", money.MustParse("3000.00", "BRL")),
+	)
+	return NewProductHandlers(repo, repository.NewReviewRepository(), converter)
+}
+
+func TestProductHandlers_GetProduct_Currency(t *testing.T) {
+	e := setupTestEcho()
+	h := newProductTestHandlers(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1?currency=USD", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.GetProductHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response struct {
+		Data models.Product `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Data.Price != money.MustParse("600.00", "USD") {
+		t.Errorf("Expected 600.00 USD, got %s", response.Data.Price)
+	}
+}
+
+func TestProductHandlers_ListProducts_UnsupportedCurrency(t *testing.T) {
+	e := setupTestEcho()
+	h := newProductTestHandlers(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/products?currency=XYZ", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.ListProductsHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestProductHandlers_CreateProduct_NormalizesCurrency(t *testing.T) {
+	e := setupTestEcho()
+	h := newProductTestHandlers(t)
+
+	body := `{"name":"Mouse","price":{"amount":2000,"currency":"USD"},"description":"Mouse sem fio","category":"Acessórios"}`
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.CreateProductHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Data models.Product `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if response.Data.Price != money.MustParse("100.00", "BRL") {
+		t.Errorf("Expected 100.00 BRL, got %s", response.Data.Price)
+	}
+}
+
+func TestProductHandlers_CreateProduct_FloatPriceRejected(t *testing.T) {
+	e := setupTestEcho()
+	h := newProductTestHandlers(t)
+
+	body := `{"name":"Mouse","price":89.99}`
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.CreateProductHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestProductHandlers_CreateProduct_UnsupportedCurrencyNotSaved(t *testing.T) {
+	e := setupTestEcho()
+	h := newProductTestHandlers(t)
+
+	body := `{"name":"Mouse","price":{"amount":2000,"currency":"USD"}}`
+	req := httptest.NewRequest(http.MethodPost, "/products?currency=XYZ", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.CreateProductHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if products := h.products.List(req.Context()); len(products) != 1 {
+		t.Errorf("Expected 1 product after rejected create, got %d", len(products))
+	}
+}
+
+func TestProductHandlers_UpdateProduct_UnsupportedCurrencyNotSaved(t *testing.T) {
+	e := setupTestEcho()
+	h := newProductTestHandlers(t)
+
+	body := `{"name":"Notebook","price":{"amount":250000,"currency":"BRL"}}`
+	req := httptest.NewRequest(http.MethodPut, "/products/1?currency=XYZ", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.UpdateProductHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	product, err := h.products.Get(req.Context(), 1)
+	if err != nil {
+		t.Fatalf("Failed to get product: %v", err)
+	}
+	if product.Name != "Laptop" {
+		t.Errorf("Expected name Laptop after rejected update, got %s", product.Name)
+	}
+}
+
+func TestProductHandlers_CreateProduct_IncludesRating(t *testing.T) {
+	e := setupTestEcho()
+	h := newProductTestHandlers(t)
+
+	body := `{"name":"Mouse","price":{"amount":2000,"currency":"BRL"}}`
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.CreateProductHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response struct {
+		Data models.Product `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Data.Rating == nil {
+		t.Errorf("Expected rating summary in create response, got none")
+	}
+}
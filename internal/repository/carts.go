@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+)
+
+// ErrEmptyCart indica um checkout de carrinho sem itens
+var ErrEmptyCart = errors.New("carrinho vazio")
+
+// CartRepository armazena os carrinhos de compras por usuário
+type CartRepository struct {
+	mu       sync.Mutex
+	carts    map[int]*models.Cart
+	currency string
+}
+
+// NewCartRepository cria um repositório de carrinhos na moeda informada
+func NewCartRepository(currency string) *CartRepository {
+	return &CartRepository{carts: make(map[int]*models.Cart), currency: currency}
+}
+
+// Get retorna o carrinho do usuário, vazio se ainda não existir
+func (r *CartRepository) Get(ctx context.Context, userID int) *models.Cart {
+	_, span := startSpan(ctx, "carts", "Get")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return copyCart(r.cartLocked(userID))
+}
+
+// AddItem soma a quantidade informada à linha do produto
+func (r *CartRepository) AddItem(ctx context.Context, userID int, item models.CartItem) (*models.Cart, error) {
+	_, span := startSpan(ctx, "carts", "AddItem")
+	defer span.End()
+
+	if item.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if item.UnitPrice.Currency != r.currency {
+		return nil, money.ErrCurrencyMismatch
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.cartLocked(userID)
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == item.ProductID {
+			cart.Items[i].Quantity += item.Quantity
+			cart.Items[i].Name = item.Name
+			cart.Items[i].UnitPrice = item.UnitPrice
+			return r.touchLocked(cart)
+		}
+	}
+
+	cart.Items = append(cart.Items, item)
+	return r.touchLocked(cart)
+}
+
+// SetItem define a quantidade de uma linha existente; zero remove a linha
+func (r *CartRepository) SetItem(ctx context.Context, userID, productID, quantity int) (*models.Cart, error) {
+	_, span := startSpan(ctx, "carts", "SetItem")
+	defer span.End()
+
+	if quantity < 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.cartLocked(userID)
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			if quantity == 0 {
+				cart.Items = append(cart.Items[:i], cart.Items[i+1:]...)
+			} else {
+				cart.Items[i].Quantity = quantity
+			}
+			return r.touchLocked(cart)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// UpdatePrice atualiza o preço registrado de um produto no carrinho
+func (r *CartRepository) UpdatePrice(ctx context.Context, userID, productID int, price money.Money) error {
+	_, span := startSpan(ctx, "carts", "UpdatePrice")
+	defer span.End()
+
+	if price.Currency != r.currency {
+		return money.ErrCurrencyMismatch
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart := r.cartLocked(userID)
+	for i := range cart.Items {
+		if cart.Items[i].ProductID == productID {
+			cart.Items[i].UnitPrice = price
+			_, err := r.touchLocked(cart)
+			return err
+		}
+	}
+	return ErrNotFound
+}
+
+// RemoveItem remove a linha de um produto do carrinho
+func (r *CartRepository) RemoveItem(ctx context.Context, userID, productID int) (*models.Cart, error) {
+	return r.SetItem(ctx, userID, productID, 0)
+}
+
+// Clear esvazia o carrinho do usuário
+func (r *CartRepository) Clear(ctx context.Context, userID int) {
+	_, span := startSpan(ctx, "carts", "Clear")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.carts, userID)
+}
+
+// Checkout chama place com o carrinho do usuário e o esvazia se place
+// tiver sucesso, tudo sob a mesma trava: o carrinho não muda enquanto o
+// pedido é criado, e checkouts simultâneos do mesmo carrinho não geram dois
+// pedidos. Um carrinho vazio retorna ErrEmptyCart sem chamar place. place
+// não pode chamar outros métodos do repositório de carrinhos.
+func (r *CartRepository) Checkout(ctx context.Context, userID int, place func(cart *models.Cart) error) error {
+	_, span := startSpan(ctx, "carts", "Checkout")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cart, ok := r.carts[userID]
+	if !ok || len(cart.Items) == 0 {
+		return ErrEmptyCart
+	}
+	if err := place(copyCart(cart)); err != nil {
+		return err
+	}
+
+	delete(r.carts, userID)
+	return nil
+}
+
+// cartLocked retorna o carrinho do usuário, criando-o se necessário
+func (r *CartRepository) cartLocked(userID int) *models.Cart {
+	cart, ok := r.carts[userID]
+	if !ok {
+		cart = &models.Cart{
+			UserID:    userID,
+			Items:     []models.CartItem{},
+			Total:     money.Zero(r.currency),
+			UpdatedAt: time.Now(),
+		}
+		r.carts[userID] = cart
+	}
+	return cart
+}
+
+// touchLocked recalcula o total e devolve uma cópia do carrinho
+func (r *CartRepository) touchLocked(cart *models.Cart) (*models.Cart, error) {
+	if err := cart.Recalculate(); err != nil {
+		return nil, err
+	}
+	cart.UpdatedAt = time.Now()
+	return copyCart(cart), nil
+}
+
+// copyCart cria uma cópia independente do carrinho
+func copyCart(cart *models.Cart) *models.Cart {
+	cp := *cart
+	cp.Items = make([]models.CartItem, len(cart.Items))
+	copy(cp.Items, cart.Items)
+	return &cp
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+)
+
+func TestCartRepository_AddAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCartRepository("BRL")
+
+	item := models.CartItem{ProductID: 1, Name: "Mouse", Quantity: 1, UnitPrice: money.MustParse("50", "BRL")}
+	if _, err := repo.AddItem(ctx, 7, item); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cart, err := repo.AddItem(ctx, 7, item)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cart.Items) != 1 || cart.Items[0].Quantity != 2 {
+		t.Errorf("Expected a single line with quantity 2, got %+v", cart.Items)
+	}
+	if cart.Total.Decimal() != "100.00" {
+		t.Errorf("Expected total 100.00, got %s", cart.Total)
+	}
+
+	cart, _ = repo.SetItem(ctx, 7, 1, 0)
+	if len(cart.Items) != 0 {
+		t.Errorf("Expected empty cart, got %+v", cart.Items)
+	}
+
+	if _, err := repo.SetItem(ctx, 7, 1, 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCartRepository_IsolatedPerUser(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCartRepository("BRL")
+
+	_, _ = repo.AddItem(ctx, 7, models.CartItem{ProductID: 1, Quantity: 1, UnitPrice: money.MustParse("10", "BRL")})
+
+	if cart := repo.Get(ctx, 8); len(cart.Items) != 0 {
+		t.Errorf("Expected empty cart for another user, got %+v", cart.Items)
+	}
+}
+
+func TestCartRepository_RejectsOtherCurrency(t *testing.T) {
+	repo := NewCartRepository("BRL")
+
+	_, err := repo.AddItem(context.Background(), 7, models.CartItem{ProductID: 1, Quantity: 1, UnitPrice: money.MustParse("10", "USD")})
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestCartRepository_Checkout(t *testing.T) {
+	ctx := context.Background()
+	repo := NewCartRepository("BRL")
+
+	if err := repo.Checkout(ctx, 7, func(*models.Cart) error { return nil }); !errors.Is(err, ErrEmptyCart) {
+		t.Errorf("Expected ErrEmptyCart, got %v", err)
+	}
+
+	_, _ = repo.AddItem(ctx, 7, models.CartItem{ProductID: 1, Quantity: 2, UnitPrice: money.MustParse("10", "BRL")})
+
+	failure := errors.New("falha")
+	if err := repo.Checkout(ctx, 7, func(*models.Cart) error { return failure }); !errors.Is(err, failure) {
+		t.Errorf("Expected place error, got %v", err)
+	}
+	if cart := repo.Get(ctx, 7); len(cart.Items) != 1 {
+		t.Errorf("Expected cart to be kept after a failed checkout, got %+v", cart.Items)
+	}
+
+	var placed *models.Cart
+	if err := repo.Checkout(ctx, 7, func(cart *models.Cart) error { placed = cart; return nil }); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if placed == nil || placed.Total.Decimal() != "20.00" {
+		t.Errorf("Expected place to receive the cart, got %+v", placed)
+	}
+	if cart := repo.Get(ctx, 7); len(cart.Items) != 0 {
+		t.Errorf("Expected cart to be cleared, got %+v", cart.Items)
+	}
+}
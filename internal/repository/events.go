@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"time"
+
+	"echo-playground/pkg/models"
+)
+
+// ChangeType identifica o tipo de alteração de um registro
+type ChangeType string
+
+// Tipos de alteração publicados pelos repositórios
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// ProductChange é publicado no barramento de eventos a cada alteração do
+// catálogo, inclusive de estoque. Product é o estado após a alteração ou, na remoção, o último
+// estado do produto.
+type ProductChange struct {
+	Type    ChangeType
+	Product models.Product
+	At      time.Time
+}
+
+// UserChange é publicado no barramento de eventos a cada alteração de um
+// usuário. User é o estado após a alteração ou, na remoção, o último estado,
+// sem o token de verificação e o hash da senha.
+type UserChange struct {
+	Type ChangeType
+	User models.User
+	At   time.Time
+}
+
+// newUserChange cria o evento a partir do usuário armazenado
+func newUserChange(t ChangeType, u *models.User) *UserChange {
+	user := *u
+	user.VerificationToken = ""
+	user.PasswordHash = ""
+	return &UserChange{Type: t, User: user, At: time.Now()}
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/models"
+)
+
+// ErrQuotaExceeded indica que o arquivo ultrapassaria a cota do usuário
+var ErrQuotaExceeded = errors.New("cota de armazenamento excedida")
+
+// FileRepository é o catálogo em memória dos arquivos enviados
+type FileRepository struct {
+	mu    sync.Mutex
+	files map[string]*models.File
+}
+
+// NewFileRepository cria um catálogo de arquivos vazio
+func NewFileRepository() *FileRepository {
+	return &FileRepository{files: make(map[string]*models.File)}
+}
+
+// Create registra os metadados de um arquivo
+func (r *FileRepository) Create(ctx context.Context, file *models.File) (*models.File, error) {
+	return r.CreateWithinQuota(ctx, file, 0)
+}
+
+// CreateWithinQuota registra o arquivo somente se o total enviado pelo
+// usuário, somado ao novo arquivo, couber em quota bytes. Os arquivos
+// anônimos (UploaderID 0) dividem a mesma cota. Cota zero não tem limite.
+func (r *FileRepository) CreateWithinQuota(ctx context.Context, file *models.File, quota int64) (*models.File, error) {
+	_, span := startSpan(ctx, "files", "CreateWithinQuota")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.files[file.ID]; exists {
+		return nil, ErrDuplicate
+	}
+	if quota > 0 {
+		if used, _ := r.usage(file.UploaderID); used+file.Size > quota {
+			return nil, ErrQuotaExceeded
+		}
+	}
+
+	stored := *file
+	r.files[stored.ID] = &stored
+
+	cp := stored
+	return &cp, nil
+}
+
+// Get retorna os metadados de um arquivo pelo ID
+func (r *FileRepository) Get(ctx context.Context, id string) (*models.File, error) {
+	_, span := startSpan(ctx, "files", "Get")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *file
+	return &cp, nil
+}
+
+// FileFilter restringe a listagem de arquivos. UploaderID zero lista os
+// arquivos de todos os usuários e Status vazio, em qualquer situação.
+type FileFilter struct {
+	UploaderID int
+	Status     string
+}
+
+// List retorna uma página de arquivos, dos mais recentes aos mais antigos,
+// e o total que atende ao filtro
+func (r *FileRepository) List(ctx context.Context, filter FileFilter, page, perPage int) ([]*models.File, int) {
+	_, span := startSpan(ctx, "files", "List")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := []*models.File{}
+	for _, file := range r.files {
+		if filter.UploaderID != 0 && file.UploaderID != filter.UploaderID {
+			continue
+		}
+		if filter.Status != "" && file.Status != filter.Status {
+			continue
+		}
+		cp := *file
+		matched = append(matched, &cp)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].UploadedAt.Equal(matched[j].UploadedAt) {
+			return matched[i].ID < matched[j].ID
+		}
+		return matched[i].UploadedAt.After(matched[j].UploadedAt)
+	})
+
+	return paginate(matched, page, perPage), len(matched)
+}
+
+// UpdateScan registra o resultado da verificação de malware de um arquivo
+func (r *FileRepository) UpdateScan(ctx context.Context, id, status, threat string, scannedAt *time.Time) (*models.File, error) {
+	_, span := startSpan(ctx, "files", "UpdateScan")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	file.Status = status
+	file.Threat = threat
+	file.ScannedAt = scannedAt
+
+	cp := *file
+	return &cp, nil
+}
+
+// Usage retorna o total de bytes e de arquivos enviados pelo usuário
+func (r *FileRepository) Usage(ctx context.Context, uploaderID int) (int64, int) {
+	_, span := startSpan(ctx, "files", "Usage")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.usage(uploaderID)
+}
+
+// usage soma os arquivos do usuário; deve ser chamado com r.mu travado
+func (r *FileRepository) usage(uploaderID int) (int64, int) {
+	var bytes int64
+	count := 0
+	for _, file := range r.files {
+		if file.UploaderID == uploaderID {
+			bytes += file.Size
+			count++
+		}
+	}
+	return bytes, count
+}
+
+// References conta os registros que apontam para o conteúdo com o SHA-256
+// informado
+func (r *FileRepository) References(ctx context.Context, sha256 string) int {
+	_, span := startSpan(ctx, "files", "References")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := 0
+	for _, file := range r.files {
+		if file.SHA256 == sha256 {
+			count++
+		}
+	}
+	return count
+}
+
+// Delete remove o registro e retorna os metadados removidos
+func (r *FileRepository) Delete(ctx context.Context, id string) (*models.File, error) {
+	_, span := startSpan(ctx, "files", "Delete")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(r.files, id)
+	return file, nil
+}
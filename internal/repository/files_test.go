@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"echo-playground/pkg/models"
+)
+
+func TestFileRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewFileRepository()
+
+	now := time.Now()
+	for i, f := range []*models.File{
+		{ID: "a", UploaderID: 7, UploadedAt: now.Add(-2 * time.Minute)},
+		{ID: "b", UploaderID: 8, UploadedAt: now.Add(-time.Minute)},
+		{ID: "c", UploaderID: 7, UploadedAt: now},
+	} {
+		if _, err := repo.Create(ctx, f); err != nil {
+			t.Fatalf("File %d: expected no error, got %v", i, err)
+		}
+	}
+
+	if _, err := repo.Create(ctx, &models.File{ID: "a"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate, got %v", err)
+	}
+
+	files, total := repo.List(ctx, FileFilter{UploaderID: 7}, 1, 10)
+	if total != 2 || files[0].ID != "c" || files[1].ID != "a" {
+		t.Errorf("Expected newest files of uploader 7, got %+v", files)
+	}
+
+	if _, err := repo.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.Get(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFileRepository_QuotaAndReferences(t *testing.T) {
+	ctx := context.Background()
+	repo := NewFileRepository()
+
+	if _, err := repo.CreateWithinQuota(ctx, &models.File{ID: "a", UploaderID: 7, Size: 6, SHA256: "x"}, 10); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.CreateWithinQuota(ctx, &models.File{ID: "b", UploaderID: 7, Size: 5, SHA256: "x"}, 10); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	if _, err := repo.CreateWithinQuota(ctx, &models.File{ID: "c", Size: 4, SHA256: "x"}, 10); err != nil {
+		t.Errorf("Expected anonymous file within quota, got %v", err)
+	}
+	if _, err := repo.CreateWithinQuota(ctx, &models.File{ID: "d", Size: 7, SHA256: "x"}, 10); !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("Expected anonymous files to share a quota, got %v", err)
+	}
+
+	if used, count := repo.Usage(ctx, 7); used != 6 || count != 1 {
+		t.Errorf("Expected 6 bytes in 1 file, got %d in %d", used, count)
+	}
+	if refs := repo.References(ctx, "x"); refs != 2 {
+		t.Errorf("Expected 2 references, got %d", refs)
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/models"
+)
+
+// ErrInvalidTransition indica uma mudança de estado não permitida
+var ErrInvalidTransition = errors.New("transição de estado inválida")
+
+// OrderRepository armazena os pedidos em memória
+type OrderRepository struct {
+	mu     sync.Mutex
+	orders map[int]*models.Order
+	nextID int
+}
+
+// NewOrderRepository cria um repositório de pedidos vazio
+func NewOrderRepository() *OrderRepository {
+	return &OrderRepository{orders: make(map[int]*models.Order), nextID: 1}
+}
+
+// Create armazena um novo pedido e define seu ID
+func (r *OrderRepository) Create(ctx context.Context, order *models.Order) *models.Order {
+	_, span := startSpan(ctx, "orders", "Create")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := copyOrder(order)
+	stored.ID = r.nextID
+	r.nextID++
+	r.orders[stored.ID] = stored
+
+	return copyOrder(stored)
+}
+
+// Get retorna um pedido pelo ID
+func (r *OrderRepository) Get(ctx context.Context, id int) (*models.Order, error) {
+	_, span := startSpan(ctx, "orders", "Get")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyOrder(order), nil
+}
+
+// ListByUser retorna os pedidos de um usuário, do mais recente ao mais antigo
+func (r *OrderRepository) ListByUser(ctx context.Context, userID int) []*models.Order {
+	_, span := startSpan(ctx, "orders", "ListByUser")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	orders := []*models.Order{}
+	for _, order := range r.orders {
+		if order.UserID == userID {
+			orders = append(orders, copyOrder(order))
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID > orders[j].ID })
+	return orders
+}
+
+// UpdateStatus move o pedido para um novo estado respeitando a máquina de estados
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id int, status models.OrderStatus) (*models.Order, error) {
+	_, span := startSpan(ctx, "orders", "UpdateStatus")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if !order.Status.CanTransitionTo(status) {
+		return nil, ErrInvalidTransition
+	}
+
+	order.Status = status
+	order.UpdatedAt = time.Now()
+	return copyOrder(order), nil
+}
+
+// copyOrder cria uma cópia independente do pedido
+func copyOrder(order *models.Order) *models.Order {
+	cp := *order
+	cp.Items = make([]models.CartItem, len(order.Items))
+	copy(cp.Items, order.Items)
+	return &cp
+}
@@ -0,0 +1,558 @@
+// Package repository contém os repositórios em memória usados pela aplicação.
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+	"echo-playground/pkg/utils"
+)
+
+// Erros retornados pelos repositórios
+var (
+	ErrNotFound          = errors.New("registro não encontrado")
+	ErrInsufficientStock = errors.New("estoque insuficiente")
+	ErrInvalidQuantity   = errors.New("quantidade inválida")
+	ErrInvalidReason     = errors.New("motivo de movimentação inválido")
+	ErrReservationClosed = errors.New("reserva não está ativa")
+	ErrPriceChanged      = errors.New("preço do produto foi alterado")
+)
+
+// PriceChangedError informa o preço atual de um produto cujo valor mudou
+type PriceChangedError struct {
+	ProductID int
+	Expected  money.Money
+	Current   money.Money
+}
+
+func (e *PriceChangedError) Error() string {
+	return fmt.Sprintf("produto %d: preço esperado %s, atual %s", e.ProductID, e.Expected, e.Current)
+}
+
+func (e *PriceChangedError) Unwrap() error {
+	return ErrPriceChanged
+}
+
+// StockShortageError informa qual produto não possui estoque suficiente
+type StockShortageError struct {
+	ProductID int
+	Requested int
+	Available int
+}
+
+func (e *StockShortageError) Error() string {
+	return fmt.Sprintf("produto %d: solicitado %d, disponível %d", e.ProductID, e.Requested, e.Available)
+}
+
+func (e *StockShortageError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// ProductRepository armazena produtos, estoque e reservas em memória.
+// Todas as operações de estoque acontecem sob o mesmo lock, garantindo
+// que reservas simultâneas nunca vendam mais do que o disponível.
+type ProductRepository struct {
+	mu           sync.Mutex
+	products     map[int]*models.Product
+	nextID       int
+	movements    map[int][]models.StockMovement
+	reservations map[string]*models.Reservation
+	events       *eventbus.Bus
+	now          func() time.Time
+}
+
+// NewProductRepository cria um repositório com os produtos informados
+func NewProductRepository(products ...*models.Product) *ProductRepository {
+	r := &ProductRepository{
+		products:     make(map[int]*models.Product),
+		nextID:       1,
+		movements:    make(map[int][]models.StockMovement),
+		reservations: make(map[string]*models.Reservation),
+		now:          time.Now,
+	}
+	for _, p := range products {
+		_, _ = r.Create(context.Background(), p)
+	}
+	return r
+}
+
+// SetEventBus faz o repositório publicar um ProductChange a cada produto
+// criado, alterado (inclusive no estoque) ou removido. Deve ser chamado antes de o repositório
+// entrar em uso.
+func (r *ProductRepository) SetEventBus(bus *eventbus.Bus) {
+	r.events = bus
+}
+
+// publish publica as alterações não nulas; é chamado depois de liberar o
+// lock, para que os assinantes possam consultar o repositório
+func (r *ProductRepository) publish(ctx context.Context, changes ...*ProductChange) {
+	for _, change := range changes {
+		if change != nil {
+			eventbus.Publish(ctx, r.events, *change)
+		}
+	}
+}
+
+// List retorna todos os produtos ordenados por ID
+func (r *ProductRepository) List(ctx context.Context) []*models.Product {
+	_, span := startSpan(ctx, "products", "List")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	products := make([]*models.Product, 0, len(r.products))
+	for _, p := range r.products {
+		cp := *p
+		products = append(products, &cp)
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products
+}
+
+// Get retorna um produto pelo ID
+func (r *ProductRepository) Get(ctx context.Context, id int) (*models.Product, error) {
+	_, span := startSpan(ctx, "products", "Get")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *p
+	return &cp, nil
+}
+
+// Create armazena um novo produto, registrando o estoque inicial
+func (r *ProductRepository) Create(ctx context.Context, p *models.Product) (*models.Product, error) {
+	ctx, span := startSpan(ctx, "products", "Create")
+	defer span.End()
+
+	if p.Stock < 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	var change *ProductChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *p
+	stored.SetID(r.nextID)
+	r.nextID++
+	r.products[stored.ID] = &stored
+
+	if stored.Stock > 0 {
+		r.recordLocked(stored.ID, stored.Stock, models.StockReasonInitial, "", stored.Stock)
+	}
+	change = &ProductChange{Type: ChangeCreated, Product: stored, At: r.now()}
+
+	cp := stored
+	return &cp, nil
+}
+
+// Update altera os dados de um produto. O estoque só muda por ajustes.
+func (r *ProductRepository) Update(ctx context.Context, id int, p *models.Product) (*models.Product, error) {
+	ctx, span := startSpan(ctx, "products", "Update")
+	defer span.End()
+
+	var change *ProductChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	stored.Name = p.Name
+	stored.Price = p.Price
+	stored.Description = p.Description
+	stored.Category = p.Category
+	change = &ProductChange{Type: ChangeUpdated, Product: *stored, At: r.now()}
+
+	cp := *stored
+	return &cp, nil
+}
+
+// Delete remove um produto e libera suas reservas
+func (r *ProductRepository) Delete(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "products", "Delete")
+	defer span.End()
+
+	var change *ProductChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.products[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	delete(r.products, id)
+	delete(r.movements, id)
+	for _, res := range r.reservations {
+		if res.ProductID == id && res.Status == models.ReservationActive {
+			res.Status = models.ReservationReleased
+		}
+	}
+	change = &ProductChange{Type: ChangeDeleted, Product: *stored, At: r.now()}
+	return nil
+}
+
+// Stock retorna a situação do estoque de um produto
+func (r *ProductRepository) Stock(ctx context.Context, id int) (models.StockLevel, error) {
+	_, span := startSpan(ctx, "products", "Stock")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return models.StockLevel{}, ErrNotFound
+	}
+	return r.levelLocked(p), nil
+}
+
+// AdjustStock soma delta ao estoque de um produto com um motivo.
+// O ajuste é recusado se deixar o disponível abaixo das reservas ativas.
+func (r *ProductRepository) AdjustStock(ctx context.Context, id, delta int, reason models.StockReason, note string) (models.StockMovement, error) {
+	ctx, span := startSpan(ctx, "products", "AdjustStock")
+	defer span.End()
+
+	if delta == 0 {
+		return models.StockMovement{}, ErrInvalidQuantity
+	}
+	if !reason.Valid() || reason == models.StockReasonInitial {
+		return models.StockMovement{}, ErrInvalidReason
+	}
+
+	var change *ProductChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.products[id]
+	if !ok {
+		return models.StockMovement{}, ErrNotFound
+	}
+
+	if r.levelLocked(p).Available+delta < 0 {
+		return models.StockMovement{}, ErrInsufficientStock
+	}
+
+	p.Stock += delta
+	change = &ProductChange{Type: ChangeUpdated, Product: *p, At: r.now()}
+	return r.recordLocked(id, delta, reason, note, p.Stock), nil
+}
+
+// Movements retorna o histórico de movimentações de um produto
+func (r *ProductRepository) Movements(ctx context.Context, id int) ([]models.StockMovement, error) {
+	_, span := startSpan(ctx, "products", "Movements")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[id]; !ok {
+		return nil, ErrNotFound
+	}
+
+	movements := make([]models.StockMovement, len(r.movements[id]))
+	copy(movements, r.movements[id])
+	return movements, nil
+}
+
+// Reserve bloqueia uma quantidade do estoque disponível por ttl
+func (r *ProductRepository) Reserve(ctx context.Context, productID, userID, quantity int, ttl time.Duration) (*models.Reservation, error) {
+	_, span := startSpan(ctx, "products", "Reserve")
+	defer span.End()
+
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.products[productID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if r.levelLocked(p).Available < quantity {
+		return nil, ErrInsufficientStock
+	}
+
+	now := r.now()
+	res := &models.Reservation{
+		ID:        utils.NewID(),
+		ProductID: productID,
+		UserID:    userID,
+		Quantity:  quantity,
+		Status:    models.ReservationActive,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	r.reservations[res.ID] = res
+
+	cp := *res
+	return &cp, nil
+}
+
+// GetReservation retorna uma reserva pelo ID
+func (r *ProductRepository) GetReservation(ctx context.Context, id string) (*models.Reservation, error) {
+	_, span := startSpan(ctx, "products", "GetReservation")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, ok := r.reservations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	r.expireLocked(res, r.now())
+
+	cp := *res
+	return &cp, nil
+}
+
+// ReleaseReservation devolve ao estoque disponível a quantidade reservada
+func (r *ProductRepository) ReleaseReservation(ctx context.Context, id string) error {
+	_, span := startSpan(ctx, "products", "ReleaseReservation")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res, ok := r.reservations[id]
+	if !ok {
+		return ErrNotFound
+	}
+	r.expireLocked(res, r.now())
+	if res.Status != models.ReservationActive {
+		return ErrReservationClosed
+	}
+
+	res.Status = models.ReservationReleased
+	return nil
+}
+
+// Checkout valida preços e estoque de todas as linhas e dá baixa no
+// estoque de forma atômica: ou todas as linhas são aceitas, ou nenhuma. As
+// reservas ativas do usuário contam como disponíveis para ele e são
+// convertidas em venda, das mais antigas às mais novas, até a quantidade
+// comprada; o que sobrar de uma reserva continua reservado.
+func (r *ProductRepository) Checkout(ctx context.Context, userID int, items []models.CartItem, note string) error {
+	ctx, span := startSpan(ctx, "products", "Checkout")
+	defer span.End()
+
+	var changes []*ProductChange
+	defer func() { r.publish(ctx, changes...) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	own := r.userReservationsLocked(userID)
+	requested := make(map[int]int)
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return ErrInvalidQuantity
+		}
+
+		p, ok := r.products[item.ProductID]
+		if !ok {
+			return fmt.Errorf("produto %d: %w", item.ProductID, ErrNotFound)
+		}
+		if p.Price != item.UnitPrice {
+			return &PriceChangedError{ProductID: p.ID, Expected: item.UnitPrice, Current: p.Price}
+		}
+
+		requested[p.ID] += item.Quantity
+		available := r.levelLocked(p).Available
+		for _, res := range own[p.ID] {
+			available += res.Quantity
+		}
+		if available < requested[p.ID] {
+			return &StockShortageError{ProductID: p.ID, Requested: requested[p.ID], Available: available}
+		}
+	}
+
+	for _, item := range items {
+		p := r.products[item.ProductID]
+		p.Stock -= item.Quantity
+		commitReservations(own[p.ID], item.Quantity)
+		r.recordLocked(p.ID, -item.Quantity, models.StockReasonSale, note, p.Stock)
+	}
+	changes = r.stockChangesLocked(items)
+	return nil
+}
+
+// userReservationsLocked retorna as reservas ativas do usuário por
+// produto, das mais antigas às mais novas
+func (r *ProductRepository) userReservationsLocked(userID int) map[int][]*models.Reservation {
+	now := r.now()
+	own := make(map[int][]*models.Reservation)
+	for _, res := range r.reservations {
+		if res.UserID == userID && res.Active(now) {
+			own[res.ProductID] = append(own[res.ProductID], res)
+		}
+	}
+	for _, list := range own {
+		sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	}
+	return own
+}
+
+// commitReservations converte até quantity unidades das reservas em venda.
+// Reservas cobertas por inteiro são marcadas como confirmadas; a última
+// pode ficar ativa com a quantidade restante.
+func commitReservations(reservations []*models.Reservation, quantity int) {
+	for _, res := range reservations {
+		if quantity == 0 {
+			return
+		}
+		if res.Status != models.ReservationActive {
+			continue
+		}
+		if res.Quantity <= quantity {
+			quantity -= res.Quantity
+			res.Status = models.ReservationCommitted
+			continue
+		}
+		res.Quantity -= quantity
+		quantity = 0
+	}
+}
+
+// Restock devolve ao estoque as quantidades das linhas informadas,
+// ignorando produtos que já foram removidos
+func (r *ProductRepository) Restock(ctx context.Context, items []models.CartItem, note string) {
+	ctx, span := startSpan(ctx, "products", "Restock")
+	defer span.End()
+
+	var changes []*ProductChange
+	defer func() { r.publish(ctx, changes...) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, item := range items {
+		p, ok := r.products[item.ProductID]
+		if !ok {
+			continue
+		}
+		p.Stock += item.Quantity
+		r.recordLocked(p.ID, item.Quantity, models.StockReasonReturn, note, p.Stock)
+	}
+	changes = r.stockChangesLocked(items)
+}
+
+// stockChangesLocked monta um ChangeUpdated com o estado final de cada
+// produto ainda existente nas linhas, uma vez por produto e na ordem das linhas
+func (r *ProductRepository) stockChangesLocked(items []models.CartItem) []*ProductChange {
+	now := r.now()
+	seen := make(map[int]bool)
+	var changes []*ProductChange
+	for _, item := range items {
+		p, ok := r.products[item.ProductID]
+		if !ok || seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		changes = append(changes, &ProductChange{Type: ChangeUpdated, Product: *p, At: now})
+	}
+	return changes
+}
+
+// ExpireReservations marca como expiradas as reservas vencidas e
+// remove do histórico as que já não retêm estoque há mais de retain
+func (r *ProductRepository) ExpireReservations(ctx context.Context, retain time.Duration) int {
+	_, span := startSpan(ctx, "products", "ExpireReservations")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	expired := 0
+	for id, res := range r.reservations {
+		if r.expireLocked(res, now) {
+			expired++
+		}
+		if res.Status != models.ReservationActive && now.Sub(res.ExpiresAt) > retain {
+			delete(r.reservations, id)
+		}
+	}
+	return expired
+}
+
+// StartReservationJanitor expira reservas periodicamente até ctx ser cancelado
+func (r *ProductRepository) StartReservationJanitor(ctx context.Context, interval, retain time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.ExpireReservations(ctx, retain)
+			}
+		}
+	}()
+}
+
+// expireLocked marca a reserva como expirada se o prazo já passou
+func (r *ProductRepository) expireLocked(res *models.Reservation, now time.Time) bool {
+	if res.Status == models.ReservationActive && !now.Before(res.ExpiresAt) {
+		res.Status = models.ReservationExpired
+		return true
+	}
+	return false
+}
+
+// levelLocked calcula o estoque reservado e disponível de um produto
+func (r *ProductRepository) levelLocked(p *models.Product) models.StockLevel {
+	now := r.now()
+	reserved := 0
+	for _, res := range r.reservations {
+		if res.ProductID == p.ID && res.Active(now) {
+			reserved += res.Quantity
+		}
+	}
+
+	return models.StockLevel{
+		ProductID: p.ID,
+		OnHand:    p.Stock,
+		Reserved:  reserved,
+		Available: p.Stock - reserved,
+	}
+}
+
+// recordLocked adiciona uma movimentação ao histórico do produto
+func (r *ProductRepository) recordLocked(id, delta int, reason models.StockReason, note string, balance int) models.StockMovement {
+	m := models.StockMovement{
+		ProductID: id,
+		Delta:     delta,
+		Reason:    reason,
+		Note:      note,
+		Balance:   balance,
+		CreatedAt: r.now(),
+	}
+	r.movements[id] = append(r.movements[id], m)
+	return m
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+	"echo-playground/pkg/tracing"
+	"echo-playground/pkg/tracing/tracingtest"
+)
+
+func newTestProduct(stock int) *models.Product {
+	p := models.NewProduct("Laptop", "Laptop de alta performance", "Eletrônicos", money.MustParse("2999.99", "BRL"))
+	p.Stock = stock
+	return p
+}
+
+func TestProductRepository_CRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(newTestProduct(5))
+
+	created, err := repo.Create(ctx, newTestProduct(0))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.ID != 2 {
+		t.Errorf("Expected ID 2, got %d", created.ID)
+	}
+
+	updated, err := repo.Update(ctx, created.ID, &models.Product{Name: "Mouse", Price: money.MustParse("89.99", "BRL"), Stock: 1000})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Name != "Mouse" || updated.Stock != 0 {
+		t.Errorf("Expected name Mouse with unchanged stock, got %+v", updated)
+	}
+
+	if got := len(repo.List(ctx)); got != 2 {
+		t.Errorf("Expected 2 products, got %d", got)
+	}
+
+	if err := repo.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.Get(ctx, created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestProductRepository_PublishesChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(newTestProduct(5))
+	bus := eventbus.New(eventbus.Options{})
+	repo.SetEventBus(bus)
+
+	var changes []ProductChange
+	eventbus.Subscribe(bus, "test", func(ctx context.Context, c ProductChange) error {
+		// Os assinantes rodam fora do lock e podem consultar o repositório
+		repo.List(ctx)
+		changes = append(changes, c)
+		return nil
+	})
+
+	created, _ := repo.Create(ctx, newTestProduct(0))
+	repo.Update(ctx, created.ID, &models.Product{Name: "Mouse", Price: money.MustParse("89.99", "BRL")})
+	repo.Delete(ctx, created.ID)
+	repo.Delete(ctx, created.ID)
+
+	want := []ChangeType{ChangeCreated, ChangeUpdated, ChangeDeleted}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for i, c := range changes {
+		if c.Type != want[i] || c.Product.ID != created.ID {
+			t.Errorf("Expected change %d to be %s of product %d, got %s of %d", i, want[i], created.ID, c.Type, c.Product.ID)
+		}
+	}
+	if changes[2].Product.Name != "Mouse" {
+		t.Errorf("Expected deletion to carry the last state, got %+v", changes[2].Product)
+	}
+}
+
+func TestProductRepository_PublishesStockChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(newTestProduct(5), newTestProduct(5))
+	bus := eventbus.New(eventbus.Options{})
+	repo.SetEventBus(bus)
+
+	var changes []ProductChange
+	eventbus.Subscribe(bus, "test", func(ctx context.Context, c ProductChange) error {
+		changes = append(changes, c)
+		return nil
+	})
+
+	price := money.MustParse("2999.99", "BRL")
+	repo.AdjustStock(ctx, 1, 10, models.StockReasonRestock, "")
+	repo.AdjustStock(ctx, 1, -100, models.StockReasonDamage, "")
+	items := []models.CartItem{
+		{ProductID: 1, Quantity: 2, UnitPrice: price},
+		{ProductID: 2, Quantity: 1, UnitPrice: price},
+		{ProductID: 1, Quantity: 1, UnitPrice: price},
+	}
+	if err := repo.Checkout(ctx, 7, items, ""); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	repo.Restock(ctx, items[:1], "")
+
+	want := []struct{ id, stock int }{{1, 15}, {1, 12}, {2, 4}, {1, 14}}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for i, c := range changes {
+		if c.Type != ChangeUpdated || c.Product.ID != want[i].id || c.Product.Stock != want[i].stock {
+			t.Errorf("Expected change %d to update product %d to stock %d, got %s of %d with stock %d",
+				i, want[i].id, want[i].stock, c.Type, c.Product.ID, c.Product.Stock)
+		}
+	}
+}
+
+func TestProductRepository_AdjustStock(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(newTestProduct(5))
+
+	m, err := repo.AdjustStock(ctx, 1, 10, models.StockReasonRestock, "fornecedor")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if m.Balance != 15 {
+		t.Errorf("Expected balance 15, got %d", m.Balance)
+	}
+
+	if _, err := repo.AdjustStock(ctx, 1, -16, models.StockReasonDamage, ""); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected ErrInsufficientStock, got %v", err)
+	}
+
+	if _, err := repo.AdjustStock(ctx, 1, 1, "gift", ""); !errors.Is(err, ErrInvalidReason) {
+		t.Errorf("Expected ErrInvalidReason, got %v", err)
+	}
+
+	movements, _ := repo.Movements(ctx, 1)
+	if len(movements) != 2 {
+		t.Errorf("Expected 2 movements, got %d", len(movements))
+	}
+}
+
+func TestProductRepository_ReserveNeverOversells(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(newTestProduct(10))
+
+	var wg sync.WaitGroup
+	var succeeded int32
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(user int) {
+			defer wg.Done()
+			if _, err := repo.Reserve(ctx, 1, user, 1, time.Minute); err == nil {
+				atomic.AddInt32(&succeeded, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if succeeded != 10 {
+		t.Errorf("Expected exactly 10 reservations, got %d", succeeded)
+	}
+
+	level, _ := repo.Stock(ctx, 1)
+	if level.Available != 0 || level.Reserved != 10 {
+		t.Errorf("Expected 10 reserved and 0 available, got %+v", level)
+	}
+}
+
+func TestProductRepository_ReservationExpires(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(newTestProduct(3))
+
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	res, err := repo.Reserve(ctx, 1, 7, 3, time.Minute)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := repo.Reserve(ctx, 1, 8, 1, time.Minute); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected ErrInsufficientStock, got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+
+	if expired := repo.ExpireReservations(ctx, time.Hour); expired != 1 {
+		t.Errorf("Expected 1 expired reservation, got %d", expired)
+	}
+
+	got, _ := repo.GetReservation(ctx, res.ID)
+	if got.Status != models.ReservationExpired {
+		t.Errorf("Expected status expired, got %s", got.Status)
+	}
+
+	if err := repo.ReleaseReservation(ctx, res.ID); !errors.Is(err, ErrReservationClosed) {
+		t.Errorf("Expected ErrReservationClosed, got %v", err)
+	}
+
+	if _, err := repo.Reserve(ctx, 1, 8, 3, time.Minute); err != nil {
+		t.Errorf("Expected stock to be available again, got %v", err)
+	}
+}
+
+func TestProductRepository_CheckoutCommitsReservations(t *testing.T) {
+	ctx := context.Background()
+	repo := NewProductRepository(newTestProduct(5))
+	product, _ := repo.Get(ctx, 1)
+	line := func(quantity int) []models.CartItem {
+		return []models.CartItem{{ProductID: 1, Quantity: quantity, UnitPrice: product.Price}}
+	}
+
+	first, _ := repo.Reserve(ctx, 1, 7, 2, time.Minute)
+	second, _ := repo.Reserve(ctx, 1, 7, 3, time.Minute)
+
+	// Outro usuário não compra o que está reservado
+	if err := repo.Checkout(ctx, 8, line(1), ""); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected ErrInsufficientStock for another user, got %v", err)
+	}
+
+	// O dono das reservas compra as últimas unidades
+	if err := repo.Checkout(ctx, 7, line(4), ""); err != nil {
+		t.Fatalf("Expected reserved units to be sold to their owner, got %v", err)
+	}
+	if res, _ := repo.GetReservation(ctx, first.ID); res.Status != models.ReservationCommitted {
+		t.Errorf("Expected first reservation to be committed, got %s", res.Status)
+	}
+	res, _ := repo.GetReservation(ctx, second.ID)
+	if res.Status != models.ReservationActive || res.Quantity != 1 {
+		t.Errorf("Expected 1 unit left in the second reservation, got %+v", res)
+	}
+
+	level, _ := repo.Stock(ctx, 1)
+	if level.OnHand != 1 || level.Reserved != 1 || level.Available != 0 {
+		t.Errorf("Unexpected stock level after checkout: %+v", level)
+	}
+	if err := repo.ReleaseReservation(ctx, first.ID); !errors.Is(err, ErrReservationClosed) {
+		t.Errorf("Expected ErrReservationClosed, got %v", err)
+	}
+}
+
+func TestProductRepository_Spans(t *testing.T) {
+	recorder := tracingtest.Record(t)
+	repo := NewProductRepository(newTestProduct(5))
+
+	// Chamadas fora de uma trace, como as dos workers, não geram spans
+	repo.ExpireReservations(context.Background(), time.Hour)
+	if n := len(recorder.Ended()); n != 0 {
+		t.Fatalf("Expected no spans outside a trace, got %d", n)
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "GET /products/:id")
+	repo.Get(ctx, 1)
+	parent.End()
+
+	span := tracingtest.Find(recorder, "products.Get")
+	if span == nil || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("Expected products.Get child span")
+	}
+	if tracingtest.Attr(span, "db.collection.name") != "products" || tracingtest.Attr(span, "db.operation.name") != "Get" {
+		t.Errorf("Unexpected attributes: %v", span.Attributes())
+	}
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/models"
+)
+
+// ErrDuplicate indica que o registro viola uma restrição de unicidade
+var ErrDuplicate = errors.New("registro duplicado")
+
+// reviewKey identifica a avaliação única de um usuário para um produto
+type reviewKey struct {
+	productID int
+	userID    int
+}
+
+// ReviewRepository armazena as avaliações de produtos em memória
+type ReviewRepository struct {
+	mu      sync.Mutex
+	reviews map[int]*models.Review
+	byUser  map[reviewKey]int
+	nextID  int
+}
+
+// NewReviewRepository cria um repositório de avaliações vazio
+func NewReviewRepository() *ReviewRepository {
+	return &ReviewRepository{
+		reviews: make(map[int]*models.Review),
+		byUser:  make(map[reviewKey]int),
+		nextID:  1,
+	}
+}
+
+// Create armazena uma avaliação; cada usuário avalia um produto uma única vez
+func (r *ReviewRepository) Create(ctx context.Context, review *models.Review) (*models.Review, error) {
+	_, span := startSpan(ctx, "reviews", "Create")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := reviewKey{productID: review.ProductID, userID: review.UserID}
+	if _, exists := r.byUser[key]; exists {
+		return nil, ErrDuplicate
+	}
+
+	stored := *review
+	stored.ID = r.nextID
+	r.nextID++
+	r.reviews[stored.ID] = &stored
+	r.byUser[key] = stored.ID
+
+	cp := stored
+	return &cp, nil
+}
+
+// Get retorna uma avaliação pelo ID
+func (r *ReviewRepository) Get(ctx context.Context, id int) (*models.Review, error) {
+	_, span := startSpan(ctx, "reviews", "Get")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *review
+	return &cp, nil
+}
+
+// ReviewFilter restringe a listagem de avaliações. ProductID zero lista todos
+// os produtos; Hidden nil lista avaliações visíveis e ocultas.
+type ReviewFilter struct {
+	ProductID int
+	Hidden    *bool
+}
+
+// List retorna uma página de avaliações, das mais recentes às mais antigas,
+// e o total de avaliações que atendem ao filtro
+func (r *ReviewRepository) List(ctx context.Context, filter ReviewFilter, page, perPage int) ([]*models.Review, int) {
+	_, span := startSpan(ctx, "reviews", "List")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := []*models.Review{}
+	for _, review := range r.reviews {
+		if filter.ProductID != 0 && review.ProductID != filter.ProductID {
+			continue
+		}
+		if filter.Hidden != nil && review.Hidden != *filter.Hidden {
+			continue
+		}
+		cp := *review
+		matched = append(matched, &cp)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID > matched[j].ID })
+
+	return paginate(matched, page, perPage), len(matched)
+}
+
+// SetHidden oculta ou reexibe uma avaliação com uma nota de moderação
+func (r *ReviewRepository) SetHidden(ctx context.Context, id int, hidden bool, note string) (*models.Review, error) {
+	_, span := startSpan(ctx, "reviews", "SetHidden")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	review, ok := r.reviews[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	review.Hidden = hidden
+	review.ModerationNote = note
+	review.UpdatedAt = time.Now()
+
+	cp := *review
+	return &cp, nil
+}
+
+// Summary calcula a média e a quantidade de avaliações visíveis de um produto
+func (r *ReviewRepository) Summary(ctx context.Context, productID int) models.RatingSummary {
+	return r.Summaries(ctx)[productID]
+}
+
+// Summaries calcula o resumo das avaliações visíveis de todos os produtos
+func (r *ReviewRepository) Summaries(ctx context.Context) map[int]models.RatingSummary {
+	_, span := startSpan(ctx, "reviews", "Summaries")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sums := make(map[int]int)
+	summaries := make(map[int]models.RatingSummary)
+	for _, review := range r.reviews {
+		if review.Hidden {
+			continue
+		}
+		s := summaries[review.ProductID]
+		s.Count++
+		sums[review.ProductID] += review.Rating
+		summaries[review.ProductID] = s
+	}
+
+	for id, s := range summaries {
+		s.Average = math.Round(float64(sums[id])/float64(s.Count)*100) / 100
+		summaries[id] = s
+	}
+	return summaries
+}
+
+// paginate retorna a fatia correspondente à página informada (base 1).
+// Páginas fora do intervalo, inclusive as grandes o bastante para estourar
+// o cálculo do deslocamento, retornam uma fatia vazia.
+func paginate[T any](items []T, page, perPage int) []T {
+	if page < 1 || perPage < 1 || page-1 > len(items)/perPage {
+		return []T{}
+	}
+	start := (page - 1) * perPage
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + perPage
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"echo-playground/pkg/models"
+)
+
+func TestReviewRepository_CreateUniquePerUser(t *testing.T) {
+	ctx := context.Background()
+	repo := NewReviewRepository()
+
+	if _, err := repo.Create(ctx, models.NewReview(1, 7, 5, "Ótimo")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, err := repo.Create(ctx, models.NewReview(1, 7, 1, "Mudei de ideia")); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate, got %v", err)
+	}
+
+	if _, err := repo.Create(ctx, models.NewReview(2, 7, 4, "")); err != nil {
+		t.Errorf("Expected review of another product to succeed, got %v", err)
+	}
+}
+
+func TestReviewRepository_ListAndSummary(t *testing.T) {
+	ctx := context.Background()
+	repo := NewReviewRepository()
+
+	for user, rating := range []int{5, 4, 4, 1} {
+		if _, err := repo.Create(ctx, models.NewReview(1, user+1, rating, "")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if _, err := repo.SetHidden(ctx, 4, true, "spam"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	summary := repo.Summary(ctx, 1)
+	if summary.Count != 3 || summary.Average != 4.33 {
+		t.Errorf("Expected 3 reviews averaging 4.33, got %+v", summary)
+	}
+
+	visible := false
+	reviews, total := repo.List(ctx, ReviewFilter{ProductID: 1, Hidden: &visible}, 1, 2)
+	if total != 3 {
+		t.Errorf("Expected total 3, got %d", total)
+	}
+	if len(reviews) != 2 || reviews[0].ID != 3 {
+		t.Errorf("Expected newest 2 reviews first, got %+v", reviews)
+	}
+
+	reviews, _ = repo.List(ctx, ReviewFilter{ProductID: 1, Hidden: &visible}, 3, 2)
+	if len(reviews) != 0 {
+		t.Errorf("Expected empty page, got %d reviews", len(reviews))
+	}
+}
+
+func TestPaginate_OutOfRange(t *testing.T) {
+	items := []int{1, 2, 3}
+
+	tests := []struct {
+		page, perPage int
+	}{
+		{3, 2},
+		{math.MaxInt, 100},
+		{math.MaxInt / 2, math.MaxInt / 2},
+		{0, 10},
+	}
+	for _, tt := range tests {
+		if got := paginate(items, tt.page, tt.perPage); len(got) != 0 {
+			t.Errorf("page=%d per_page=%d: expected empty page, got %v", tt.page, tt.perPage, got)
+		}
+	}
+
+	if got := paginate(items, 2, 2); len(got) != 1 || got[0] != 3 {
+		t.Errorf("Expected last item on page 2, got %v", got)
+	}
+}
+
+func TestReviewRepository_SetHiddenNotFound(t *testing.T) {
+	repo := NewReviewRepository()
+
+	if _, err := repo.SetHidden(context.Background(), 99, true, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"echo-playground/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startSpan abre o span de uma operação do repositório, nomeado pela
+// coleção e pela operação (por exemplo "products.Get"). Operações fora de
+// uma trace, como as dos workers, não geram spans.
+func startSpan(ctx context.Context, collection, operation string) (context.Context, trace.Span) {
+	return tracing.StartChild(ctx, collection+"."+operation, trace.WithAttributes(
+		attribute.String("db.system", "memory"),
+		attribute.String("db.collection.name", collection),
+		attribute.String("db.operation.name", operation),
+	))
+}
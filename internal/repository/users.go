@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/models"
+)
+
+// ErrInvalidToken indica que o token de verificação não confere
+var ErrInvalidToken = errors.New("token de verificação inválido")
+
+// UserRepository armazena os usuários em memória. Os e-mails são únicos,
+// comparados sem diferenciar maiúsculas de minúsculas.
+type UserRepository struct {
+	mu      sync.Mutex
+	users   map[int]*models.User
+	byEmail map[string]int
+	nextID  int
+	events  *eventbus.Bus
+}
+
+// NewUserRepository cria um repositório com os usuários iniciais informados
+func NewUserRepository(users ...*models.User) *UserRepository {
+	r := &UserRepository{
+		users:   make(map[int]*models.User),
+		byEmail: make(map[string]int),
+		nextID:  1,
+	}
+	for _, u := range users {
+		_, _ = r.Create(context.Background(), u)
+	}
+	return r
+}
+
+// SetEventBus faz o repositório publicar um UserChange a cada usuário
+// criado, alterado ou removido. Deve ser chamado antes de o repositório
+// entrar em uso.
+func (r *UserRepository) SetEventBus(bus *eventbus.Bus) {
+	r.events = bus
+}
+
+// publish publica a alteração, se houver, depois de liberado o lock
+func (r *UserRepository) publish(ctx context.Context, change *UserChange) {
+	if change != nil {
+		eventbus.Publish(ctx, r.events, *change)
+	}
+}
+
+// NormalizeEmail padroniza um e-mail para comparação e armazenamento
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Create armazena um novo usuário e atribui seu ID
+func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
+	ctx, span := startSpan(ctx, "users", "Create")
+	defer span.End()
+
+	var change *UserChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *user
+	stored.Email = NormalizeEmail(stored.Email)
+	if _, exists := r.byEmail[stored.Email]; exists {
+		return nil, ErrDuplicate
+	}
+
+	stored.ID = r.nextID
+	r.nextID++
+	if stored.Created == "" {
+		stored.Created = time.Now().Format(time.RFC3339)
+	}
+	r.users[stored.ID] = &stored
+	r.byEmail[stored.Email] = stored.ID
+	change = newUserChange(ChangeCreated, &stored)
+
+	cp := stored
+	return &cp, nil
+}
+
+// Get retorna um usuário pelo ID
+func (r *UserRepository) Get(ctx context.Context, id int) (*models.User, error) {
+	_, span := startSpan(ctx, "users", "Get")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *user
+	return &cp, nil
+}
+
+// GetByEmail retorna um usuário pelo e-mail
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	_, span := startSpan(ctx, "users", "GetByEmail")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, ok := r.byEmail[NormalizeEmail(email)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *r.users[id]
+	return &cp, nil
+}
+
+// List retorna uma página de usuários ordenados por ID e o total cadastrado
+func (r *UserRepository) List(ctx context.Context, page, perPage int) ([]*models.User, int) {
+	_, span := startSpan(ctx, "users", "List")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make([]*models.User, 0, len(r.users))
+	for _, u := range r.users {
+		cp := *u
+		users = append(users, &cp)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	return paginate(users, page, perPage), len(users)
+}
+
+// Update substitui os dados de um usuário existente, preservando ID e data
+// de criação. O e-mail pendente também precisa estar livre.
+func (r *UserRepository) Update(ctx context.Context, id int, user *models.User) (*models.User, error) {
+	ctx, span := startSpan(ctx, "users", "Update")
+	defer span.End()
+
+	var change *UserChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	email := NormalizeEmail(user.Email)
+	pending := NormalizeEmail(user.PendingEmail)
+	for _, e := range []string{email, pending} {
+		if owner, exists := r.byEmail[e]; e != "" && exists && owner != id {
+			return nil, ErrDuplicate
+		}
+	}
+
+	stored := *user
+	stored.ID = id
+	stored.Email = email
+	stored.PendingEmail = pending
+	stored.Created = existing.Created
+	stored.Updated = time.Now().Format(time.RFC3339)
+
+	delete(r.byEmail, existing.Email)
+	r.byEmail[email] = id
+	r.users[id] = &stored
+	change = newUserChange(ChangeUpdated, &stored)
+
+	cp := stored
+	return &cp, nil
+}
+
+// VerifyEmail confirma o e-mail do usuário se o token conferir. Com uma
+// troca pendente, o novo e-mail só passa a valer aqui, inclusive para o
+// login; se ele tiver sido cadastrado por outro usuário nesse meio tempo,
+// retorna ErrDuplicate.
+func (r *UserRepository) VerifyEmail(ctx context.Context, id int, token string) (*models.User, error) {
+	ctx, span := startSpan(ctx, "users", "VerifyEmail")
+	defer span.End()
+
+	var change *UserChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if user.EmailVerified && user.PendingEmail == "" {
+		cp := *user
+		return &cp, nil
+	}
+	if token == "" || user.VerificationToken != token {
+		return nil, ErrInvalidToken
+	}
+
+	if user.PendingEmail != "" {
+		if owner, exists := r.byEmail[user.PendingEmail]; exists && owner != id {
+			return nil, ErrDuplicate
+		}
+		delete(r.byEmail, user.Email)
+		user.Email = user.PendingEmail
+		user.PendingEmail = ""
+		r.byEmail[user.Email] = id
+	}
+	user.EmailVerified = true
+	user.VerificationToken = ""
+	user.Updated = time.Now().Format(time.RFC3339)
+	change = newUserChange(ChangeUpdated, user)
+
+	cp := *user
+	return &cp, nil
+}
+
+// Delete remove um usuário
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	ctx, span := startSpan(ctx, "users", "Delete")
+	defer span.End()
+
+	var change *UserChange
+	defer func() { r.publish(ctx, change) }()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(r.byEmail, user.Email)
+	delete(r.users, id)
+	change = newUserChange(ChangeDeleted, user)
+	return nil
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/models"
+)
+
+func TestUserRepository_UniqueEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(models.NewUser("Maria", "maria@exemplo.com", 28))
+
+	if _, err := repo.Create(ctx, models.NewUser("Outra Maria", " MARIA@exemplo.com ", 30)); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate, got %v", err)
+	}
+
+	joao, err := repo.Create(ctx, models.NewUser("João", "joao@exemplo.com", 32))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	joao.Email = "maria@exemplo.com"
+	if _, err := repo.Update(ctx, joao.ID, joao); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate on update, got %v", err)
+	}
+
+	joao.Email = "joao.santos@exemplo.com"
+	if _, err := repo.Update(ctx, joao.ID, joao); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.GetByEmail(ctx, "joao@exemplo.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected old email to be released, got %v", err)
+	}
+	if _, err := repo.Create(ctx, models.NewUser("Outro João", "joao@exemplo.com", 40)); err != nil {
+		t.Errorf("Expected old email to be reusable, got %v", err)
+	}
+}
+
+func TestUserRepository_VerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	user := models.NewUser("Maria", "maria@exemplo.com", 28)
+	user.VerificationToken = "abc"
+	repo := NewUserRepository(user)
+
+	if _, err := repo.VerifyEmail(ctx, 1, "xyz"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken, got %v", err)
+	}
+
+	verified, err := repo.VerifyEmail(ctx, 1, "abc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !verified.EmailVerified || verified.VerificationToken != "" {
+		t.Errorf("Expected verified user without token, got %+v", verified)
+	}
+}
+
+func TestUserRepository_VerifyPendingEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(
+		&models.User{Name: "Maria", Email: "maria@exemplo.com", EmailVerified: true},
+		&models.User{Name: "João", Email: "joao@exemplo.com", EmailVerified: true},
+	)
+
+	maria, _ := repo.Get(ctx, 1)
+	maria.PendingEmail = "joao@exemplo.com"
+	if _, err := repo.Update(ctx, 1, maria); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate for a taken pending email, got %v", err)
+	}
+
+	maria.PendingEmail = "Maria.Nova@exemplo.com"
+	maria.VerificationToken = "abc"
+	if _, err := repo.Update(ctx, 1, maria); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.GetByEmail(ctx, "maria@exemplo.com"); err != nil {
+		t.Errorf("Expected current email to remain until verified, got %v", err)
+	}
+
+	// Outro usuário cadastra o e-mail antes da confirmação
+	joao, _ := repo.Get(ctx, 2)
+	joao.Email = "maria.nova@exemplo.com"
+	taken, _ := repo.Update(ctx, 2, joao)
+	if _, err := repo.VerifyEmail(ctx, 1, "abc"); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate when the pending email was taken, got %v", err)
+	}
+	taken.Email = "joao@exemplo.com"
+	_, _ = repo.Update(ctx, 2, taken)
+
+	verified, err := repo.VerifyEmail(ctx, 1, "abc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if verified.Email != "maria.nova@exemplo.com" || verified.PendingEmail != "" || !verified.EmailVerified {
+		t.Errorf("Expected new email to replace the old one, got %+v", verified)
+	}
+	if _, err := repo.GetByEmail(ctx, "maria@exemplo.com"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected old email to be released, got %v", err)
+	}
+	if user, err := repo.GetByEmail(ctx, "maria.nova@exemplo.com"); err != nil || user.ID != 1 {
+		t.Errorf("Expected new email to find the user, got %v", err)
+	}
+}
+
+func TestUserRepository_PublishesChanges(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository()
+	bus := eventbus.New(eventbus.Options{})
+	repo.SetEventBus(bus)
+
+	var changes []UserChange
+	eventbus.Subscribe(bus, "test", func(ctx context.Context, c UserChange) error {
+		changes = append(changes, c)
+		return nil
+	})
+
+	user := models.NewUser("Maria", "maria@exemplo.com", 28)
+	user.VerificationToken = "abc"
+	created, _ := repo.Create(ctx, user)
+	repo.VerifyEmail(ctx, created.ID, "errado")
+	repo.VerifyEmail(ctx, created.ID, "abc")
+	repo.Delete(ctx, created.ID)
+
+	want := []ChangeType{ChangeCreated, ChangeUpdated, ChangeDeleted}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected %d changes, got %+v", len(want), changes)
+	}
+	for i, c := range changes {
+		if c.Type != want[i] || c.User.ID != created.ID {
+			t.Errorf("Expected change %d to be %s, got %s of %d", i, want[i], c.Type, c.User.ID)
+		}
+	}
+	if changes[0].User.VerificationToken != "" {
+		t.Error("Expected events not to carry the verification token")
+	}
+	if !changes[1].User.EmailVerified {
+		t.Error("Expected update event with the verified e-mail")
+	}
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/utils"
+)
+
+// WebhookRepository armazena em memória os webhooks e o registro de
+// entregas de cada um
+type WebhookRepository struct {
+	mu         sync.Mutex
+	webhooks   map[string]*models.Webhook
+	deliveries map[string]*models.WebhookDelivery
+	now        func() time.Time
+}
+
+// NewWebhookRepository cria um repositório de webhooks vazio
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{
+		webhooks:   make(map[string]*models.Webhook),
+		deliveries: make(map[string]*models.WebhookDelivery),
+		now:        time.Now,
+	}
+}
+
+// Create armazena um webhook com um novo ID
+func (r *WebhookRepository) Create(ctx context.Context, w *models.Webhook) (*models.Webhook, error) {
+	_, span := startSpan(ctx, "webhooks", "Create")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := cloneWebhook(w)
+	stored.ID = utils.NewID()
+	stored.CreatedAt = r.now()
+	stored.UpdatedAt = stored.CreatedAt
+	r.webhooks[stored.ID] = stored
+
+	return cloneWebhook(stored), nil
+}
+
+// Get retorna um webhook pelo ID
+func (r *WebhookRepository) Get(ctx context.Context, id string) (*models.Webhook, error) {
+	_, span := startSpan(ctx, "webhooks", "Get")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneWebhook(w), nil
+}
+
+// List retorna todos os webhooks, dos mais antigos aos mais recentes
+func (r *WebhookRepository) List(ctx context.Context) []*models.Webhook {
+	_, span := startSpan(ctx, "webhooks", "List")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make([]*models.Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		list = append(list, cloneWebhook(w))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].CreatedAt.Before(list[j].CreatedAt)
+	})
+	return list
+}
+
+// Subscribers retorna os webhooks ativos que assinam o evento
+func (r *WebhookRepository) Subscribers(ctx context.Context, event string) []*models.Webhook {
+	ctx, span := startSpan(ctx, "webhooks", "Subscribers")
+	defer span.End()
+
+	subscribers := []*models.Webhook{}
+	for _, w := range r.List(ctx) {
+		if w.Subscribed(event) {
+			subscribers = append(subscribers, w)
+		}
+	}
+	return subscribers
+}
+
+// Update altera a URL, os eventos e o estado de um webhook. O segredo só é
+// trocado quando w.Secret não está vazio.
+func (r *WebhookRepository) Update(ctx context.Context, id string, w *models.Webhook) (*models.Webhook, error) {
+	_, span := startSpan(ctx, "webhooks", "Update")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	stored.URL = w.URL
+	stored.Events = append([]string(nil), w.Events...)
+	stored.Active = w.Active
+	if w.Secret != "" {
+		stored.Secret = w.Secret
+	}
+	stored.UpdatedAt = r.now()
+
+	return cloneWebhook(stored), nil
+}
+
+// Delete remove um webhook e todas as suas entregas
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	_, span := startSpan(ctx, "webhooks", "Delete")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.webhooks, id)
+	for deliveryID, d := range r.deliveries {
+		if d.WebhookID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+// CreateDelivery registra uma entrega pendente, com um novo ID, para ser
+// tentada em d.NextAttemptAt (ou imediatamente, se vazio)
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, d *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	_, span := startSpan(ctx, "webhooks", "CreateDelivery")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks[d.WebhookID]; !ok {
+		return nil, ErrNotFound
+	}
+
+	stored := cloneDelivery(d)
+	stored.ID = utils.NewID()
+	stored.Status = models.WebhookDeliveryPending
+	stored.Attempts = []models.WebhookAttempt{}
+	stored.CreatedAt = r.now()
+	if stored.NextAttemptAt == nil {
+		next := stored.CreatedAt
+		stored.NextAttemptAt = &next
+	}
+	r.deliveries[stored.ID] = stored
+
+	return cloneDelivery(stored), nil
+}
+
+// GetDelivery retorna uma entrega pelo ID
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id string) (*models.WebhookDelivery, error) {
+	_, span := startSpan(ctx, "webhooks", "GetDelivery")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return cloneDelivery(d), nil
+}
+
+// WebhookDeliveryFilter restringe a listagem de entregas. Status vazio lista
+// entregas em qualquer situação.
+type WebhookDeliveryFilter struct {
+	WebhookID string
+	Status    string
+}
+
+// ListDeliveries retorna uma página de entregas, das mais recentes às mais
+// antigas, e o total que atende ao filtro
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter, page, perPage int) ([]*models.WebhookDelivery, int) {
+	_, span := startSpan(ctx, "webhooks", "ListDeliveries")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := []*models.WebhookDelivery{}
+	for _, d := range r.deliveries {
+		if filter.WebhookID != "" && d.WebhookID != filter.WebhookID {
+			continue
+		}
+		if filter.Status != "" && d.Status != filter.Status {
+			continue
+		}
+		matched = append(matched, cloneDelivery(d))
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].CreatedAt.Equal(matched[j].CreatedAt) {
+			return matched[i].ID > matched[j].ID
+		}
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	return paginate(matched, page, perPage), len(matched)
+}
+
+// DueDeliveries retorna até limit entregas aguardando tentativa até now, das
+// mais atrasadas às mais recentes
+func (r *WebhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) []*models.WebhookDelivery {
+	_, span := startSpan(ctx, "webhooks", "DueDeliveries")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := []*models.WebhookDelivery{}
+	for _, d := range r.deliveries {
+		if d.Due(now) {
+			due = append(due, cloneDelivery(d))
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextAttemptAt.Equal(*due[j].NextAttemptAt) {
+			return due[i].CreatedAt.Before(due[j].CreatedAt)
+		}
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due
+}
+
+// RecordAttempt registra uma tentativa de entrega e a nova situação. next é
+// o instante da próxima tentativa, ou nil se não haverá outra.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, id string, attempt models.WebhookAttempt, status string, next *time.Time) (*models.WebhookDelivery, error) {
+	_, span := startSpan(ctx, "webhooks", "RecordAttempt")
+	defer span.End()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d, ok := r.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	d.Attempts = append(d.Attempts, attempt)
+	d.Status = status
+	d.NextAttemptAt = next
+	if status == models.WebhookDeliveryDelivered {
+		at := attempt.At
+		d.DeliveredAt = &at
+	}
+
+	return cloneDelivery(d), nil
+}
+
+// cloneWebhook copia o webhook, sem compartilhar a lista de eventos
+func cloneWebhook(w *models.Webhook) *models.Webhook {
+	cp := *w
+	cp.Events = append([]string(nil), w.Events...)
+	return &cp
+}
+
+// cloneDelivery copia a entrega, sem compartilhar o corpo nem as tentativas
+func cloneDelivery(d *models.WebhookDelivery) *models.WebhookDelivery {
+	cp := *d
+	cp.Payload = append([]byte(nil), d.Payload...)
+	cp.Attempts = append([]models.WebhookAttempt{}, d.Attempts...)
+	if d.NextAttemptAt != nil {
+		next := *d.NextAttemptAt
+		cp.NextAttemptAt = &next
+	}
+	if d.DeliveredAt != nil {
+		delivered := *d.DeliveredAt
+		cp.DeliveredAt = &delivered
+	}
+	if d.TraceContext != nil {
+		cp.TraceContext = make(map[string]string, len(d.TraceContext))
+		for k, v := range d.TraceContext {
+			cp.TraceContext[k] = v
+		}
+	}
+	return &cp
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"echo-playground/pkg/models"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewWebhookRepository()
+
+	products, _ := repo.Create(ctx, &models.Webhook{URL: "http://erp/a", Events: []string{models.WebhookEventProductCreated}, Secret: "s1", Active: true})
+	all, _ := repo.Create(ctx, &models.Webhook{URL: "http://erp/b", Events: []string{models.WebhookEventAll}, Secret: "s2", Active: true})
+	repo.Create(ctx, &models.Webhook{URL: "http://erp/c", Events: []string{models.WebhookEventAll}, Active: false})
+
+	subs := repo.Subscribers(ctx, models.WebhookEventProductCreated)
+	if len(subs) != 2 || subs[0].ID != products.ID || subs[1].ID != all.ID {
+		t.Errorf("Expected the two active subscribers, got %+v", subs)
+	}
+	if subs := repo.Subscribers(ctx, models.WebhookEventUserDeleted); len(subs) != 1 || subs[0].ID != all.ID {
+		t.Errorf("Expected only the wildcard subscriber, got %+v", subs)
+	}
+
+	updated, err := repo.Update(ctx, products.ID, &models.Webhook{URL: "http://erp/a2", Events: []string{models.WebhookEventProductDeleted}, Active: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.URL != "http://erp/a2" || updated.Secret != "s1" {
+		t.Errorf("Expected new URL and the same secret, got %+v", updated)
+	}
+
+	delivery, err := repo.CreateDelivery(ctx, &models.WebhookDelivery{WebhookID: products.ID, Event: "ping", Payload: []byte(`{}`)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.CreateDelivery(ctx, &models.WebhookDelivery{WebhookID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for unknown webhook, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, products.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.GetDelivery(ctx, delivery.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected deliveries to be removed with the webhook, got %v", err)
+	}
+}
+
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	ctx := context.Background()
+	repo := NewWebhookRepository()
+	w, _ := repo.Create(ctx, &models.Webhook{URL: "http://erp", Events: []string{models.WebhookEventAll}, Active: true})
+
+	first, _ := repo.CreateDelivery(ctx, &models.WebhookDelivery{WebhookID: w.ID, Event: "a"})
+	now := time.Now()
+	later := now.Add(time.Minute)
+	second, _ := repo.CreateDelivery(ctx, &models.WebhookDelivery{WebhookID: w.ID, Event: "b", NextAttemptAt: &later})
+
+	if first.Status != models.WebhookDeliveryPending || first.NextAttemptAt == nil {
+		t.Errorf("Expected pending delivery due now, got %+v", first)
+	}
+	if due := repo.DueDeliveries(ctx, now, 10); len(due) != 1 || due[0].ID != first.ID {
+		t.Errorf("Expected only the first delivery to be due, got %+v", due)
+	}
+
+	attempt := models.WebhookAttempt{At: now, StatusCode: 200}
+	delivered, err := repo.RecordAttempt(ctx, first.ID, attempt, models.WebhookDeliveryDelivered, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(delivered.Attempts) != 1 || delivered.DeliveredAt == nil || delivered.NextAttemptAt != nil {
+		t.Errorf("Expected delivered state, got %+v", delivered)
+	}
+	if due := repo.DueDeliveries(ctx, later, 10); len(due) != 1 || due[0].ID != second.ID {
+		t.Errorf("Expected only the second delivery to be due, got %+v", due)
+	}
+
+	list, total := repo.ListDeliveries(ctx, WebhookDeliveryFilter{WebhookID: w.ID, Status: models.WebhookDeliveryDelivered}, 1, 10)
+	if total != 1 || list[0].ID != first.ID {
+		t.Errorf("Expected the delivered entry, got %+v", list)
+	}
+	if _, total := repo.ListDeliveries(ctx, WebhookDeliveryFilter{WebhookID: w.ID}, 1, 10); total != 2 {
+		t.Errorf("Expected 2 deliveries, got %d", total)
+	}
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MaxReviewLength é o tamanho máximo do texto de uma avaliação
+const MaxReviewLength = 2000
+
+// ReviewHandlers contém os handlers de avaliações de produtos
+type ReviewHandlers struct {
+	products *repository.ProductRepository
+	reviews  *repository.ReviewRepository
+}
+
+// NewReviewHandlers cria uma nova instância de handlers de avaliações
+func NewReviewHandlers(products *repository.ProductRepository, reviews *repository.ReviewRepository) *ReviewHandlers {
+	return &ReviewHandlers{products: products, reviews: reviews}
+}
+
+// ReviewRequest representa uma nova avaliação
+type ReviewRequest struct {
+	Rating int    `json:"rating"`
+	Text   string `json:"text"`
+}
+
+// ModerationRequest representa uma ação de moderação
+type ModerationRequest struct {
+	Note string `json:"note"`
+}
+
+// CreateReviewHandler registra a avaliação do usuário autenticado para um produto
+func (h *ReviewHandlers) CreateReviewHandler(c echo.Context) error {
+	productID, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	req := new(ReviewRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar avaliação", err.Error()))
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.products.Get(ctx, productID); err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	userID, _ := middleware.UserID(c)
+	review := models.NewReview(productID, userID, req.Rating, strings.TrimSpace(req.Text))
+	if !review.ValidRating() {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("A nota deve estar entre 1 e 5", ""))
+	}
+	if len(review.Text) > MaxReviewLength {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Texto da avaliação muito longo", ""))
+	}
+
+	created, err := h.reviews.Create(ctx, review)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Você já avaliou este produto", err.Error()))
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, api.NewSuccessResponse("Avaliação registrada com sucesso", created))
+}
+
+// ListReviewsHandler lista as avaliações visíveis de um produto, paginadas
+func (h *ReviewHandlers) ListReviewsHandler(c echo.Context) error {
+	productID, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de produto inválido", err.Error()))
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.products.Get(ctx, productID); err != nil {
+		return repositoryError(c, err, "Produto não encontrado")
+	}
+
+	visible := false
+	page, perPage := paginationParams(c)
+	reviews, total := h.reviews.List(ctx, repository.ReviewFilter{ProductID: productID, Hidden: &visible}, page, perPage)
+
+	return c.JSON(http.StatusOK, api.NewPaginatedResponse("Avaliações listadas com sucesso", reviews, api.NewPagination(page, perPage, total)))
+}
+
+// ListModerationReviewsHandler lista avaliações de todos os produtos para
+// moderação; ?hidden=true|false filtra pelo estado
+func (h *ReviewHandlers) ListModerationReviewsHandler(c echo.Context) error {
+	filter := repository.ReviewFilter{}
+	if value := c.QueryParam("hidden"); value != "" {
+		hidden, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Parâmetro hidden inválido", err.Error()))
+		}
+		filter.Hidden = &hidden
+	}
+	if value := c.QueryParam("product_id"); value != "" {
+		productID, err := strconv.Atoi(value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Parâmetro product_id inválido", err.Error()))
+		}
+		filter.ProductID = productID
+	}
+
+	page, perPage := paginationParams(c)
+	reviews, total := h.reviews.List(c.Request().Context(), filter, page, perPage)
+
+	return c.JSON(http.StatusOK, api.NewPaginatedResponse("Avaliações listadas com sucesso", reviews, api.NewPagination(page, perPage, total)))
+}
+
+// HideReviewHandler oculta uma avaliação da listagem pública
+func (h *ReviewHandlers) HideReviewHandler(c echo.Context) error {
+	return h.setHidden(c, true, "Avaliação ocultada com sucesso")
+}
+
+// UnhideReviewHandler volta a exibir uma avaliação ocultada
+func (h *ReviewHandlers) UnhideReviewHandler(c echo.Context) error {
+	return h.setHidden(c, false, "Avaliação reexibida com sucesso")
+}
+
+// setHidden aplica a ação de moderação à avaliação da rota
+func (h *ReviewHandlers) setHidden(c echo.Context, hidden bool, message string) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de avaliação inválido", err.Error()))
+	}
+
+	req := new(ModerationRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar moderação", err.Error()))
+	}
+
+	review, err := h.reviews.SetHidden(c.Request().Context(), id, hidden, req.Note)
+	if err != nil {
+		return repositoryError(c, err, "Avaliação não encontrada")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse(message, review))
+}
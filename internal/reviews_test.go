@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/money"
+)
+
+func newTestReviewHandlers() (*ReviewHandlers, *repository.ReviewRepository) {
+	products := repository.NewProductRepository(
+		models.NewProduct("Mouse", "Mouse sem fio", "Acessórios", money.MustParse("89.99", "BRL")),
+	)
+	reviews := repository.NewReviewRepository()
+	return NewReviewHandlers(products, reviews), reviews
+}
+
+func postReview(h *ReviewHandlers, productID string, userID int, body string) *httptest.ResponseRecorder {
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/products/"+productID+"/reviews", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(productID)
+	c.Set(middleware.ContextKeyUserID, userID)
+
+	_ = h.CreateReviewHandler(c)
+	return rec
+}
+
+func TestReviewHandlers_Create(t *testing.T) {
+	h, _ := newTestReviewHandlers()
+
+	if rec := postReview(h, "1", 7, `{"rating":5,"text":"Excelente"}`); rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := postReview(h, "1", 7, `{"rating":4}`); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for duplicate review, got %d", rec.Code)
+	}
+
+	if rec := postReview(h, "1", 8, `{"rating":6}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid rating, got %d", rec.Code)
+	}
+
+	if rec := postReview(h, "99", 8, `{"rating":3}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown product, got %d", rec.Code)
+	}
+}
+
+func TestReviewHandlers_ListHidesModerated(t *testing.T) {
+	h, reviews := newTestReviewHandlers()
+	postReview(h, "1", 7, `{"rating":5}`)
+	postReview(h, "1", 8, `{"rating":1,"text":"spam"}`)
+
+	if _, err := reviews.SetHidden(context.Background(), 2, true, "spam"); err != nil {
+		t.Fatalf("Failed to hide review: %v", err)
+	}
+
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodGet, "/products/1/reviews?page=1&per_page=10", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.ListReviewsHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response struct {
+		Data       []models.Review `json:"data"`
+		Pagination api.Pagination  `json:"pagination"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response.Data) != 1 || response.Data[0].UserID != 7 {
+		t.Errorf("Expected only the visible review, got %+v", response.Data)
+	}
+	if response.Pagination.Total != 1 {
+		t.Errorf("Expected total 1, got %d", response.Pagination.Total)
+	}
+}
+
+func TestReviewHandlers_Hide(t *testing.T) {
+	h, reviews := newTestReviewHandlers()
+	postReview(h, "1", 7, `{"rating":2}`)
+
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/admin/reviews/1/hide", strings.NewReader(`{"note":"ofensivo"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.HideReviewHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	review, _ := reviews.Get(context.Background(), 1)
+	if !review.Hidden || review.ModerationNote != "ofensivo" {
+		t.Errorf("Expected hidden review with note, got %+v", review)
+	}
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/scanner"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrScanDisabled indica que nenhum scanner está configurado
+var ErrScanDisabled = errors.New("verificação de arquivos desativada")
+
+// scanBatchSize é a quantidade de arquivos pendentes buscada por vez
+const scanBatchSize = 50
+
+// StartScanWorker verifica em segundo plano os arquivos em quarentena,
+// logo após cada upload e também a cada intervalo, até o contexto ser
+// cancelado. Sem scanner configurado, não faz nada.
+func (s *FileService) StartScanWorker(ctx context.Context, interval time.Duration) {
+	if s.scanner == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.scanRequests:
+			case <-ticker.C:
+			}
+			s.ScanPending(ctx)
+		}
+	}()
+}
+
+// ScanPending verifica todos os arquivos em quarentena e retorna quantos
+// foram processados
+func (s *FileService) ScanPending(ctx context.Context) int {
+	if s.scanner == nil {
+		return 0
+	}
+
+	processed := 0
+	for ctx.Err() == nil {
+		pending, _ := s.files.List(ctx, repository.FileFilter{Status: models.FileStatusPending}, 1, scanBatchSize)
+		if len(pending) == 0 {
+			break
+		}
+		for _, file := range pending {
+			s.scan(ctx, file)
+			processed++
+		}
+	}
+	return processed
+}
+
+// Rescan devolve o arquivo à quarentena para uma nova verificação
+func (s *FileService) Rescan(ctx context.Context, id string) (*models.File, error) {
+	if s.scanner == nil {
+		return nil, ErrScanDisabled
+	}
+	file, err := s.files.UpdateScan(ctx, id, models.FileStatusPending, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	s.requestScan()
+	return file, nil
+}
+
+// scan verifica um arquivo e registra o resultado. Arquivos infectados são
+// marcados ou, com a política "reject", removidos.
+func (s *FileService) scan(ctx context.Context, file *models.File) {
+	verdict, err := s.scanContent(ctx, file)
+	now := time.Now()
+	switch {
+	case err != nil:
+		_, _ = s.files.UpdateScan(ctx, file.ID, models.FileStatusScanFailed, "", &now)
+	case verdict.Infected && s.rejectInfected:
+		_ = s.Delete(ctx, file.ID)
+	case verdict.Infected:
+		_, _ = s.files.UpdateScan(ctx, file.ID, models.FileStatusInfected, verdict.Threat, &now)
+	default:
+		_, _ = s.files.UpdateScan(ctx, file.ID, models.FileStatusClean, "", &now)
+	}
+}
+
+// scanContent executa o scanner sobre o conteúdo armazenado
+func (s *FileService) scanContent(ctx context.Context, file *models.File) (scanner.Verdict, error) {
+	content, _, err := s.Open(ctx, file)
+	if err != nil {
+		return scanner.Verdict{}, err
+	}
+	defer content.Close()
+
+	return s.scanner.Scan(ctx, content)
+}
+
+// requestScan acorda o worker de verificação sem bloquear
+func (s *FileService) requestScan() {
+	select {
+	case s.scanRequests <- struct{}{}:
+	default:
+	}
+}
+
+// RescanHandler devolve um arquivo à quarentena para ser verificado
+// novamente (admin)
+func (h *FileHandlers) RescanHandler(c echo.Context) error {
+	file, err := h.files.Rescan(c.Request().Context(), c.Param("id"))
+	if errors.Is(err, ErrScanDisabled) {
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Verificação de arquivos desativada", err.Error()))
+	}
+	if err != nil {
+		return repositoryError(c, err, "Arquivo não encontrado")
+	}
+
+	return c.JSON(http.StatusAccepted, api.NewSuccessResponse("Arquivo enviado para nova verificação", file))
+}
+
+// unavailableFile retorna o status e a mensagem para arquivos cujo
+// conteúdo ainda não pode ser entregue
+func unavailableFile(file *models.File) (int, string) {
+	switch file.Status {
+	case models.FileStatusInfected:
+		return http.StatusForbidden, "Arquivo bloqueado: ameaça detectada"
+	case models.FileStatusScanFailed:
+		return http.StatusConflict, "Não foi possível verificar o arquivo"
+	}
+	return http.StatusConflict, "Arquivo em verificação"
+}
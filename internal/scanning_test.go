@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/scanner"
+	"echo-playground/pkg/signing"
+	"echo-playground/pkg/storage"
+)
+
+func newScanTestHandlers(t *testing.T, onInfected string) *FileHandlers {
+	t.Helper()
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return NewFileHandlers(NewFileService(store, repository.NewFileRepository(), scanner.EICAR{}, config.UploadConfig{
+		MaxSize:      config.Kilobyte,
+		AllowedTypes: []string{"txt"},
+		Scan:         config.ScanConfig{OnInfected: onInfected},
+	}), signing.NewSigner([]byte("test")))
+}
+
+func TestFileService_ScanQuarantine(t *testing.T) {
+	h := newScanTestHandlers(t, "flag")
+	ctx := context.Background()
+
+	clean := uploadFile(t, h, "nota.txt", []byte("conteúdo comum"))
+	infected := uploadFile(t, h, "eicar.txt", []byte(scanner.EICARSignature))
+	if clean.Status != models.FileStatusPending || infected.Status != models.FileStatusPending {
+		t.Fatalf("Expected files to start in quarantine, got %s and %s", clean.Status, infected.Status)
+	}
+
+	if rec := download(h, clean.ID, nil); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 while pending, got %d", rec.Code)
+	}
+
+	if n := h.files.ScanPending(ctx); n != 2 {
+		t.Errorf("Expected 2 scanned files, got %d", n)
+	}
+
+	if rec := download(h, clean.ID, nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected clean file to be downloadable, got %d", rec.Code)
+	}
+
+	got, _ := h.files.Get(ctx, infected.ID)
+	if got.Status != models.FileStatusInfected || got.Threat != scanner.EICARThreat || got.ScannedAt == nil {
+		t.Errorf("Expected infected file to be flagged, got %+v", got)
+	}
+	if rec := download(h, infected.ID, nil); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for infected file, got %d", rec.Code)
+	}
+
+	rec, c := fileRequest(http.MethodPost, infected.ID, 1, middleware.RoleAdmin, nil)
+	_ = h.RescanHandler(c)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", rec.Code)
+	}
+	if got, _ := h.files.Get(ctx, infected.ID); got.Status != models.FileStatusPending {
+		t.Errorf("Expected file back in quarantine, got %s", got.Status)
+	}
+}
+
+func TestFileService_ScanRejectsInfected(t *testing.T) {
+	h := newScanTestHandlers(t, "reject")
+	ctx := context.Background()
+
+	infected := uploadFile(t, h, "eicar.txt", []byte("prefixo "+scanner.EICARSignature))
+	h.files.ScanPending(ctx)
+
+	if _, err := h.files.Get(ctx, infected.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected infected file to be removed, got %v", err)
+	}
+}
+
+func TestFileService_ScanDisabled(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+	file := uploadFile(t, h, "nota.txt", []byte("sem verificação"))
+
+	if file.Status != models.FileStatusUnscanned {
+		t.Errorf("Expected status unscanned, got %s", file.Status)
+	}
+	if _, err := h.files.Rescan(context.Background(), file.ID); !errors.Is(err, ErrScanDisabled) {
+		t.Errorf("Expected ErrScanDisabled, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/signing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Validade dos links assinados
+const (
+	DefaultSignedURLTTL = time.Hour
+	MaxSignedURLTTL     = 7 * 24 * time.Hour
+)
+
+// SignedURLRequest contém as opções de um link de download assinado.
+// ExpiresIn é a validade em segundos.
+type SignedURLRequest struct {
+	ExpiresIn int  `json:"expires_in"`
+	SingleUse bool `json:"single_use"`
+}
+
+// SignedURL é um link de download que dispensa autenticação
+type SignedURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	SingleUse bool      `json:"single_use"`
+}
+
+// SignURLHandler emite um link de download assinado para um arquivo do
+// usuário autenticado, que pode ser entregue a quem não tem acesso à API
+func (h *FileHandlers) SignURLHandler(c echo.Context) error {
+	file, ok := h.ownedFile(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, api.NewErrorResponse("Arquivo não encontrado", ""))
+	}
+
+	req := new(SignedURLRequest)
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(req); err != nil {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar dados do link", err.Error()))
+		}
+	}
+
+	ttl := DefaultSignedURLTTL
+	if req.ExpiresIn != 0 {
+		ttl = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if ttl <= 0 || ttl > MaxSignedURLTTL {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(
+			"expires_in deve estar entre 1 segundo e 7 dias", ""))
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	query, err := h.signer.Sign(signedResource(file.ID), expiresAt, req.SingleUse)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, api.NewSuccessResponse("Link de download criado", SignedURL{
+		URL:       c.Scheme() + "://" + c.Request().Host + file.URL + "?" + query.Encode(),
+		ExpiresAt: expiresAt.UTC(),
+		SingleUse: req.SingleUse,
+	}))
+}
+
+// downloadAccess decide se a requisição pode ler o conteúdo do arquivo: por
+// um link assinado válido, pelo usuário que o enviou ou por um
+// administrador. Arquivos anônimos continuam acessíveis pelo ID, e arquivos
+// em quarentena ou infectados não são entregues. Retorna o status e a
+// mensagem de erro, ou zero se o acesso for permitido. Links de uso único
+// não são consumidos aqui; veja consumeSignedURL.
+func (h *FileHandlers) downloadAccess(c echo.Context, file *models.File) (int, string) {
+	query := c.QueryParams()
+	signed := signing.Signed(query)
+	if userID, _ := middleware.UserID(c); !signed && file.UploaderID != 0 && file.UploaderID != userID && !middleware.IsAdmin(c) {
+		return http.StatusNotFound, "Arquivo não encontrado"
+	}
+
+	if !file.Downloadable() {
+		return unavailableFile(file)
+	}
+
+	if signed {
+		err := h.signer.Verify(signedResource(file.ID), query)
+		switch {
+		case errors.Is(err, signing.ErrExpired):
+			return http.StatusGone, "Link de download expirado"
+		case errors.Is(err, signing.ErrAlreadyUsed):
+			return http.StatusGone, "Link de download já utilizado"
+		case err != nil:
+			return http.StatusForbidden, "Assinatura do link inválida"
+		}
+	}
+	return 0, ""
+}
+
+// consumeSignedURL consome o link de uso único da requisição, se houver,
+// em qualquer GET, com ou sem Range: o link vale para uma única resposta
+// com conteúdo. Apenas HEAD e miniaturas não o consomem. A função retornada
+// devolve o link se a resposta não for 200 nem 206, como um 304, um 416 ou
+// uma falha antes do envio. Deve ser chamada depois de downloadAccess.
+func (h *FileHandlers) consumeSignedURL(c echo.Context) (func(), error) {
+	query := c.QueryParams()
+	if !signing.Signed(query) || c.Request().Method != http.MethodGet {
+		return func() {}, nil
+	}
+	if err := h.signer.Consume(query); err != nil {
+		return nil, err
+	}
+	return func() {
+		if status := c.Response().Status; status != http.StatusOK && status != http.StatusPartialContent {
+			h.signer.Release(query)
+		}
+	}, nil
+}
+
+// signedResource identifica o arquivo na assinatura. O mesmo link vale para
+// o download e para as miniaturas.
+func signedResource(id string) string {
+	return "file:" + id
+}
@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+)
+
+func uploadFileAs(t *testing.T, h *FileHandlers, userID int, filename string, content []byte) models.File {
+	t.Helper()
+	rec, c := fileRequest(http.MethodPost, "", userID, middleware.RoleUser, nil)
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	part, _ := w.CreateFormFile("file", filename)
+	_, _ = part.Write(content)
+	_ = w.Close()
+	c.Request().Body = io.NopCloser(body)
+	c.Request().Header.Set("Content-Type", w.FormDataContentType())
+	_ = h.UploadHandler(c)
+
+	var response struct {
+		Data models.File `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Failed to upload file: %d %s", rec.Code, rec.Body.String())
+	}
+	return response.Data
+}
+
+func signURL(h *FileHandlers, id string, userID int, body string) *httptest.ResponseRecorder {
+	rec, c := fileRequest(http.MethodPost, id, userID, middleware.RoleUser, nil)
+	c.Request().Body = io.NopCloser(strings.NewReader(body))
+	c.Request().ContentLength = int64(len(body))
+	c.Request().Header.Set("Content-Type", "application/json")
+	_ = h.SignURLHandler(c)
+	return rec
+}
+
+func signedDownload(h *FileHandlers, link string) *httptest.ResponseRecorder {
+	return signedRequest(h, http.MethodGet, link, nil)
+}
+
+func signedRequest(h *FileHandlers, method, link string, headers map[string]string) *httptest.ResponseRecorder {
+	u, _ := url.Parse(link)
+	id := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/api/v1/files/"), "/download")
+	rec, c := fileRequest(method, id, 0, "", headers)
+	c.Request().URL.RawQuery = u.RawQuery
+	_ = h.DownloadHandler(c)
+	return rec
+}
+
+func TestFileHandlers_SignedURL(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+	file := uploadFileAs(t, h, 7, "contrato.txt", []byte("confidencial"))
+
+	if rec := download(h, file.ID, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for anonymous download of owned file, got %d", rec.Code)
+	}
+	rec, c := fileRequest(http.MethodGet, file.ID, 7, middleware.RoleUser, nil)
+	_ = h.DownloadHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected owner to download, got %d", rec.Code)
+	}
+
+	if rec := signURL(h, file.ID, 8, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 when signing another user's file, got %d", rec.Code)
+	}
+	if rec := signURL(h, file.ID, 7, `{"expires_in": 999999999}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for excessive expiry, got %d", rec.Code)
+	}
+
+	rec = signURL(h, file.ID, 7, "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response struct {
+		Data SignedURL `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := signedDownload(h, response.Data.URL); rec.Code != http.StatusOK || rec.Body.String() != "confidencial" {
+			t.Errorf("Expected signed download to succeed, got %d", rec.Code)
+		}
+	}
+
+	tampered := strings.Replace(response.Data.URL, "expires=", "expires=1", 1)
+	if rec := signedDownload(h, tampered); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for tampered link, got %d", rec.Code)
+	}
+
+	other := uploadFileAs(t, h, 7, "outro.txt", []byte("outro"))
+	if rec := signedDownload(h, strings.Replace(response.Data.URL, file.ID, other.ID, 1)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 when reusing the signature for another file, got %d", rec.Code)
+	}
+}
+
+func TestFileHandlers_SignedURL_SingleUse(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+	file := uploadFileAs(t, h, 7, "contrato.txt", []byte("confidencial"))
+
+	rec := signURL(h, file.ID, 7, `{"expires_in": 60, "single_use": true}`)
+	var response struct {
+		Data SignedURL `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil || !response.Data.SingleUse {
+		t.Fatalf("Expected single-use link, got %s", rec.Body.String())
+	}
+
+	// HEAD e respostas condicionais não consomem o link
+	if rec := signedRequest(h, http.MethodHead, response.Data.URL, nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected HEAD to succeed, got %d", rec.Code)
+	}
+	etag := `"` + file.SHA256 + `"`
+	if rec := signedRequest(h, http.MethodGet, response.Data.URL, map[string]string{"If-None-Match": etag}); rec.Code != http.StatusNotModified {
+		t.Errorf("Expected status 304, got %d", rec.Code)
+	}
+
+	if rec := signedDownload(h, response.Data.URL); rec.Code != http.StatusOK || rec.Body.String() != "confidencial" {
+		t.Fatalf("Expected first full download to succeed, got %d", rec.Code)
+	}
+	if rec := signedDownload(h, response.Data.URL); rec.Code != http.StatusGone {
+		t.Errorf("Expected status 410 on reuse, got %d", rec.Code)
+	}
+	if rec := signedRequest(h, http.MethodGet, response.Data.URL, map[string]string{"Range": "bytes=0-4"}); rec.Code != http.StatusGone {
+		t.Errorf("Expected status 410 for range request after use, got %d", rec.Code)
+	}
+}
+
+func TestFileHandlers_SignedURL_SingleUseRange(t *testing.T) {
+	h, _ := newTestFileHandlers(t, config.Kilobyte)
+	file := uploadFileAs(t, h, 7, "contrato.txt", []byte("confidencial"))
+
+	rec := signURL(h, file.ID, 7, `{"single_use": true}`)
+	var response struct {
+		Data SignedURL `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	// Um Range fora do arquivo não entrega conteúdo e não consome o link
+	if rec := signedRequest(h, http.MethodGet, response.Data.URL, map[string]string{"Range": "bytes=100-"}); rec.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("Expected status 416, got %d", rec.Code)
+	}
+
+	rangeAll := map[string]string{"Range": "bytes=0-"}
+	if rec := signedRequest(h, http.MethodGet, response.Data.URL, rangeAll); rec.Code != http.StatusPartialContent || rec.Body.String() != "confidencial" {
+		t.Fatalf("Expected first range request to succeed, got %d", rec.Code)
+	}
+	if rec := signedRequest(h, http.MethodGet, response.Data.URL, rangeAll); rec.Code != http.StatusGone {
+		t.Errorf("Expected status 410 when reusing the link with Range, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"echo-playground/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationNDJSON é o tipo das respostas com um objeto JSON por linha
+const MIMEApplicationNDJSON = "application/x-ndjson"
+
+// Limites do StreamHandler
+const (
+	DefaultStreamCount    = 10
+	MaxStreamCount        = 1000
+	DefaultStreamInterval = 500 * time.Millisecond
+	MaxStreamInterval     = 10 * time.Second
+)
+
+// ndjsonPageSize é o número de registros lidos do repositório por vez
+const ndjsonPageSize = 100
+
+// ndjsonFlushEvery é o número de linhas enviadas entre cada flush
+const ndjsonFlushEvery = 50
+
+// StreamChunk é uma linha do StreamHandler no modo NDJSON
+type StreamChunk struct {
+	Chunk int       `json:"chunk"`
+	Total int       `json:"total"`
+	Time  time.Time `json:"time"`
+}
+
+// StreamHandlers contém os endpoints de streaming
+type StreamHandlers struct {
+	products *repository.ProductRepository
+	users    *repository.UserRepository
+}
+
+// NewStreamHandlers cria os handlers de streaming
+func NewStreamHandlers(products *repository.ProductRepository, users *repository.UserRepository) *StreamHandlers {
+	return &StreamHandlers{products: products, users: users}
+}
+
+// StreamHandler envia ?count= chunks, um a cada ?interval= (por exemplo
+// "250ms"), como texto ou, com ?format=ndjson ou Accept: application/x-ndjson,
+// como linhas JSON. O envio para assim que o cliente desconecta.
+func (h *StreamHandlers) StreamHandler(c echo.Context) error {
+	count, interval := streamParams(c)
+	ndjson := wantsNDJSON(c)
+
+	res := c.Response()
+	if ndjson {
+		res.Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	} else {
+		res.Header().Set(echo.HeaderContentType, echo.MIMETextPlainCharsetUTF8)
+	}
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	res.WriteHeader(http.StatusOK)
+	disableWriteDeadline(res)
+
+	ctx := c.Request().Context()
+	enc := json.NewEncoder(res)
+	for i := 1; i <= count; i++ {
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil
+		}
+
+		var err error
+		if ndjson {
+			err = enc.Encode(StreamChunk{Chunk: i, Total: count, Time: time.Now().UTC()})
+		} else {
+			_, err = fmt.Fprintf(res, "Chunk %d\n", i)
+		}
+		if err != nil {
+			return nil
+		}
+		res.Flush()
+	}
+
+	return nil
+}
+
+// ProductsStreamHandler envia todos os produtos em NDJSON, um por linha
+func (h *StreamHandlers) ProductsStreamHandler(c echo.Context) error {
+	stream := newNDJSONStream(c)
+	for _, p := range h.products.List(c.Request().Context()) {
+		if err := stream.Write(p); err != nil {
+			return nil
+		}
+	}
+	stream.Flush()
+	return nil
+}
+
+// UsersStreamHandler envia todos os usuários em NDJSON, um por linha
+// (somente admin). Os usuários são lidos em páginas, sem carregar o
+// cadastro inteiro em memória.
+func (h *StreamHandlers) UsersStreamHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	stream := newNDJSONStream(c)
+	for page := 1; ; page++ {
+		users, total := h.users.List(ctx, page, ndjsonPageSize)
+		for _, u := range users {
+			if err := stream.Write(u); err != nil {
+				return nil
+			}
+		}
+		if len(users) == 0 || page*ndjsonPageSize >= total {
+			break
+		}
+	}
+	stream.Flush()
+	return nil
+}
+
+// ndjsonStream escreve uma resposta NDJSON linha a linha, parando assim que
+// o cliente desconecta
+type ndjsonStream struct {
+	ctx  context.Context
+	res  *echo.Response
+	enc  *json.Encoder
+	rows int
+}
+
+// newNDJSONStream envia os headers de uma resposta NDJSON
+func newNDJSONStream(c echo.Context) *ndjsonStream {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, MIMEApplicationNDJSON)
+	res.Header().Set("X-Content-Type-Options", "nosniff")
+	res.WriteHeader(http.StatusOK)
+	disableWriteDeadline(res)
+	return &ndjsonStream{ctx: c.Request().Context(), res: res, enc: json.NewEncoder(res)}
+}
+
+// Write envia v como uma linha. Retorna erro se o cliente desconectou.
+func (s *ndjsonStream) Write(v interface{}) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.enc.Encode(v); err != nil {
+		return err
+	}
+	s.rows++
+	if s.rows%ndjsonFlushEvery == 0 {
+		s.res.Flush()
+	}
+	return nil
+}
+
+// Flush envia as linhas pendentes
+func (s *ndjsonStream) Flush() {
+	s.res.Flush()
+}
+
+// streamParams lê ?count= e ?interval=, aplicando padrões e limites
+func streamParams(c echo.Context) (count int, interval time.Duration) {
+	count, err := strconv.Atoi(c.QueryParam("count"))
+	if err != nil || count < 1 {
+		count = DefaultStreamCount
+	}
+	if count > MaxStreamCount {
+		count = MaxStreamCount
+	}
+
+	interval, err = time.ParseDuration(c.QueryParam("interval"))
+	if err != nil || interval < 0 {
+		interval = DefaultStreamInterval
+	}
+	if interval > MaxStreamInterval {
+		interval = MaxStreamInterval
+	}
+
+	return count, interval
+}
+
+// wantsNDJSON informa se o cliente pediu a resposta em NDJSON
+func wantsNDJSON(c echo.Context) bool {
+	if format := c.QueryParam("format"); format != "" {
+		return format == "ndjson"
+	}
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), MIMEApplicationNDJSON)
+}
+
+// sleepContext espera d ou até ctx ser cancelado
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// disableWriteDeadline remove o prazo de escrita do servidor para respostas
+// longas, que terminam quando o cliente desconecta
+func disableWriteDeadline(res *echo.Response) {
+	_ = http.NewResponseController(res.Writer).SetWriteDeadline(time.Time{})
+}
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newStreamTestHandlers(products, users int) *StreamHandlers {
+	productRepo := repository.NewProductRepository()
+	for i := 1; i <= products; i++ {
+		productRepo.Create(context.Background(), testProduct(fmt.Sprintf("Produto %d", i)))
+	}
+	userRepo := repository.NewUserRepository()
+	for i := 1; i <= users; i++ {
+		userRepo.Create(context.Background(), models.NewUser(fmt.Sprintf("Usuário %d", i), fmt.Sprintf("u%d@exemplo.com", i), 30))
+	}
+	return NewStreamHandlers(productRepo, userRepo)
+}
+
+func doStream(t *testing.T, handler echo.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	if err := handler(echo.New().NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return rec
+}
+
+func ndjsonLines(t *testing.T, body string) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Invalid NDJSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestStreamParams(t *testing.T) {
+	tests := []struct {
+		query    string
+		count    int
+		interval time.Duration
+	}{
+		{"", DefaultStreamCount, DefaultStreamInterval},
+		{"?count=3&interval=250ms", 3, 250 * time.Millisecond},
+		{"?count=0&interval=abc", DefaultStreamCount, DefaultStreamInterval},
+		{"?count=-1&interval=-1s", DefaultStreamCount, DefaultStreamInterval},
+		{"?count=5000&interval=1h", MaxStreamCount, MaxStreamInterval},
+		{"?interval=0s", DefaultStreamCount, 0},
+	}
+
+	for _, tt := range tests {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/stream"+tt.query, nil), httptest.NewRecorder())
+		count, interval := streamParams(c)
+		if count != tt.count || interval != tt.interval {
+			t.Errorf("%q: expected %d/%v, got %d/%v", tt.query, tt.count, tt.interval, count, interval)
+		}
+	}
+}
+
+func TestStreamHandler_Text(t *testing.T) {
+	h := newStreamTestHandlers(0, 0)
+	rec := doStream(t, h.StreamHandler, httptest.NewRequest(http.MethodGet, "/stream?count=3&interval=0s", nil))
+
+	if got := rec.Body.String(); got != "Chunk 1\nChunk 2\nChunk 3\n" {
+		t.Errorf("Expected 3 chunks, got %q", got)
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Expected text/plain, got %q", ct)
+	}
+}
+
+func TestStreamHandler_NDJSON(t *testing.T) {
+	h := newStreamTestHandlers(0, 0)
+
+	byQuery := httptest.NewRequest(http.MethodGet, "/stream?count=2&interval=0s&format=ndjson", nil)
+	byAccept := httptest.NewRequest(http.MethodGet, "/stream?count=2&interval=0s", nil)
+	byAccept.Header.Set(echo.HeaderAccept, MIMEApplicationNDJSON)
+
+	for _, req := range []*http.Request{byQuery, byAccept} {
+		rec := doStream(t, h.StreamHandler, req)
+		if ct := rec.Header().Get(echo.HeaderContentType); ct != MIMEApplicationNDJSON {
+			t.Errorf("Expected %s, got %q", MIMEApplicationNDJSON, ct)
+		}
+		lines := ndjsonLines(t, rec.Body.String())
+		if len(lines) != 2 || lines[1]["chunk"] != float64(2) || lines[1]["total"] != float64(2) {
+			t.Errorf("Expected 2 chunk lines, got %v", lines)
+		}
+	}
+}
+
+func TestStreamHandler_StopsOnCancel(t *testing.T) {
+	h := newStreamTestHandlers(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/stream?count=5&interval=10s", nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		h.StreamHandler(echo.New().NewContext(req, rec))
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected stream to stop when the request is cancelled")
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected no chunks after cancel, got %q", rec.Body.String())
+	}
+}
+
+func TestProductsStreamHandler(t *testing.T) {
+	h := newStreamTestHandlers(3, 0)
+	rec := doStream(t, h.ProductsStreamHandler, httptest.NewRequest(http.MethodGet, "/products/stream", nil))
+
+	lines := ndjsonLines(t, rec.Body.String())
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 products, got %d", len(lines))
+	}
+	if lines[0]["name"] != "Produto 1" || lines[2]["id"] != float64(3) {
+		t.Errorf("Unexpected products: %v", lines)
+	}
+}
+
+func TestUsersStreamHandler(t *testing.T) {
+	h := newStreamTestHandlers(0, 2*ndjsonPageSize+5)
+	rec := doStream(t, h.UsersStreamHandler, httptest.NewRequest(http.MethodGet, "/users/stream", nil))
+
+	lines := ndjsonLines(t, rec.Body.String())
+	if len(lines) != 2*ndjsonPageSize+5 {
+		t.Fatalf("Expected every user across pages, got %d", len(lines))
+	}
+	if lines[len(lines)-1]["id"] != float64(2*ndjsonPageSize+5) {
+		t.Errorf("Expected users in ID order, got last %v", lines[len(lines)-1])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec = doStream(t, h.UsersStreamHandler, httptest.NewRequest(http.MethodGet, "/users/stream", nil).WithContext(ctx))
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected no rows for a cancelled request, got %d bytes", rec.Body.Len())
+	}
+}
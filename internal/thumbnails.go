@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"echo-playground/pkg/imaging"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/storage"
+)
+
+// Erros ao obter miniaturas
+var (
+	ErrNoThumbnail          = errors.New("arquivo não possui miniatura")
+	ErrInvalidThumbnailSize = errors.New("tamanho de miniatura não configurado")
+)
+
+// ThumbnailSizes retorna os tamanhos de miniatura configurados, em ordem
+// crescente
+func (s *FileService) ThumbnailSizes() []int {
+	return append([]int(nil), s.thumbSizes...)
+}
+
+// Thumbnail abre a miniatura de uma imagem no tamanho informado. As
+// miniaturas são geradas no upload; se ainda não existirem (por exemplo,
+// após uma mudança de configuração), são geradas e gravadas neste momento.
+func (s *FileService) Thumbnail(ctx context.Context, file *models.File, size int) (io.ReadSeekCloser, *storage.Object, error) {
+	if !imaging.Supported(file.ContentType) {
+		return nil, nil, ErrNoThumbnail
+	}
+	if i := sort.SearchInts(s.thumbSizes, size); i == len(s.thumbSizes) || s.thumbSizes[i] != size {
+		return nil, nil, ErrInvalidThumbnailSize
+	}
+
+	key := thumbnailKey(file.SHA256, file.ContentType, size)
+	content, obj, err := s.store.Get(ctx, key)
+	if !errors.Is(err, storage.ErrNotFound) {
+		return content, obj, err
+	}
+
+	if err := s.generateThumbnail(ctx, file, size); err != nil {
+		return nil, nil, err
+	}
+	return s.store.Get(ctx, key)
+}
+
+// generateThumbnail gera uma única miniatura a partir do conteúdo original
+func (s *FileService) generateThumbnail(ctx context.Context, file *models.File, size int) error {
+	unlock := s.blobs.Lock(file.SHA256)
+	defer unlock()
+
+	content, _, err := s.Open(ctx, file)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	img, err := imaging.Decode(content)
+	if err != nil {
+		return err
+	}
+	return s.putThumbnail(ctx, file.SHA256, file.ContentType, size, imaging.Thumbnail(img, size))
+}
+
+// generateThumbnails gera todas as miniaturas configuradas. Cada tamanho é
+// reduzido a partir do anterior, do maior para o menor, para percorrer a
+// imagem original uma única vez.
+func (s *FileService) generateThumbnails(ctx context.Context, sum, contentType string, r io.ReadSeeker) error {
+	img, err := imaging.Decode(r)
+	if err != nil {
+		return err
+	}
+
+	for i := len(s.thumbSizes) - 1; i >= 0; i-- {
+		size := s.thumbSizes[i]
+		img = imaging.Thumbnail(img, size)
+		if err := s.putThumbnail(ctx, sum, contentType, size, img); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putThumbnail codifica e grava uma miniatura
+func (s *FileService) putThumbnail(ctx context.Context, sum, contentType string, size int, img image.Image) error {
+	thumbType := imaging.ThumbnailType(contentType)
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, thumbType); err != nil {
+		return err
+	}
+	_, err := s.store.Put(ctx, thumbnailKey(sum, contentType, size), &buf, int64(buf.Len()), thumbType)
+	return err
+}
+
+// deleteThumbnails remove as miniaturas de um conteúdo nos tamanhos
+// configurados
+func (s *FileService) deleteThumbnails(ctx context.Context, sum, contentType string) error {
+	if !imaging.Supported(contentType) {
+		return nil
+	}
+	for _, size := range s.thumbSizes {
+		err := s.store.Delete(ctx, thumbnailKey(sum, contentType, size))
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// thumbnailKey retorna a chave de armazenamento de uma miniatura. Como o
+// conteúdo, as miniaturas são compartilhadas por arquivos de mesmo SHA-256.
+func thumbnailKey(sum, contentType string, size int) string {
+	ext := "png"
+	if imaging.ThumbnailType(contentType) == "image/jpeg" {
+		ext = "jpg"
+	}
+	return "thumbs/" + sum[:2] + "/" + sum + "/" + strconv.Itoa(size) + "." + ext
+}
+
+// thumbnailSizes normaliza os tamanhos configurados: descarta valores não
+// positivos e repetidos e os ordena de forma crescente
+func thumbnailSizes(sizes []int) []int {
+	normalized := []int{}
+	for _, size := range sizes {
+		if size > 0 {
+			normalized = append(normalized, size)
+		}
+	}
+	sort.Ints(normalized)
+
+	unique := normalized[:0]
+	for i, size := range normalized {
+		if i == 0 || size != normalized[i-1] {
+			unique = append(unique, size)
+		}
+	}
+	return unique
+}
+
+// stripMetadata remove os metadados EXIF de imagens JPEG e PNG antes da
+// gravação. O conteúdo dessas imagens é lido em memória, limitado a
+// maxSize+1 bytes para que o limite de tamanho continue valendo.
+func stripMetadata(contentType string, r io.Reader, maxSize int64) (io.Reader, error) {
+	if !strings.HasPrefix(contentType, "image/jpeg") && !strings.HasPrefix(contentType, "image/png") {
+		return r, nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, ErrFileTooLarge
+	}
+
+	stripped, err := imaging.StripMetadata(contentType, data)
+	if err != nil {
+		return nil, ErrContentMismatch
+	}
+	return bytes.NewReader(stripped), nil
+}
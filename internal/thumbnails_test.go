@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/signing"
+	"echo-playground/pkg/storage"
+)
+
+func newThumbnailTestHandlers(t *testing.T) (*FileHandlers, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewLocal(dir)
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return NewFileHandlers(NewFileService(store, repository.NewFileRepository(), nil, config.UploadConfig{
+		MaxSize:        config.Megabyte,
+		AllowedTypes:   []string{"png", "txt"},
+		ThumbnailSizes: []int{128, 32, 128},
+	}), signing.NewSigner([]byte("test"))), dir
+}
+
+// pngWithExif gera um PNG 300x150 com um chunk eXIf logo após o IHDR
+func pngWithExif() []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 150))
+	for y := 0; y < 150; y++ {
+		for x := 0; x < 300; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	data := buf.Bytes()
+
+	exif := []byte("MM\x00\x2aGPS -23.5505")
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(exif)))
+	chunk = append(append(chunk, "eXIf"...), exif...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	ihdrEnd := 8 + 12 + 13
+	return append(append(append([]byte{}, data[:ihdrEnd]...), chunk...), data[ihdrEnd:]...)
+}
+
+func thumbnail(h *FileHandlers, id, size string) *httptest.ResponseRecorder {
+	rec, c := fileRequest(http.MethodGet, id, 0, "", nil)
+	if size != "" {
+		q := c.Request().URL.Query()
+		q.Set("size", size)
+		c.Request().URL.RawQuery = q.Encode()
+	}
+	_ = h.ThumbnailHandler(c)
+	return rec
+}
+
+func TestFileHandlers_ThumbnailHandler(t *testing.T) {
+	h, dir := newThumbnailTestHandlers(t)
+	file := uploadFile(t, h, "foto.png", pngWithExif())
+
+	stored, err := os.ReadFile(filepath.Join(dir, file.Name))
+	if err != nil {
+		t.Fatalf("Failed to read stored file: %v", err)
+	}
+	if bytes.Contains(stored, []byte("eXIf")) || bytes.Contains(stored, []byte("GPS")) {
+		t.Error("Expected EXIF metadata to be stripped from stored original")
+	}
+
+	tests := []struct {
+		size          string
+		width, height int
+	}{
+		{"", 32, 16},
+		{"128", 128, 64},
+	}
+	for _, tt := range tests {
+		rec := thumbnail(h, file.ID, tt.size)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for size %q, got %d: %s", tt.size, rec.Code, rec.Body.String())
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+			t.Errorf("Expected image/png, got %s", ct)
+		}
+		img, err := png.Decode(rec.Body)
+		if err != nil {
+			t.Fatalf("Failed to decode thumbnail: %v", err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("Expected %dx%d, got %dx%d", tt.width, tt.height, b.Dx(), b.Dy())
+		}
+	}
+
+	if rec := thumbnail(h, file.ID, "100"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unconfigured size, got %d", rec.Code)
+	}
+
+	text := uploadFile(t, h, "nota.txt", []byte("sem miniatura"))
+	if rec := thumbnail(h, text.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for non-image, got %d", rec.Code)
+	}
+}
+
+func TestFileHandlers_ThumbnailHandler_RegeneratesAndDeletes(t *testing.T) {
+	h, dir := newThumbnailTestHandlers(t)
+	file := uploadFile(t, h, "foto.png", pngWithExif())
+
+	thumbs := filepath.Join(dir, "thumbs", file.SHA256[:2], file.SHA256)
+	if err := os.RemoveAll(thumbs); err != nil {
+		t.Fatalf("Failed to remove thumbnails: %v", err)
+	}
+
+	if rec := thumbnail(h, file.ID, "128"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected missing thumbnail to be regenerated, got %d", rec.Code)
+	}
+	if _, err := os.Stat(filepath.Join(thumbs, "128.png")); err != nil {
+		t.Errorf("Expected regenerated thumbnail to be stored: %v", err)
+	}
+
+	rec, c := fileRequest(http.MethodDelete, file.ID, 1, middleware.RoleAdmin, nil)
+	_ = h.DeleteFileHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if _, err := os.Stat(filepath.Join(thumbs, "128.png")); !os.IsNotExist(err) {
+		t.Errorf("Expected thumbnails to be removed with the file, got %v", err)
+	}
+}
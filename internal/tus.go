@@ -0,0 +1,390 @@
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Cabeçalhos e valores do protocolo tus 1.0
+const (
+	TusVersion          = "1.0.0"
+	TusExtensions       = "creation,expiration,termination"
+	TusOffsetStreamType = "application/offset+octet-stream"
+
+	HeaderTusResumable   = "Tus-Resumable"
+	HeaderTusVersion     = "Tus-Version"
+	HeaderTusExtension   = "Tus-Extension"
+	HeaderTusMaxSize     = "Tus-Max-Size"
+	HeaderUploadLength   = "Upload-Length"
+	HeaderUploadOffset   = "Upload-Offset"
+	HeaderUploadMetadata = "Upload-Metadata"
+	HeaderUploadExpires  = "Upload-Expires"
+	HeaderFileID         = "X-File-ID"
+)
+
+// DefaultTusExpiration é a validade padrão de um upload incompleto
+const DefaultTusExpiration = 24 * time.Hour
+
+// tusUpload é o estado de um upload resumível
+type tusUpload struct {
+	mu         sync.Mutex
+	id         string
+	length     int64
+	offset     int64
+	filename   string
+	uploaderID int
+	expiresAt  time.Time
+	fileID     string
+}
+
+// TusHandlers implementa o protocolo tus 1.0 (núcleo e as extensões
+// creation, expiration e termination). Os blocos recebidos são acumulados
+// em um arquivo temporário e, quando o upload termina, o conteúdo segue
+// pelo mesmo FileService dos demais uploads e entra no catálogo.
+type TusHandlers struct {
+	files   *FileService
+	dir     string
+	ttl     time.Duration
+	baseURL string
+	now     func() time.Time
+
+	mu      sync.Mutex
+	uploads map[string]*tusUpload
+}
+
+// NewTusHandlers cria os handlers tus. dir guarda os uploads em andamento e
+// baseURL é o caminho da rota de criação, usado no header Location.
+func NewTusHandlers(files *FileService, dir, baseURL string, ttl time.Duration) (*TusHandlers, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		ttl = DefaultTusExpiration
+	}
+	return &TusHandlers{
+		files:   files,
+		dir:     dir,
+		ttl:     ttl,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		now:     time.Now,
+		uploads: make(map[string]*tusUpload),
+	}, nil
+}
+
+// TusResumable é o middleware que exige o header Tus-Resumable compatível
+// em todas as requisições, exceto OPTIONS
+func (h *TusHandlers) TusResumable(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(HeaderTusResumable, TusVersion)
+		if c.Request().Method == http.MethodOptions {
+			return next(c)
+		}
+		if c.Request().Header.Get(HeaderTusResumable) != TusVersion {
+			c.Response().Header().Set(HeaderTusVersion, TusVersion)
+			return c.NoContent(http.StatusPreconditionFailed)
+		}
+		return next(c)
+	}
+}
+
+// OptionsHandler informa a versão, as extensões e o tamanho máximo aceitos
+func (h *TusHandlers) OptionsHandler(c echo.Context) error {
+	header := c.Response().Header()
+	header.Set(HeaderTusVersion, TusVersion)
+	header.Set(HeaderTusExtension, TusExtensions)
+	header.Set(HeaderTusMaxSize, strconv.FormatInt(int64(h.files.MaxSize()), 10))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateHandler cria um upload a partir de Upload-Length e Upload-Metadata
+// (chave "filename" obrigatória)
+func (h *TusHandlers) CreateHandler(c echo.Context) error {
+	length, err := strconv.ParseInt(c.Request().Header.Get(HeaderUploadLength), 10, 64)
+	if err != nil || length < 0 {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Upload-Length inválido", ""))
+	}
+
+	metadata, err := parseTusMetadata(c.Request().Header.Get(HeaderUploadMetadata))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Upload-Metadata inválido", err.Error()))
+	}
+	filename := metadata["filename"]
+	if filename == "" {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Upload-Metadata deve informar filename", ""))
+	}
+	uploaderID, _ := middleware.UserID(c)
+	if err := h.files.Check(c.Request().Context(), filename, length, uploaderID); err != nil {
+		return uploadError(c, err, h.files.MaxSize())
+	}
+
+	upload := &tusUpload{
+		id:         utils.NewID(),
+		length:     length,
+		filename:   filename,
+		uploaderID: uploaderID,
+		expiresAt:  h.now().Add(h.ttl),
+	}
+
+	f, err := os.OpenFile(h.dataPath(upload.id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	f.Close()
+
+	h.mu.Lock()
+	h.uploads[upload.id] = upload
+	h.mu.Unlock()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderLocation, h.baseURL+"/"+upload.id)
+	header.Set(HeaderUploadExpires, upload.expiresAt.UTC().Format(http.TimeFormat))
+
+	if length == 0 {
+		upload.mu.Lock()
+		defer upload.mu.Unlock()
+		if err := h.finish(c.Request().Context(), upload); err != nil {
+			return uploadError(c, err, h.files.MaxSize())
+		}
+		header.Set(HeaderFileID, upload.fileID)
+	}
+	return c.NoContent(http.StatusCreated)
+}
+
+// HeadHandler informa o deslocamento atual para que o cliente retome o envio
+func (h *TusHandlers) HeadHandler(c echo.Context) error {
+	upload, status := h.lookup(c)
+	if upload == nil {
+		return c.NoContent(status)
+	}
+	upload.mu.Lock()
+	defer upload.mu.Unlock()
+
+	h.setUploadHeaders(c, upload)
+	c.Response().Header().Set(HeaderUploadLength, strconv.FormatInt(upload.length, 10))
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.NoContent(http.StatusOK)
+}
+
+// PatchHandler acrescenta um bloco a partir de Upload-Offset. Se a conexão
+// cair no meio do bloco, os bytes já recebidos são mantidos.
+func (h *TusHandlers) PatchHandler(c echo.Context) error {
+	if c.Request().Header.Get(echo.HeaderContentType) != TusOffsetStreamType {
+		return c.JSON(http.StatusUnsupportedMediaType, api.NewErrorResponse("Content-Type deve ser "+TusOffsetStreamType, ""))
+	}
+
+	upload, status := h.lookup(c)
+	if upload == nil {
+		return c.NoContent(status)
+	}
+	if !upload.mu.TryLock() {
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Upload em andamento em outra requisição", ""))
+	}
+	defer upload.mu.Unlock()
+
+	offset, err := strconv.ParseInt(c.Request().Header.Get(HeaderUploadOffset), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Upload-Offset inválido", ""))
+	}
+	if offset != upload.offset {
+		h.setUploadHeaders(c, upload)
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Upload-Offset não corresponde ao recebido", ""))
+	}
+	if upload.fileID != "" {
+		h.setUploadHeaders(c, upload)
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	remaining := upload.length - upload.offset
+	if c.Request().ContentLength > remaining {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Bloco excede o Upload-Length declarado", ""))
+	}
+
+	f, err := os.OpenFile(h.dataPath(upload.id), os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	n, copyErr := io.Copy(f, io.LimitReader(c.Request().Body, remaining))
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	upload.offset += n
+	upload.expiresAt = h.now().Add(h.ttl)
+
+	if copyErr != nil {
+		return copyErr
+	}
+
+	if upload.offset == upload.length {
+		if err := h.finish(c.Request().Context(), upload); err != nil {
+			h.discard(upload.id)
+			return uploadError(c, err, h.files.MaxSize())
+		}
+	}
+
+	h.setUploadHeaders(c, upload)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteHandler encerra o upload e descarta os dados recebidos
+func (h *TusHandlers) DeleteHandler(c echo.Context) error {
+	upload, status := h.lookup(c)
+	if upload == nil {
+		return c.NoContent(status)
+	}
+	if !upload.mu.TryLock() {
+		return c.JSON(http.StatusConflict, api.NewErrorResponse("Upload em andamento em outra requisição", ""))
+	}
+	defer upload.mu.Unlock()
+
+	h.discard(upload.id)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ExpireUploads descarta os uploads cujo prazo passou e retorna quantos
+func (h *TusHandlers) ExpireUploads() int {
+	now := h.now()
+
+	h.mu.Lock()
+	expired := []string{}
+	for id, upload := range h.uploads {
+		if upload.mu.TryLock() {
+			if !now.Before(upload.expiresAt) {
+				expired = append(expired, id)
+			}
+			upload.mu.Unlock()
+		}
+	}
+	h.mu.Unlock()
+
+	for _, id := range expired {
+		h.discard(id)
+	}
+	return len(expired)
+}
+
+// StartJanitor descarta uploads expirados periodicamente até ctx ser cancelado
+func (h *TusHandlers) StartJanitor(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				h.ExpireUploads()
+			}
+		}
+	}()
+}
+
+// lookup busca o upload da rota. Uploads expirados retornam 410 e uploads
+// de outro usuário são tratados como inexistentes.
+func (h *TusHandlers) lookup(c echo.Context) (*tusUpload, int) {
+	h.mu.Lock()
+	upload, ok := h.uploads[c.Param("id")]
+	h.mu.Unlock()
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+
+	userID, _ := middleware.UserID(c)
+	if upload.uploaderID != 0 && upload.uploaderID != userID {
+		return nil, http.StatusNotFound
+	}
+
+	upload.mu.Lock()
+	expired := upload.fileID == "" && !h.now().Before(upload.expiresAt)
+	upload.mu.Unlock()
+	if expired {
+		h.discard(upload.id)
+		return nil, http.StatusGone
+	}
+	return upload, 0
+}
+
+// finish envia o conteúdo completo ao FileService e descarta o arquivo
+// temporário. O upload permanece registrado até expirar, informando o ID
+// do arquivo criado.
+func (h *TusHandlers) finish(ctx context.Context, upload *tusUpload) error {
+	f, err := os.Open(h.dataPath(upload.id))
+	if err != nil {
+		return err
+	}
+	file, err := h.files.Ingest(ctx, upload.filename, f, upload.length, upload.uploaderID)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	upload.fileID = file.ID
+	upload.expiresAt = h.now().Add(h.ttl)
+	_ = os.Remove(h.dataPath(upload.id))
+	return nil
+}
+
+// discard remove o upload e seus dados
+func (h *TusHandlers) discard(id string) {
+	h.mu.Lock()
+	delete(h.uploads, id)
+	h.mu.Unlock()
+	_ = os.Remove(h.dataPath(id))
+}
+
+// setUploadHeaders define Upload-Offset, Upload-Expires e, se o upload já
+// terminou, o ID do arquivo no catálogo
+func (h *TusHandlers) setUploadHeaders(c echo.Context, upload *tusUpload) {
+	header := c.Response().Header()
+	header.Set(HeaderUploadOffset, strconv.FormatInt(upload.offset, 10))
+	header.Set(HeaderUploadExpires, upload.expiresAt.UTC().Format(http.TimeFormat))
+	if upload.fileID != "" {
+		header.Set(HeaderFileID, upload.fileID)
+	}
+}
+
+// dataPath retorna o caminho do arquivo temporário do upload
+func (h *TusHandlers) dataPath(id string) string {
+	return filepath.Join(h.dir, id+".part")
+}
+
+// parseTusMetadata interpreta o header Upload-Metadata: pares "chave valor"
+// separados por vírgula, com o valor em base64
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, errors.New("par de metadados malformado")
+		}
+		value := ""
+		if len(fields) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, err
+			}
+			value = string(decoded)
+		}
+		metadata[fields[0]] = value
+	}
+	return metadata, nil
+}
@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+type tusTestFixture struct {
+	e        *echo.Echo
+	handlers *TusHandlers
+	files    *FileService
+	now      time.Time
+}
+
+func newTusTestFixture(t *testing.T) *tusTestFixture {
+	t.Helper()
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	files := NewFileService(store, repository.NewFileRepository(), nil, config.UploadConfig{
+		MaxSize:      config.Kilobyte,
+		AllowedTypes: []string{"txt"},
+	})
+
+	h, err := NewTusHandlers(files, t.TempDir(), "/tus/files", time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create tus handlers: %v", err)
+	}
+
+	f := &tusTestFixture{e: echo.New(), handlers: h, files: files, now: time.Now()}
+	h.now = func() time.Time { return f.now }
+
+	g := f.e.Group("/tus/files", h.TusResumable)
+	g.OPTIONS("", h.OptionsHandler)
+	g.POST("", h.CreateHandler)
+	g.HEAD("/:id", h.HeadHandler)
+	g.PATCH("/:id", h.PatchHandler)
+	g.DELETE("/:id", h.DeleteHandler)
+	return f
+}
+
+func (f *tusTestFixture) do(method, path string, headers map[string]string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(HeaderTusResumable, TusVersion)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	f.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func (f *tusTestFixture) create(t *testing.T, filename string, length int) string {
+	t.Helper()
+	rec := f.do(http.MethodPost, "/tus/files", map[string]string{
+		HeaderUploadLength:   strconv.Itoa(length),
+		HeaderUploadMetadata: "filename " + base64.StdEncoding.EncodeToString([]byte(filename)),
+	}, "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	return rec.Header().Get(echo.HeaderLocation)
+}
+
+func (f *tusTestFixture) patch(location string, offset int, chunk string) *httptest.ResponseRecorder {
+	return f.do(http.MethodPatch, location, map[string]string{
+		echo.HeaderContentType: TusOffsetStreamType,
+		HeaderUploadOffset:     strconv.Itoa(offset),
+	}, chunk)
+}
+
+func TestTusHandlers_ResumableUpload(t *testing.T) {
+	f := newTusTestFixture(t)
+	location := f.create(t, "relatorio.txt", 10)
+
+	if rec := f.patch(location, 0, "01234"); rec.Code != http.StatusNoContent || rec.Header().Get(HeaderUploadOffset) != "5" {
+		t.Fatalf("Expected offset 5, got %d %s", rec.Code, rec.Header().Get(HeaderUploadOffset))
+	}
+
+	rec := f.do(http.MethodHead, location, nil, "")
+	if rec.Code != http.StatusOK || rec.Header().Get(HeaderUploadOffset) != "5" || rec.Header().Get(HeaderUploadLength) != "10" {
+		t.Errorf("Unexpected HEAD response: %d %v", rec.Code, rec.Header())
+	}
+	if rec.Header().Get(echo.HeaderCacheControl) != "no-store" {
+		t.Error("Expected Cache-Control: no-store")
+	}
+
+	if rec := f.patch(location, 3, "34567"); rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for wrong offset, got %d", rec.Code)
+	}
+
+	rec = f.patch(location, 5, "56789")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	fileID := rec.Header().Get(HeaderFileID)
+	file, err := f.files.Get(context.Background(), fileID)
+	if err != nil {
+		t.Fatalf("Expected file in catalog, got %v", err)
+	}
+	if file.OriginalName != "relatorio.txt" || file.Size != 10 {
+		t.Errorf("Unexpected catalog entry: %+v", file)
+	}
+}
+
+func TestTusHandlers_Protocol(t *testing.T) {
+	f := newTusTestFixture(t)
+
+	rec := f.do(http.MethodOptions, "/tus/files", nil, "")
+	if rec.Code != http.StatusNoContent || rec.Header().Get(HeaderTusExtension) != TusExtensions || rec.Header().Get(HeaderTusMaxSize) != "1024" {
+		t.Errorf("Unexpected OPTIONS response: %d %v", rec.Code, rec.Header())
+	}
+
+	rec = f.do(http.MethodPost, "/tus/files", map[string]string{HeaderTusResumable: "0.2.2", HeaderUploadLength: "1"}, "")
+	if rec.Code != http.StatusPreconditionFailed || rec.Header().Get(HeaderTusVersion) != TusVersion {
+		t.Errorf("Expected status 412 for unsupported version, got %d", rec.Code)
+	}
+
+	rec = f.do(http.MethodPost, "/tus/files", map[string]string{
+		HeaderUploadLength:   "2048",
+		HeaderUploadMetadata: "filename " + base64.StdEncoding.EncodeToString([]byte("grande.txt")),
+	}, "")
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected status 413, got %d", rec.Code)
+	}
+
+	rec = f.do(http.MethodPost, "/tus/files", map[string]string{
+		HeaderUploadLength:   "10",
+		HeaderUploadMetadata: "filename " + base64.StdEncoding.EncodeToString([]byte("programa.exe")),
+	}, "")
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status 415, got %d", rec.Code)
+	}
+
+	location := f.create(t, "nota.txt", 4)
+	rec = f.do(http.MethodPatch, location, map[string]string{HeaderUploadOffset: "0", echo.HeaderContentType: "text/plain"}, "abcd")
+	if rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected status 415 for wrong Content-Type, got %d", rec.Code)
+	}
+
+	if rec := f.do(http.MethodDelete, location, nil, ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204 on termination, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodHead, location, nil, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after termination, got %d", rec.Code)
+	}
+}
+
+func TestTusHandlers_Expiration(t *testing.T) {
+	f := newTusTestFixture(t)
+	location := f.create(t, "nota.txt", 4)
+	f.patch(location, 0, "ab")
+
+	f.now = f.now.Add(2 * time.Hour)
+
+	if rec := f.patch(location, 2, "cd"); rec.Code != http.StatusGone {
+		t.Errorf("Expected status 410 for expired upload, got %d", rec.Code)
+	}
+
+	expiring := f.create(t, "outra.txt", 4)
+	f.now = f.now.Add(2 * time.Hour)
+	if n := f.handlers.ExpireUploads(); n != 1 {
+		t.Errorf("Expected 1 expired upload, got %d", n)
+	}
+	if rec := f.do(http.MethodHead, expiring, nil, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after janitor, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Erros de validação de upload
+var (
+	ErrFileTooLarge    = errors.New("arquivo excede o tamanho máximo")
+	ErrTypeNotAllowed  = errors.New("tipo de arquivo não permitido")
+	ErrContentMismatch = errors.New("conteúdo não corresponde à extensão do arquivo")
+	ErrEmptyFile       = errors.New("arquivo vazio")
+)
+
+const (
+	// sniffLen é a quantidade de bytes usada na detecção do tipo de conteúdo
+	sniffLen = 512
+
+	// maxFilenameBytes é o tamanho máximo do nome original preservado
+	maxFilenameBytes = 255
+
+	// multipartOverhead é a folga concedida ao corpo da requisição, além do
+	// tamanho máximo do arquivo, para cabeçalhos e delimitadores multipart
+	multipartOverhead = 64 << 10
+)
+
+// contentSignatures associa cada extensão aos tipos MIME que
+// http.DetectContentType pode reconhecer para ela. Extensões permitidas
+// que não estão na tabela são aceitas com o tipo detectado.
+var contentSignatures = map[string][]string{
+	"jpg":  {"image/jpeg"},
+	"jpeg": {"image/jpeg"},
+	"png":  {"image/png"},
+	"gif":  {"image/gif"},
+	"webp": {"image/webp"},
+	"bmp":  {"image/bmp"},
+	"pdf":  {"application/pdf"},
+	"zip":  {"application/zip"},
+	"mp4":  {"video/mp4"},
+	"txt":  {"text/plain"},
+	"csv":  {"text/plain"},
+	"json": {"text/plain"},
+}
+
+// sanitizeFilename reduz o nome enviado pelo cliente ao último componente,
+// removendo separadores de diretório e caracteres de controle
+func sanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = filepath.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == '/' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+
+	if len(name) > maxFilenameBytes {
+		ext := filepath.Ext(name)
+		if len(ext) > 16 {
+			ext = ""
+		}
+		name = strings.ToValidUTF8(name[:maxFilenameBytes-len(ext)], "") + ext
+	}
+	if name == "" {
+		return "arquivo"
+	}
+	return name
+}
+
+// fileExtension retorna a extensão do arquivo em minúsculas, sem o ponto
+func fileExtension(name string) string {
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+}
+
+// sniffContent detecta o tipo do conteúdo pelos primeiros bytes e confere
+// se ele corresponde à extensão. Retorna um reader que reproduz o conteúdo
+// completo, incluindo os bytes já lidos.
+func sniffContent(ext string, r io.Reader) (string, io.Reader, error) {
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", nil, err
+	}
+	if n == 0 {
+		return "", nil, ErrEmptyFile
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if expected, ok := contentSignatures[ext]; ok {
+		detected, _, _ := mime.ParseMediaType(contentType)
+		matched := false
+		for _, e := range expected {
+			if detected == e {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", nil, ErrContentMismatch
+		}
+	}
+
+	return contentType, io.MultiReader(bytes.NewReader(head), r), nil
+}
@@ -0,0 +1,398 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/utils"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+)
+
+// TokenTTL é a validade dos tokens JWT emitidos no login
+const TokenTTL = 72 * time.Hour
+
+// MinPasswordLength é o tamanho mínimo da senha de um usuário
+const MinPasswordLength = 8
+
+// UserHandlers contém os handlers de usuários e autenticação
+type UserHandlers struct {
+	users *repository.UserRepository
+}
+
+// NewUserHandlers cria uma nova instância de handlers de usuários
+func NewUserHandlers(users *repository.UserRepository) *UserHandlers {
+	return &UserHandlers{users: users}
+}
+
+// UserRequest representa os dados completos de um usuário (POST e PUT).
+// Password é obrigatória na criação; no PUT, vazia mantém a senha atual.
+type UserRequest struct {
+	Name     string `json:"name" xml:"name" form:"name"`
+	Email    string `json:"email" xml:"email" form:"email"`
+	Age      int    `json:"age" xml:"age" form:"age"`
+	Role     string `json:"role" xml:"role" form:"role"`
+	Password string `json:"password" xml:"password" form:"password"`
+}
+
+// UserPatch representa uma atualização parcial; campos ausentes são mantidos
+type UserPatch struct {
+	Name     *string `json:"name"`
+	Email    *string `json:"email"`
+	Age      *int    `json:"age"`
+	Role     *string `json:"role"`
+	Password *string `json:"password"`
+}
+
+// VerifyEmailRequest contém o token enviado para o e-mail do usuário
+type VerifyEmailRequest struct {
+	Token string `json:"token" form:"token" query:"token"`
+}
+
+// CreateUserHandler cria um novo usuário com o e-mail pendente de verificação
+func (h *UserHandlers) CreateUserHandler(c echo.Context) error {
+	req := new(UserRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "Erro ao processar dados",
+			"error":   err.Error(),
+		})
+	}
+
+	user := models.NewUser(strings.TrimSpace(req.Name), req.Email, req.Age)
+	user.Role = middleware.RoleUser
+	if msg := validateUser(user); msg != "" {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(msg, ""))
+	}
+	if msg := validatePassword(req.Password); msg != "" {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(msg, ""))
+	}
+	if err := user.SetPassword(req.Password); err != nil {
+		return c.JSON(http.StatusInternalServerError, api.NewErrorResponse("Erro ao processar senha", err.Error()))
+	}
+	requestVerification(user)
+
+	created, err := h.users.Create(c.Request().Context(), user)
+	if err != nil {
+		return repositoryError(c, err, "Usuário não encontrado")
+	}
+	sendVerification(c, created)
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"message": "Usuário criado com sucesso",
+		"data":    created,
+	})
+}
+
+// ListUsersHandler lista os usuários cadastrados, paginados (somente admin)
+func (h *UserHandlers) ListUsersHandler(c echo.Context) error {
+	page, perPage := paginationParams(c)
+	users, total := h.users.List(c.Request().Context(), page, perPage)
+
+	return c.JSON(http.StatusOK, api.NewPaginatedResponse("Usuários listados com sucesso", users, api.NewPagination(page, perPage, total)))
+}
+
+// GetUserHandler obtém um usuário específico
+func (h *UserHandlers) GetUserHandler(c echo.Context) error {
+	id, ok, err := h.accessibleUserID(c)
+	if !ok {
+		return err
+	}
+
+	user, err := h.users.Get(c.Request().Context(), id)
+	if err != nil {
+		return repositoryError(c, err, "Usuário não encontrado")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Usuário encontrado", user))
+}
+
+// UpdateUserHandler substitui os dados de um usuário
+func (h *UserHandlers) UpdateUserHandler(c echo.Context) error {
+	id, ok, err := h.accessibleUserID(c)
+	if !ok {
+		return err
+	}
+
+	req := new(UserRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar dados", err.Error()))
+	}
+
+	patch := &UserPatch{Name: &req.Name, Email: &req.Email, Age: &req.Age}
+	if req.Role != "" {
+		patch.Role = &req.Role
+	}
+	if req.Password != "" {
+		patch.Password = &req.Password
+	}
+	return h.applyPatch(c, id, patch)
+}
+
+// PatchUserHandler atualiza parcialmente um usuário
+func (h *UserHandlers) PatchUserHandler(c echo.Context) error {
+	id, ok, err := h.accessibleUserID(c)
+	if !ok {
+		return err
+	}
+
+	patch := new(UserPatch)
+	if err := c.Bind(patch); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar dados", err.Error()))
+	}
+
+	return h.applyPatch(c, id, patch)
+}
+
+// DeleteUserHandler remove um usuário
+func (h *UserHandlers) DeleteUserHandler(c echo.Context) error {
+	id, ok, err := h.accessibleUserID(c)
+	if !ok {
+		return err
+	}
+
+	if err := h.users.Delete(c.Request().Context(), id); err != nil {
+		return repositoryError(c, err, "Usuário não encontrado")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Usuário removido com sucesso", nil))
+}
+
+// VerifyEmailHandler confirma o e-mail do usuário com o token recebido
+func (h *UserHandlers) VerifyEmailHandler(c echo.Context) error {
+	id, err := intParam(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de usuário inválido", err.Error()))
+	}
+
+	req := new(VerifyEmailRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar dados", err.Error()))
+	}
+
+	user, err := h.users.VerifyEmail(c.Request().Context(), id, req.Token)
+	if err != nil {
+		return repositoryError(c, err, "Usuário não encontrado")
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("E-mail verificado com sucesso", user))
+}
+
+// LoginHandler gera um token JWT para um usuário cadastrado, identificado
+// pelo e-mail, com a senha correta; o token leva o ID e o papel do cadastro.
+// E-mail desconhecido e senha errada recebem a mesma resposta, para não
+// revelar quais e-mails estão cadastrados.
+func (h *UserHandlers) LoginHandler(c echo.Context) error {
+	type LoginRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	loginReq := new(LoginRequest)
+	if err := c.Bind(loginReq); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "Dados de login inválidos",
+			"error":   err.Error(),
+		})
+	}
+
+	// Validação simples para demonstração
+	if loginReq.Username == "" || loginReq.Password == "" {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"success": false,
+			"message": "Username e password são obrigatórios",
+			"error":   "",
+		})
+	}
+
+	user, err := h.users.GetByEmail(c.Request().Context(), loginReq.Username)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if err != nil {
+		// Confere a senha mesmo assim, para que o tempo de resposta também
+		// não revele se o e-mail existe
+		user = &models.User{PasswordHash: unknownUserHash}
+	}
+	if !user.CheckPassword(loginReq.Password) || user.ID == 0 {
+		return c.JSON(http.StatusUnauthorized, api.NewErrorResponse("Credenciais inválidas", ""))
+	}
+	userID, role := user.ID, user.Role
+
+	expires := time.Now().Add(TokenTTL).Unix()
+
+	// Claims do token
+	claims := jwt.MapClaims{
+		"user_id":  userID,
+		"username": loginReq.Username,
+		"role":     role,
+		"exp":      expires,
+	}
+
+	// Criar token
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString(middleware.JWTSecret)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+			"success": false,
+			"message": "Erro ao gerar token",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"message": "Login realizado com sucesso",
+		"data": map[string]interface{}{
+			"token":    tokenString,
+			"user_id":  userID,
+			"username": loginReq.Username,
+			"role":     role,
+			"expires":  expires,
+		},
+	})
+}
+
+// unknownUserHash é conferido no login de e-mails não cadastrados, que
+// assim levam o mesmo tempo que uma senha errada
+var unknownUserHash = func() string {
+	var u models.User
+	_ = u.SetPassword("senha-de-usuario-inexistente")
+	return u.PasswordHash
+}()
+
+// accessibleUserID lê o ID da rota e verifica se o usuário autenticado pode
+// acessá-lo: administradores acessam qualquer registro, os demais apenas o
+// próprio. Quando ok é falso, err contém a resposta já enviada.
+func (h *UserHandlers) accessibleUserID(c echo.Context) (id int, ok bool, err error) {
+	id, err = intParam(c, "id")
+	if err != nil {
+		return 0, false, c.JSON(http.StatusBadRequest, api.NewErrorResponse("ID de usuário inválido", err.Error()))
+	}
+
+	if current, _ := middleware.UserID(c); current != id && !middleware.IsAdmin(c) {
+		return 0, false, c.JSON(http.StatusForbidden, api.NewErrorResponse("Acesso negado", ""))
+	}
+	return id, true, nil
+}
+
+// applyPatch aplica as alterações ao usuário e persiste o resultado. O novo
+// e-mail só substitui o atual depois de verificado, e a troca de papel é
+// restrita a admins.
+func (h *UserHandlers) applyPatch(c echo.Context, id int, patch *UserPatch) error {
+	ctx := c.Request().Context()
+	user, err := h.users.Get(ctx, id)
+	if err != nil {
+		return repositoryError(c, err, "Usuário não encontrado")
+	}
+
+	if patch.Role != nil && *patch.Role != user.Role {
+		if !middleware.IsAdmin(c) {
+			return c.JSON(http.StatusForbidden, api.NewErrorResponse("Apenas administradores podem alterar papéis", ""))
+		}
+		if *patch.Role != middleware.RoleUser && *patch.Role != middleware.RoleAdmin {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Papel inválido", ""))
+		}
+		user.Role = *patch.Role
+	}
+	if patch.Name != nil {
+		user.Name = strings.TrimSpace(*patch.Name)
+	}
+	if patch.Age != nil {
+		user.Age = *patch.Age
+	}
+	if patch.Password != nil {
+		if msg := validatePassword(*patch.Password); msg != "" {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse(msg, ""))
+		}
+		if err := user.SetPassword(*patch.Password); err != nil {
+			return c.JSON(http.StatusInternalServerError, api.NewErrorResponse("Erro ao processar senha", err.Error()))
+		}
+	}
+
+	if msg := validateUser(user); msg != "" {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse(msg, ""))
+	}
+
+	// O novo e-mail fica pendente até ser confirmado; até lá, o atual
+	// continua valendo, inclusive para o login
+	emailChanged := false
+	if patch.Email != nil && repository.NormalizeEmail(*patch.Email) != user.Email {
+		if _, err := mail.ParseAddress(*patch.Email); err != nil {
+			return c.JSON(http.StatusBadRequest, api.NewErrorResponse("E-mail inválido", ""))
+		}
+		user.PendingEmail = *patch.Email
+		user.VerificationToken = utils.NewID()
+		emailChanged = true
+	}
+
+	updated, err := h.users.Update(ctx, id, user)
+	if err != nil {
+		return repositoryError(c, err, "Usuário não encontrado")
+	}
+
+	message := "Usuário atualizado com sucesso"
+	if emailChanged {
+		sendVerification(c, updated)
+		message = "Usuário atualizado; confirme o novo e-mail"
+	}
+
+	return c.JSON(http.StatusOK, api.NewSuccessResponse(message, updated))
+}
+
+// validateUser valida os campos obrigatórios de um usuário. Retorna uma
+// mensagem de erro se o usuário for inválido.
+func validateUser(u *models.User) string {
+	if u.Name == "" {
+		return "Nome é obrigatório"
+	}
+	if _, err := mail.ParseAddress(u.Email); err != nil {
+		return "E-mail inválido"
+	}
+	if u.Age < 0 {
+		return "Idade não pode ser negativa"
+	}
+	return ""
+}
+
+// validatePassword verifica o tamanho mínimo da senha. Retorna uma mensagem
+// de erro se a senha for inválida.
+func validatePassword(password string) string {
+	if len(password) < MinPasswordLength {
+		return fmt.Sprintf("A senha deve ter ao menos %d caracteres", MinPasswordLength)
+	}
+	return ""
+}
+
+// requestVerification marca o e-mail do usuário como não verificado e gera
+// um novo token de verificação
+func requestVerification(u *models.User) {
+	u.EmailVerified = false
+	u.VerificationToken = utils.NewID()
+}
+
+// sendVerification simula o envio do token de verificação para o e-mail
+// pendente ou, se não houver, para o atual. O token não é registrado no log.
+func sendVerification(c echo.Context, u *models.User) {
+	to := u.Email
+	if u.PendingEmail != "" {
+		to = u.PendingEmail
+	}
+	slog.InfoContext(c.Request().Context(), "verificação de e-mail enviada",
+		"email", to,
+		"url", fmt.Sprintf("/api/v1/users/%d/verify-email", u.ID),
+	)
+}
@@ -0,0 +1,391 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/middleware"
+	"echo-playground/pkg/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestUserHandlers_CreateUserHandler(t *testing.T) {
+	e := setupTestEcho()
+
+	userJSON := `{"name":"Test User","email":"test@example.com","age":25,"password":"senha-secreta"}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(userJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := NewUserHandlers(repository.NewUserRepository())
+	err := h.CreateUserHandler(c)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+
+	if response["success"] != true {
+		t.Error("Expected success to be true")
+	}
+
+	if response["message"] != "Usuário criado com sucesso" {
+		t.Errorf("Unexpected message: %v", response["message"])
+	}
+	if strings.Contains(rec.Body.String(), "senha") || strings.Contains(rec.Body.String(), "password") {
+		t.Errorf("Expected no password in response, got %s", rec.Body.String())
+	}
+}
+
+func TestUserHandlers_CreateUserHandler_ShortPassword(t *testing.T) {
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name":"Test User","email":"test@example.com","age":25,"password":"curta"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	NewUserHandlers(repository.NewUserRepository()).CreateUserHandler(e.NewContext(req, rec))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestUserHandlers_CreateUserHandler_InvalidJSON(t *testing.T) {
+	e := setupTestEcho()
+
+	invalidJSON := `{"name":"Test User","email":}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(invalidJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	h := NewUserHandlers(repository.NewUserRepository())
+	err := h.CreateUserHandler(c)
+
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+
+	if response["success"] == true {
+		t.Error("Expected success to be false")
+	}
+}
+
+func newTestUserHandlers() (*UserHandlers, *repository.UserRepository) {
+	users := repository.NewUserRepository(
+		&models.User{Name: "Maria Silva", Email: "maria@exemplo.com", Age: 28, Role: middleware.RoleUser, EmailVerified: true},
+		&models.User{Name: "João Santos", Email: "joao@exemplo.com", Age: 32, Role: middleware.RoleUser, EmailVerified: true},
+	)
+	return NewUserHandlers(users), users
+}
+
+func userRequest(method, id, body string, userID int, role string) (*httptest.ResponseRecorder, echo.Context) {
+	e := setupTestEcho()
+	req := httptest.NewRequest(method, "/users/"+id, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	c.Set(middleware.ContextKeyUserID, userID)
+	c.Set(middleware.ContextKeyRole, role)
+	return rec, c
+}
+
+func TestUserHandlers_ListUsersHandler(t *testing.T) {
+	h, _ := newTestUserHandlers()
+
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodGet, "/users?page=2&per_page=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.ListUsersHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	var response struct {
+		Data       []models.User  `json:"data"`
+		Pagination api.Pagination `json:"pagination"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(response.Data) != 1 || response.Data[0].ID != 2 {
+		t.Errorf("Expected second user on page 2, got %+v", response.Data)
+	}
+	if response.Pagination.Total != 2 || response.Pagination.TotalPages != 2 {
+		t.Errorf("Unexpected pagination: %+v", response.Pagination)
+	}
+}
+
+func TestUserHandlers_ListUsersHandler_HugePage(t *testing.T) {
+	h, _ := newTestUserHandlers()
+
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodGet, "/users?page=9223372036854775807&per_page=100", nil)
+	rec := httptest.NewRecorder()
+
+	if err := h.ListUsersHandler(e.NewContext(req, rec)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var response struct {
+		Data []models.User `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if len(response.Data) != 0 {
+		t.Errorf("Expected empty page, got %d users", len(response.Data))
+	}
+}
+
+func TestUserHandlers_GetUserHandler_Access(t *testing.T) {
+	h, _ := newTestUserHandlers()
+
+	rec, c := userRequest(http.MethodGet, "1", "", 1, middleware.RoleUser)
+	_ = h.GetUserHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for own record, got %d", rec.Code)
+	}
+
+	rec, c = userRequest(http.MethodGet, "2", "", 1, middleware.RoleUser)
+	_ = h.GetUserHandler(c)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for another user's record, got %d", rec.Code)
+	}
+
+	rec, c = userRequest(http.MethodGet, "2", "", 99, middleware.RoleAdmin)
+	_ = h.GetUserHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for admin, got %d", rec.Code)
+	}
+}
+
+func TestUserHandlers_PatchEmailRequiresVerification(t *testing.T) {
+	h, users := newTestUserHandlers()
+
+	rec, c := userRequest(http.MethodPatch, "1", `{"email":"Maria.Nova@Exemplo.com"}`, 1, middleware.RoleUser)
+	if err := h.PatchUserHandler(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	user, _ := users.Get(context.Background(), 1)
+	if user.Email != "maria@exemplo.com" || user.PendingEmail != "maria.nova@exemplo.com" {
+		t.Fatalf("Expected current email to be kept until verified, got %+v", user)
+	}
+	if user.Name != "Maria Silva" {
+		t.Errorf("Expected name to be preserved, got %s", user.Name)
+	}
+	if _, err := users.GetByEmail(context.Background(), "maria.nova@exemplo.com"); err == nil {
+		t.Error("Expected pending email not to be usable before verification")
+	}
+
+	rec, c = userRequest(http.MethodPost, "1", `{"token":"errado"}`, 0, "")
+	_ = h.VerifyEmailHandler(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for wrong token, got %d", rec.Code)
+	}
+
+	rec, c = userRequest(http.MethodPost, "1", `{"token":"`+user.VerificationToken+`"}`, 0, "")
+	_ = h.VerifyEmailHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+
+	if user, _ := users.Get(context.Background(), 1); !user.EmailVerified || user.Email != "maria.nova@exemplo.com" || user.PendingEmail != "" {
+		t.Errorf("Expected new email to be verified, got %+v", user)
+	}
+}
+
+func TestUserHandlers_UpdateUserHandler(t *testing.T) {
+	h, _ := newTestUserHandlers()
+
+	rec, c := userRequest(http.MethodPut, "1", `{"name":"Maria","email":"joao@exemplo.com","age":29}`, 1, middleware.RoleUser)
+	_ = h.UpdateUserHandler(c)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for duplicate email, got %d", rec.Code)
+	}
+
+	rec, c = userRequest(http.MethodPut, "1", `{"name":"Maria","email":"maria@exemplo.com","age":29,"role":"admin"}`, 1, middleware.RoleUser)
+	_ = h.UpdateUserHandler(c)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for role escalation, got %d", rec.Code)
+	}
+
+	rec, c = userRequest(http.MethodPut, "1", `{"name":"Maria","email":"maria@exemplo.com","age":29}`, 1, middleware.RoleUser)
+	_ = h.UpdateUserHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestUserHandlers_DeleteUserHandler(t *testing.T) {
+	h, users := newTestUserHandlers()
+
+	rec, c := userRequest(http.MethodDelete, "2", "", 99, middleware.RoleAdmin)
+	_ = h.DeleteUserHandler(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	if _, err := users.Get(context.Background(), 2); err == nil {
+		t.Error("Expected user to be deleted")
+	}
+}
+
+func loginRequest(h *UserHandlers, body string) (*httptest.ResponseRecorder, *middleware.Claims) {
+	e := setupTestEcho()
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.LoginHandler(e.NewContext(req, rec))
+
+	var response struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &response)
+	claims, _ := middleware.ParseToken(response.Data.Token)
+	return rec, claims
+}
+
+func TestUserHandlers_LoginHandler_NoAdminUsername(t *testing.T) {
+	h, _ := newTestUserHandlers()
+
+	rec, claims := loginRequest(h, `{"username":"admin","password":"qualquer"}`)
+	if rec.Code == http.StatusOK && (claims == nil || claims.Role == middleware.RoleAdmin) {
+		t.Errorf("Expected no admin token for username admin, got %+v", claims)
+	}
+}
+
+func TestUserHandlers_LoginHandler(t *testing.T) {
+	h, users := newTestUserHandlers()
+	admin := &models.User{Name: "Admin", Email: "admin@exemplo.com", Role: middleware.RoleAdmin, EmailVerified: true}
+	admin.SetPassword("senha-do-admin")
+	created, _ := users.Create(context.Background(), admin)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		userID int
+		role   string
+	}{
+		{"correct password", `{"username":"admin@exemplo.com","password":"senha-do-admin"}`, http.StatusOK, created.ID, middleware.RoleAdmin},
+		{"wrong password", `{"username":"admin@exemplo.com","password":"qualquer"}`, http.StatusUnauthorized, 0, ""},
+		{"user without password", `{"username":"maria@exemplo.com","password":"qualquer"}`, http.StatusUnauthorized, 0, ""},
+		{"unregistered", `{"username":"visitante@exemplo.com","password":"qualquer"}`, http.StatusUnauthorized, 0, ""},
+	}
+	for _, tt := range tests {
+		rec, claims := loginRequest(h, tt.body)
+		if rec.Code != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.status, rec.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		if claims == nil || claims.UserID != tt.userID || claims.Role != tt.role {
+			t.Errorf("%s: expected user %d with role %s, got %+v", tt.name, tt.userID, tt.role, claims)
+		}
+	}
+}
+
+func TestUserHandlers_PatchPassword(t *testing.T) {
+	h, users := newTestUserHandlers()
+
+	rec, c := userRequest(http.MethodPatch, "1", `{"password":"nova-senha-123"}`, 1, middleware.RoleUser)
+	if err := h.PatchUserHandler(c); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d (%v)", rec.Code, err)
+	}
+	user, _ := users.Get(context.Background(), 1)
+	if !user.CheckPassword("nova-senha-123") {
+		t.Error("Expected new password to be stored")
+	}
+
+	// Outras alterações mantêm a senha
+	rec, c = userRequest(http.MethodPatch, "1", `{"name":"Maria S."}`, 1, middleware.RoleUser)
+	h.PatchUserHandler(c)
+	if user, _ := users.Get(context.Background(), 1); rec.Code != http.StatusOK || !user.CheckPassword("nova-senha-123") {
+		t.Errorf("Expected password to be kept, got status %d", rec.Code)
+	}
+
+	rec, c = userRequest(http.MethodPatch, "1", `{"password":"curta"}`, 1, middleware.RoleUser)
+	h.PatchUserHandler(c)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for short password, got %d", rec.Code)
+	}
+}
+
+func TestUserHandlers_LoginHandler_SameResponseForUnknownEmail(t *testing.T) {
+	h, users := newTestUserHandlers()
+	user, _ := users.Get(context.Background(), 1)
+	user.SetPassword("senha-da-maria")
+	users.Update(context.Background(), 1, user)
+
+	wrong, _ := loginRequest(h, `{"username":"`+user.Email+`","password":"outra-senha"}`)
+	unknown, _ := loginRequest(h, `{"username":"visitante@exemplo.com","password":"outra-senha"}`)
+	if wrong.Code != http.StatusUnauthorized || unknown.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected status 401 for both, got %d and %d", wrong.Code, unknown.Code)
+	}
+	if wrong.Body.String() != unknown.Body.String() {
+		t.Errorf("Expected the same body, got %s and %s", wrong.Body.String(), unknown.Body.String())
+	}
+}
+
+func TestUserHandlers_VerificationTokenNotLogged(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	h, users := newTestUserHandlers()
+	_, c := userRequest(http.MethodPatch, "1", `{"email":"maria.nova@exemplo.com"}`, 1, middleware.RoleUser)
+	_ = h.PatchUserHandler(c)
+
+	user, _ := users.Get(context.Background(), 1)
+	if user.VerificationToken == "" || !strings.Contains(buf.String(), "maria.nova@exemplo.com") {
+		t.Fatalf("Expected verification to be sent, got %s", buf.String())
+	}
+	if strings.Contains(buf.String(), user.VerificationToken) {
+		t.Errorf("Expected token not to be logged, got %s", buf.String())
+	}
+}
@@ -0,0 +1,308 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/tracing"
+	"echo-playground/pkg/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers enviados em cada entrega de webhook. A assinatura é
+// "sha256=" seguido do HMAC-SHA256, em hexadecimal, de
+// "<X-Webhook-Timestamp>.<corpo>" com o segredo do webhook.
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// webhookBatchSize é a quantidade de entregas pendentes buscada por vez
+const webhookBatchSize = 50
+
+// webhookResponseLimit é o máximo lido da resposta de um destino
+const webhookResponseLimit = 64 << 10
+
+// WebhookPayload é o corpo JSON de cada entrega. ID identifica o evento e
+// se repete nas entregas do mesmo evento a webhooks diferentes e nos
+// reenvios, permitindo ao destino descartar duplicatas.
+type WebhookPayload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDispatcher transforma as alterações publicadas no barramento em
+// entregas de webhook e as envia em segundo plano, com novas tentativas e
+// espera exponencial
+type WebhookDispatcher struct {
+	webhooks    *repository.WebhookRepository
+	client      *http.Client
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	now         func() time.Time
+	wake        chan struct{}
+}
+
+// NewWebhookDispatcher cria o despachante e assina as alterações de
+// produtos e usuários no barramento
+func NewWebhookDispatcher(webhooks *repository.WebhookRepository, bus *eventbus.Bus, cfg config.WebhookConfig) *WebhookDispatcher {
+	d := &WebhookDispatcher{
+		webhooks:    webhooks,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		maxAttempts: cfg.MaxAttempts,
+		baseDelay:   cfg.RetryBaseDelay,
+		maxDelay:    cfg.RetryMaxDelay,
+		now:         time.Now,
+		wake:        make(chan struct{}, 1),
+	}
+	if d.maxAttempts < 1 {
+		d.maxAttempts = 1
+	}
+
+	eventbus.Subscribe(bus, "webhooks", func(ctx context.Context, change repository.ProductChange) error {
+		return d.Enqueue(ctx, "product."+string(change.Type), change.At, map[string]interface{}{"product": change.Product})
+	})
+	eventbus.Subscribe(bus, "webhooks", func(ctx context.Context, change repository.UserChange) error {
+		return d.Enqueue(ctx, "user."+string(change.Type), change.At, map[string]interface{}{"user": change.User})
+	})
+	return d
+}
+
+// Enqueue registra uma entrega do evento para cada webhook que o assina e
+// acorda o worker. Nada é enviado durante a chamada.
+func (d *WebhookDispatcher) Enqueue(ctx context.Context, event string, at time.Time, data interface{}) error {
+	subscribers := d.webhooks.Subscribers(ctx, event)
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(WebhookPayload{ID: utils.NewID(), Event: event, CreatedAt: at.UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+	now := d.now()
+	traceContext := webhookTraceContext(ctx)
+	for _, w := range subscribers {
+		// Um webhook removido desde a consulta apenas deixa de receber
+		_, err := d.webhooks.CreateDelivery(ctx, &models.WebhookDelivery{WebhookID: w.ID, Event: event, Payload: payload, NextAttemptAt: &now, TraceContext: traceContext})
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+	d.requestDelivery()
+	return nil
+}
+
+// Ping registra uma entrega do evento "ping" para o webhook, ativo ou não
+func (d *WebhookDispatcher) Ping(ctx context.Context, webhookID string) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(WebhookPayload{
+		ID:        utils.NewID(),
+		Event:     models.WebhookEventPing,
+		CreatedAt: d.now().UTC(),
+		Data:      map[string]string{"webhook_id": webhookID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d.queue(ctx, &models.WebhookDelivery{WebhookID: webhookID, Event: models.WebhookEventPing, Payload: payload})
+}
+
+// Redeliver registra uma nova entrega com o mesmo corpo de uma entrega
+// anterior, por exemplo para reenviar uma entrega em dead-letter
+func (d *WebhookDispatcher) Redeliver(ctx context.Context, deliveryID string) (*models.WebhookDelivery, error) {
+	previous, err := d.webhooks.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	return d.queue(ctx, &models.WebhookDelivery{WebhookID: previous.WebhookID, Event: previous.Event, Payload: previous.Payload})
+}
+
+// queue registra a entrega para envio imediato e acorda o worker
+func (d *WebhookDispatcher) queue(ctx context.Context, delivery *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := d.now()
+	delivery.NextAttemptAt = &now
+	delivery.TraceContext = webhookTraceContext(ctx)
+	created, err := d.webhooks.CreateDelivery(ctx, delivery)
+	if err != nil {
+		return nil, err
+	}
+	d.requestDelivery()
+	return created, nil
+}
+
+// StartWorker envia em segundo plano as entregas pendentes, logo após cada
+// novo evento e também a cada intervalo, até o contexto ser cancelado
+func (d *WebhookDispatcher) StartWorker(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-d.wake:
+			case <-ticker.C:
+			}
+			d.DeliverDue(ctx)
+		}
+	}()
+}
+
+// DeliverDue tenta todas as entregas cujo horário já chegou e retorna
+// quantas tentativas foram feitas
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) int {
+	attempts := 0
+	for ctx.Err() == nil {
+		due := d.webhooks.DueDeliveries(ctx, d.now(), webhookBatchSize)
+		if len(due) == 0 {
+			break
+		}
+		for _, delivery := range due {
+			d.attempt(ctx, delivery)
+			attempts++
+		}
+	}
+	return attempts
+}
+
+// attempt envia a entrega uma vez e registra o resultado: entregue, nova
+// tentativa agendada ou, esgotadas as tentativas, dead-letter. Cada
+// tentativa é um span filho da operação que gerou a entrega.
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	ctx = tracing.Extract(ctx, propagation.MapCarrier(delivery.TraceContext))
+	ctx, span := tracing.Start(ctx, "webhook.deliver",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("webhook.id", delivery.WebhookID),
+			attribute.String("webhook.event", delivery.Event),
+			attribute.String("webhook.delivery_id", delivery.ID),
+			attribute.Int("webhook.attempt", len(delivery.Attempts)+1),
+		),
+	)
+
+	webhook, err := d.webhooks.Get(ctx, delivery.WebhookID)
+	if err != nil {
+		tracing.End(span, err)
+		return
+	}
+
+	span.SetAttributes(attribute.String("http.request.method", http.MethodPost), attribute.String("url.full", webhook.URL))
+	start := d.now()
+	status, err := d.send(ctx, webhook, delivery)
+	if status != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+	}
+	defer tracing.End(span, err)
+
+	attempt := models.WebhookAttempt{At: start, StatusCode: status, DurationMs: d.now().Sub(start).Milliseconds()}
+	if err == nil {
+		_, _ = d.webhooks.RecordAttempt(ctx, delivery.ID, attempt, models.WebhookDeliveryDelivered, nil)
+		return
+	}
+
+	attempt.Error = err.Error()
+	failures := len(delivery.Attempts) + 1
+	if failures >= d.maxAttempts {
+		_, _ = d.webhooks.RecordAttempt(ctx, delivery.ID, attempt, models.WebhookDeliveryDead, nil)
+		return
+	}
+	next := d.now().Add(d.backoff(failures))
+	_, _ = d.webhooks.RecordAttempt(ctx, delivery.ID, attempt, models.WebhookDeliveryRetrying, &next)
+}
+
+// send faz o POST assinado e retorna o status recebido. Qualquer resposta
+// fora da faixa 2xx é uma falha.
+func (d *WebhookDispatcher) send(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "echo-playground-webhooks/1.0")
+	req.Header.Set(HeaderWebhookEvent, delivery.Event)
+	req.Header.Set(HeaderWebhookDelivery, delivery.ID)
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderWebhookSignature, SignWebhook(webhook.Secret, timestamp, delivery.Payload))
+	tracing.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("destino respondeu %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff retorna a espera antes da próxima tentativa após failures falhas:
+// RetryBaseDelay, dobrando a cada falha, limitada a RetryMaxDelay
+func (d *WebhookDispatcher) backoff(failures int) time.Duration {
+	delay := d.baseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if d.maxDelay > 0 && delay >= d.maxDelay {
+			return d.maxDelay
+		}
+	}
+	if d.maxDelay > 0 && delay > d.maxDelay {
+		return d.maxDelay
+	}
+	return delay
+}
+
+// webhookTraceContext retorna o traceparent do contexto, guardado na
+// entrega, ou nil fora de uma trace
+func webhookTraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	tracing.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// requestDelivery acorda o worker de entregas sem bloquear
+func (d *WebhookDispatcher) requestDelivery() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SignWebhook calcula o valor do header X-Webhook-Signature. O destino
+// deve recalculá-lo com o próprio segredo e comparar com hmac.Equal,
+// rejeitando timestamps muito antigos para evitar reenvios maliciosos.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MinWebhookSecretLength é o tamanho mínimo de um segredo informado pelo
+// cliente; sem segredo, um aleatório é gerado
+const MinWebhookSecretLength = 16
+
+// WebhookHandlers contém os handlers de gerenciamento de webhooks (admin)
+type WebhookHandlers struct {
+	webhooks   *repository.WebhookRepository
+	dispatcher *WebhookDispatcher
+}
+
+// NewWebhookHandlers cria uma nova instância de handlers de webhooks
+func NewWebhookHandlers(webhooks *repository.WebhookRepository, dispatcher *WebhookDispatcher) *WebhookHandlers {
+	return &WebhookHandlers{webhooks: webhooks, dispatcher: dispatcher}
+}
+
+// WebhookRequest representa a criação ou alteração de um webhook. Active
+// ausente mantém o webhook ativo; Secret vazio gera um segredo na criação e
+// mantém o atual na alteração.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+// WebhookWithSecret é a resposta da criação e da troca de segredo, a única
+// em que o segredo é exibido
+type WebhookWithSecret struct {
+	*models.Webhook
+	Secret string `json:"secret"`
+}
+
+// CreateWebhookHandler cadastra um webhook
+func (h *WebhookHandlers) CreateWebhookHandler(c echo.Context) error {
+	req := new(WebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar webhook", err.Error()))
+	}
+	webhook, err := req.webhook()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Dados inválidos", err.Error()))
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = "whsec_" + utils.NewID()
+	}
+
+	created, err := h.webhooks.Create(c.Request().Context(), webhook)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, api.NewSuccessResponse("Webhook criado com sucesso", WebhookWithSecret{Webhook: created, Secret: created.Secret}))
+}
+
+// ListWebhooksHandler lista os webhooks cadastrados
+func (h *WebhookHandlers) ListWebhooksHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Webhooks listados com sucesso", h.webhooks.List(c.Request().Context())))
+}
+
+// GetWebhookHandler retorna um webhook
+func (h *WebhookHandlers) GetWebhookHandler(c echo.Context) error {
+	webhook, err := h.webhooks.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return repositoryError(c, err, "Webhook não encontrado")
+	}
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Webhook encontrado", webhook))
+}
+
+// UpdateWebhookHandler substitui a URL, os eventos e o estado de um
+// webhook; um novo segredo, se informado, é devolvido na resposta
+func (h *WebhookHandlers) UpdateWebhookHandler(c echo.Context) error {
+	req := new(WebhookRequest)
+	if err := c.Bind(req); err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Erro ao processar webhook", err.Error()))
+	}
+	webhook, err := req.webhook()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Dados inválidos", err.Error()))
+	}
+
+	updated, err := h.webhooks.Update(c.Request().Context(), c.Param("id"), webhook)
+	if err != nil {
+		return repositoryError(c, err, "Webhook não encontrado")
+	}
+
+	if webhook.Secret != "" {
+		return c.JSON(http.StatusOK, api.NewSuccessResponse("Webhook atualizado com sucesso", WebhookWithSecret{Webhook: updated, Secret: updated.Secret}))
+	}
+	return c.JSON(http.StatusOK, api.NewSuccessResponse("Webhook atualizado com sucesso", updated))
+}
+
+// DeleteWebhookHandler remove um webhook e seu registro de entregas
+func (h *WebhookHandlers) DeleteWebhookHandler(c echo.Context) error {
+	if err := h.webhooks.Delete(c.Request().Context(), c.Param("id")); err != nil {
+		return repositoryError(c, err, "Webhook não encontrado")
+	}
+	return c.JSON(http.StatusOK, api.NewSuccessMessage("Webhook removido com sucesso"))
+}
+
+// PingWebhookHandler envia o evento "ping" ao webhook para testar o destino
+func (h *WebhookHandlers) PingWebhookHandler(c echo.Context) error {
+	delivery, err := h.dispatcher.Ping(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return repositoryError(c, err, "Webhook não encontrado")
+	}
+	return c.JSON(http.StatusAccepted, api.NewSuccessResponse("Ping enviado para entrega", delivery))
+}
+
+// ListDeliveriesHandler lista as entregas de um webhook, das mais recentes
+// às mais antigas, com cada tentativa; ?status= filtra pela situação
+func (h *WebhookHandlers) ListDeliveriesHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	if _, err := h.webhooks.Get(ctx, c.Param("id")); err != nil {
+		return repositoryError(c, err, "Webhook não encontrado")
+	}
+
+	status := c.QueryParam("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryRetrying, models.WebhookDeliveryDelivered, models.WebhookDeliveryDead:
+	default:
+		return c.JSON(http.StatusBadRequest, api.NewErrorResponse("Parâmetro status inválido", "use pending, retrying, delivered ou dead"))
+	}
+
+	page, perPage := paginationParams(c)
+	deliveries, total := h.webhooks.ListDeliveries(ctx, repository.WebhookDeliveryFilter{WebhookID: c.Param("id"), Status: status}, page, perPage)
+
+	return c.JSON(http.StatusOK, api.NewPaginatedResponse("Entregas listadas com sucesso", deliveries, api.NewPagination(page, perPage, total)))
+}
+
+// RedeliverHandler reenvia o corpo de uma entrega como uma nova entrega
+func (h *WebhookHandlers) RedeliverHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	previous, err := h.webhooks.GetDelivery(ctx, c.Param("delivery_id"))
+	if err == nil && previous.WebhookID != c.Param("id") {
+		err = repository.ErrNotFound
+	}
+	if err != nil {
+		return repositoryError(c, err, "Entrega não encontrada")
+	}
+
+	delivery, err := h.dispatcher.Redeliver(ctx, previous.ID)
+	if err != nil {
+		return repositoryError(c, err, "Entrega não encontrada")
+	}
+	return c.JSON(http.StatusAccepted, api.NewSuccessResponse("Entrega agendada para reenvio", delivery))
+}
+
+// webhook valida a requisição e monta o webhook correspondente
+func (r *WebhookRequest) webhook() (*models.Webhook, error) {
+	target, err := url.Parse(strings.TrimSpace(r.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, errors.New("url deve ser um endereço http ou https absoluto")
+	}
+	if len(r.Events) == 0 {
+		return nil, errors.New("informe ao menos um evento")
+	}
+	for _, event := range r.Events {
+		if !models.ValidWebhookEvent(event) {
+			return nil, errors.New("evento desconhecido: " + event)
+		}
+	}
+	if r.Secret != "" && len(r.Secret) < MinWebhookSecretLength {
+		return nil, errors.New("o segredo deve ter ao menos 16 caracteres")
+	}
+
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return &models.Webhook{URL: target.String(), Events: r.Events, Secret: r.Secret, Active: active}, nil
+}
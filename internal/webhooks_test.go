@@ -0,0 +1,402 @@
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"echo-playground/internal/repository"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/eventbus"
+	"echo-playground/pkg/models"
+	"echo-playground/pkg/tracing"
+	"echo-playground/pkg/tracing/tracingtest"
+
+	"github.com/labstack/echo/v4"
+)
+
+// webhookReceiver é um destino de teste que valida a assinatura e responde
+// com os status da fila, repetindo o último
+type webhookReceiver struct {
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	received []WebhookPayload
+	headers  []http.Header
+	invalid  int
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	timestamp, _ := strconv.ParseInt(req.Header.Get(HeaderWebhookTimestamp), 10, 64)
+	if !hmac.Equal([]byte(req.Header.Get(HeaderWebhookSignature)), []byte(SignWebhook(r.secret, timestamp, body))) {
+		r.invalid++
+	}
+	var payload WebhookPayload
+	json.Unmarshal(body, &payload)
+	r.received = append(r.received, payload)
+	r.headers = append(r.headers, req.Header.Clone())
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		if len(r.statuses) > 1 {
+			r.statuses = r.statuses[1:]
+		}
+	}
+	w.WriteHeader(status)
+}
+
+type webhookFixture struct {
+	products   *repository.ProductRepository
+	webhooks   *repository.WebhookRepository
+	dispatcher *WebhookDispatcher
+	receiver   *webhookReceiver
+	url        string
+	clock      time.Time
+}
+
+func newWebhookFixture(t *testing.T, statuses ...int) *webhookFixture {
+	t.Helper()
+	f := &webhookFixture{
+		products: repository.NewProductRepository(),
+		webhooks: repository.NewWebhookRepository(),
+		receiver: &webhookReceiver{secret: "segredo-de-teste-123", statuses: statuses},
+		clock:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	server := httptest.NewServer(f.receiver)
+	t.Cleanup(server.Close)
+	f.url = server.URL
+
+	bus := eventbus.New(eventbus.Options{OnError: func(err error) { t.Errorf("Unexpected bus error: %v", err) }})
+	f.products.SetEventBus(bus)
+	f.dispatcher = NewWebhookDispatcher(f.webhooks, bus, config.WebhookConfig{
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		RetryBaseDelay: 10 * time.Second,
+		RetryMaxDelay:  15 * time.Second,
+	})
+	f.dispatcher.now = func() time.Time { return f.clock }
+	return f
+}
+
+func (f *webhookFixture) subscribe(t *testing.T, events ...string) *models.Webhook {
+	t.Helper()
+	w, err := f.webhooks.Create(context.Background(), &models.Webhook{URL: f.url, Events: events, Secret: f.receiver.secret, Active: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return w
+}
+
+func (f *webhookFixture) deliveries(t *testing.T, webhookID string) []*models.WebhookDelivery {
+	t.Helper()
+	list, _ := f.webhooks.ListDeliveries(context.Background(), repository.WebhookDeliveryFilter{WebhookID: webhookID}, 1, 100)
+	return list
+}
+
+func TestSignWebhook(t *testing.T) {
+	// HMAC-SHA256("key", "1700000000.{}"), calculado de forma independente
+	want := "sha256=9d713ed406bb7076d4123f0dc2c39d2df5c654ed4b0cd56b52c8b4c940bd63ae"
+	got := SignWebhook("key", 1700000000, []byte("{}"))
+	if got != want {
+		t.Fatalf("Expected %s, got %s", want, got)
+	}
+	if got == SignWebhook("other", 1700000000, []byte("{}")) || got == SignWebhook("key", 1700000001, []byte("{}")) {
+		t.Error("Expected signature to depend on secret and timestamp")
+	}
+}
+
+func TestWebhookDispatcher_DeliversSignedEvents(t *testing.T) {
+	f := newWebhookFixture(t)
+	w := f.subscribe(t, models.WebhookEventProductCreated, models.WebhookEventProductDeleted)
+	ctx := context.Background()
+
+	created, _ := f.products.Create(ctx, testProduct("Caneta"))
+	f.products.Update(ctx, created.ID, testProduct("Caneta azul")) // não assinado
+	f.products.Delete(ctx, created.ID)
+
+	if n := f.dispatcher.DeliverDue(ctx); n != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", n)
+	}
+
+	r := f.receiver
+	if r.invalid != 0 {
+		t.Errorf("Expected valid signatures, got %d invalid", r.invalid)
+	}
+	if len(r.received) != 2 || r.received[0].Event != "product.created" || r.received[1].Event != "product.deleted" {
+		t.Fatalf("Unexpected events: %+v", r.received)
+	}
+	data, _ := r.received[0].Data.(map[string]interface{})
+	if product, _ := data["product"].(map[string]interface{}); product["name"] != "Caneta" {
+		t.Errorf("Expected product data, got %+v", r.received[0].Data)
+	}
+	if r.headers[0].Get(HeaderWebhookEvent) != "product.created" || r.headers[0].Get(HeaderWebhookDelivery) == "" {
+		t.Errorf("Unexpected headers: %v", r.headers[0])
+	}
+	if ts := r.headers[0].Get(HeaderWebhookTimestamp); ts != strconv.FormatInt(f.clock.Unix(), 10) {
+		t.Errorf("Expected timestamp %d, got %s", f.clock.Unix(), ts)
+	}
+
+	for _, d := range f.deliveries(t, w.ID) {
+		if d.Status != models.WebhookDeliveryDelivered || len(d.Attempts) != 1 || d.Attempts[0].StatusCode != http.StatusOK {
+			t.Errorf("Expected delivered on first attempt, got %+v", d)
+		}
+	}
+}
+
+func TestWebhookDispatcher_RetriesWithBackoff(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK)
+	w := f.subscribe(t, models.WebhookEventAll)
+	ctx := context.Background()
+	start := f.clock
+
+	f.products.Create(ctx, testProduct("Caneta"))
+	f.dispatcher.DeliverDue(ctx)
+
+	d := f.deliveries(t, w.ID)[0]
+	if d.Status != models.WebhookDeliveryRetrying || !d.NextAttemptAt.Equal(start.Add(10*time.Second)) {
+		t.Fatalf("Expected retry in 10s, got %+v", d)
+	}
+	if d.Attempts[0].StatusCode != http.StatusInternalServerError || d.Attempts[0].Error == "" {
+		t.Errorf("Expected failed attempt to be logged, got %+v", d.Attempts[0])
+	}
+
+	// Antes do horário nada é enviado
+	if n := f.dispatcher.DeliverDue(ctx); n != 0 {
+		t.Errorf("Expected no attempts before the retry time, got %d", n)
+	}
+
+	// A segunda espera dobraria para 20s, mas é limitada a 15s
+	f.clock = start.Add(10 * time.Second)
+	f.dispatcher.DeliverDue(ctx)
+	d = f.deliveries(t, w.ID)[0]
+	if d.Status != models.WebhookDeliveryRetrying || !d.NextAttemptAt.Equal(f.clock.Add(15*time.Second)) {
+		t.Fatalf("Expected retry capped at 15s, got %+v", d)
+	}
+
+	f.clock = f.clock.Add(15 * time.Second)
+	f.dispatcher.DeliverDue(ctx)
+	d = f.deliveries(t, w.ID)[0]
+	if d.Status != models.WebhookDeliveryDelivered || len(d.Attempts) != 3 || d.DeliveredAt == nil {
+		t.Errorf("Expected delivery on third attempt, got %+v", d)
+	}
+
+	// O mesmo evento mantém o ID em todas as tentativas
+	r := f.receiver
+	if len(r.received) != 3 || r.received[0].ID != r.received[2].ID {
+		t.Errorf("Expected the same event ID on every attempt, got %+v", r.received)
+	}
+}
+
+func TestWebhookDispatcher_PropagatesTrace(t *testing.T) {
+	recorder := tracingtest.Record(t)
+	f := newWebhookFixture(t, http.StatusInternalServerError, http.StatusOK)
+	f.subscribe(t, models.WebhookEventAll)
+
+	// A alteração acontece dentro de uma requisição; as tentativas, feitas
+	// depois pelo worker, continuam a mesma trace
+	reqCtx, reqSpan := tracing.Start(context.Background(), "POST /api/v1/products")
+	f.products.Create(reqCtx, testProduct("Caneta"))
+	reqSpan.End()
+
+	f.dispatcher.DeliverDue(context.Background())
+	f.clock = f.clock.Add(time.Minute)
+	f.dispatcher.DeliverDue(context.Background())
+
+	// O evento é publicado dentro do span do repositório
+	create := tracingtest.Find(recorder, "products.Create")
+	if create == nil {
+		t.Fatal("Expected products.Create span")
+	}
+	traceID := reqSpan.SpanContext().TraceID().String()
+	var attempts []string
+	for _, span := range recorder.Ended() {
+		if span.Name() == "webhook.deliver" {
+			if span.SpanContext().TraceID().String() != traceID || span.Parent().SpanID() != create.SpanContext().SpanID() {
+				t.Errorf("Expected attempt span in the request trace, got parent %s", span.Parent().SpanID())
+			}
+			attempts = append(attempts, span.SpanContext().SpanID().String())
+		}
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("Expected 2 attempt spans, got %d", len(attempts))
+	}
+
+	for i, header := range f.receiver.headers {
+		want := "00-" + traceID + "-" + attempts[i] + "-01"
+		if got := header.Get("traceparent"); got != want {
+			t.Errorf("Attempt %d: expected traceparent %s, got %s", i+1, want, got)
+		}
+	}
+}
+
+func TestWebhookDispatcher_DeadLetterAndRedeliver(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusBadGateway)
+	w := f.subscribe(t, models.WebhookEventAll)
+	ctx := context.Background()
+
+	f.products.Create(ctx, testProduct("Caneta"))
+	for i := 0; i < 3; i++ {
+		f.dispatcher.DeliverDue(ctx)
+		f.clock = f.clock.Add(time.Minute)
+	}
+
+	d := f.deliveries(t, w.ID)[0]
+	if d.Status != models.WebhookDeliveryDead || len(d.Attempts) != 3 || d.NextAttemptAt != nil {
+		t.Fatalf("Expected dead-letter after 3 attempts, got %+v", d)
+	}
+	if n := f.dispatcher.DeliverDue(ctx); n != 0 {
+		t.Errorf("Expected dead deliveries not to be retried, got %d attempts", n)
+	}
+
+	f.receiver.statuses = []int{http.StatusNoContent}
+	redelivery, err := f.dispatcher.Redeliver(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	f.dispatcher.DeliverDue(ctx)
+
+	again, _ := f.webhooks.GetDelivery(ctx, redelivery.ID)
+	if again.Status != models.WebhookDeliveryDelivered || string(again.Payload) != string(d.Payload) {
+		t.Errorf("Expected redelivery of the same payload, got %+v", again)
+	}
+	if old, _ := f.webhooks.GetDelivery(ctx, d.ID); old.Status != models.WebhookDeliveryDead {
+		t.Errorf("Expected original delivery to stay dead, got %s", old.Status)
+	}
+}
+
+func TestWebhookDispatcher_UnreachableTarget(t *testing.T) {
+	f := newWebhookFixture(t)
+	ctx := context.Background()
+	w, _ := f.webhooks.Create(ctx, &models.Webhook{URL: "http://127.0.0.1:1", Events: []string{models.WebhookEventAll}, Active: true})
+
+	f.products.Create(ctx, testProduct("Caneta"))
+	f.dispatcher.DeliverDue(ctx)
+
+	d := f.deliveries(t, w.ID)[0]
+	if d.Status != models.WebhookDeliveryRetrying || d.Attempts[0].StatusCode != 0 || d.Attempts[0].Error == "" {
+		t.Errorf("Expected connection error to be retried, got %+v", d)
+	}
+}
+
+func doWebhookRequest(h echo.HandlerFunc, method, target, body string, params ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if len(params) > 0 {
+		c.SetParamNames(params[:len(params)/2]...)
+		c.SetParamValues(params[len(params)/2:]...)
+	}
+	h(c)
+	return rec
+}
+
+func TestWebhookHandlers_Create(t *testing.T) {
+	f := newWebhookFixture(t)
+	h := NewWebhookHandlers(f.webhooks, f.dispatcher)
+
+	invalid := []string{
+		`{"url":"ftp://erp","events":["product.created"]}`,
+		`{"url":"/relative","events":["product.created"]}`,
+		`{"url":"http://erp","events":[]}`,
+		`{"url":"http://erp","events":["order.created"]}`,
+		`{"url":"http://erp","events":["*"],"secret":"curto"}`,
+	}
+	for _, body := range invalid {
+		if rec := doWebhookRequest(h.CreateWebhookHandler, http.MethodPost, "/", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+
+	rec := doWebhookRequest(h.CreateWebhookHandler, http.MethodPost, "/", `{"url":"`+f.url+`","events":["product.created"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Data struct {
+			ID     string `json:"id"`
+			Secret string `json:"secret"`
+			Active bool   `json:"active"`
+		} `json:"data"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &created)
+	if !strings.HasPrefix(created.Data.Secret, "whsec_") || !created.Data.Active {
+		t.Errorf("Expected generated secret and active webhook, got %+v", created.Data)
+	}
+
+	// O segredo só aparece na criação
+	rec = doWebhookRequest(h.GetWebhookHandler, http.MethodGet, "/", "", "id", created.Data.ID)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "secret") {
+		t.Errorf("Expected webhook without secret, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doWebhookRequest(h.UpdateWebhookHandler, http.MethodPut, "/", `{"url":"`+f.url+`","events":["*"],"active":false}`, "id", created.Data.ID)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"active":false`) {
+		t.Errorf("Expected inactive webhook, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doWebhookRequest(h.DeleteWebhookHandler, http.MethodDelete, "/", "", "id", created.Data.ID)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	rec = doWebhookRequest(h.DeleteWebhookHandler, http.MethodDelete, "/", "", "id", created.Data.ID)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestWebhookHandlers_DeliveryLog(t *testing.T) {
+	f := newWebhookFixture(t, http.StatusInternalServerError, http.StatusOK)
+	h := NewWebhookHandlers(f.webhooks, f.dispatcher)
+	w := f.subscribe(t, models.WebhookEventAll)
+	ctx := context.Background()
+
+	rec := doWebhookRequest(h.PingWebhookHandler, http.MethodPost, "/", "", "id", w.ID)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", rec.Code)
+	}
+	f.dispatcher.DeliverDue(ctx)
+
+	rec = doWebhookRequest(h.ListDeliveriesHandler, http.MethodGet, "/?status=retrying", "", "id", w.ID)
+	var resp struct {
+		Data       []models.WebhookDelivery `json:"data"`
+		Pagination api.Pagination           `json:"pagination"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusOK || len(resp.Data) != 1 || resp.Data[0].Event != models.WebhookEventPing || resp.Pagination.Total != 1 {
+		t.Fatalf("Expected the retrying ping, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doWebhookRequest(h.ListDeliveriesHandler, http.MethodGet, "/?status=lost", "", "id", w.ID)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown status, got %d", rec.Code)
+	}
+
+	rec = doWebhookRequest(h.RedeliverHandler, http.MethodPost, "/", "", "id", "delivery_id", "outro", resp.Data[0].ID)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for delivery of another webhook, got %d", rec.Code)
+	}
+	rec = doWebhookRequest(h.RedeliverHandler, http.MethodPost, "/", "", "id", "delivery_id", w.ID, resp.Data[0].ID)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	f.dispatcher.DeliverDue(ctx)
+
+	if _, total := f.webhooks.ListDeliveries(ctx, repository.WebhookDeliveryFilter{WebhookID: w.ID, Status: models.WebhookDeliveryDelivered}, 1, 10); total != 1 {
+		t.Errorf("Expected redelivered ping to succeed, got %d delivered", total)
+	}
+}
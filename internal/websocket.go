@@ -0,0 +1,330 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"echo-playground/internal/chat"
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/metrics"
+	"echo-playground/pkg/middleware"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+// Tipos de mensagem do protocolo JSON de /ws
+const (
+	WSTypeWelcome = "welcome"
+	WSTypePing    = "ping"
+	WSTypePong    = "pong"
+	WSTypeEcho    = "echo"
+	WSTypeWhoAmI  = "whoami"
+	WSTypeTime    = "time"
+	WSTypeError   = "error"
+)
+
+// wsSendBuffer é o número padrão de mensagens aguardando envio por conexão
+const wsSendBuffer = 64
+
+// WSMessage é uma mensagem JSON trocada em /ws. ID é opcional e é repetido
+// na resposta para que o cliente associe pedidos e respostas.
+type WSMessage struct {
+	Type  string          `json:"type"`
+	ID    string          `json:"id,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
+// WSReply é uma mensagem JSON enviada pelo servidor em /ws
+type WSReply struct {
+	Type  string      `json:"type"`
+	ID    string      `json:"id,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// WebSocketHandlers contém o endpoint WebSocket da aplicação
+type WebSocketHandlers struct {
+	cfg      config.WebSocketConfig
+	hub      *chat.Hub
+	upgrader websocket.Upgrader
+	metrics  *metrics.Metrics
+}
+
+// NewWebSocketHandlers cria os handlers WebSocket com os limites informados.
+// As conexões participam das salas de chat do hub.
+func NewWebSocketHandlers(cfg config.WebSocketConfig, hub *chat.Hub) *WebSocketHandlers {
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = wsSendBuffer
+	}
+	h := &WebSocketHandlers{cfg: cfg, hub: hub}
+	h.upgrader = websocket.Upgrader{
+		HandshakeTimeout: cfg.WriteTimeout,
+		CheckOrigin:      h.checkOrigin,
+		Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+			w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(api.NewErrorResponse("Falha no upgrade WebSocket", reason.Error()))
+		},
+	}
+	return h
+}
+
+// SetMetrics passa a contar as conexões WebSocket abertas em m
+func (h *WebSocketHandlers) SetMetrics(m *metrics.Metrics) {
+	h.metrics = m
+}
+
+// WebSocketHandler faz o upgrade de /ws para WebSocket. O token JWT é lido
+// do header Authorization ou, para navegadores, do parâmetro ?token=. Texto
+// que não é um comando JSON e mensagens binárias são devolvidos como eco.
+func (h *WebSocketHandlers) WebSocketHandler(c echo.Context) error {
+	claims, err := wsClaims(c.Request())
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, api.NewErrorResponse("Token inválido", ""))
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// O upgrader já respondeu com o erro
+		return nil
+	}
+
+	client := newWSConn(conn, h.cfg, h.cfg.SendBuffer)
+	defer h.metrics.ConnectionOpened(metrics.ConnectionWebSocket)()
+	go client.writeLoop()
+	member := &wsChatClient{wsConn: client}
+	defer h.hub.LeaveAll(member)
+
+	client.SendJSON(WSReply{Type: WSTypeWelcome, Data: wsIdentity(claims)})
+	client.readLoop(func(kind int, data []byte) {
+		h.handleMessage(member, claims, kind, data)
+	})
+	return nil
+}
+
+// handleMessage responde a uma mensagem recebida
+func (h *WebSocketHandlers) handleMessage(client *wsChatClient, claims *middleware.Claims, kind int, data []byte) {
+	var msg WSMessage
+	if kind != websocket.TextMessage || json.Unmarshal(data, &msg) != nil || msg.Type == "" {
+		client.Send(kind, data)
+		return
+	}
+
+	reply := WSReply{Type: msg.Type, ID: msg.ID}
+	switch msg.Type {
+	case WSTypePing:
+		reply.Type = WSTypePong
+		reply.Data = map[string]interface{}{"time": time.Now().UTC()}
+	case WSTypeEcho:
+		reply.Data = msg.Data
+	case WSTypeWhoAmI:
+		reply.Data = wsIdentity(claims)
+	case WSTypeTime:
+		reply.Data = map[string]interface{}{"time": time.Now().UTC()}
+	case WSTypeJoin, WSTypeLeave, WSTypeSend, WSTypePresence, WSTypeRooms:
+		if !h.handleChat(client, claims, msg, &reply) {
+			return
+		}
+	default:
+		reply.Type = WSTypeError
+		reply.Error = "Comando desconhecido: " + msg.Type
+	}
+	client.SendJSON(reply)
+}
+
+// checkOrigin aceita clientes sem Origin (não navegadores), as origens
+// configuradas e, com a lista vazia, apenas a mesma origem do servidor
+func (h *WebSocketHandlers) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get(echo.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range h.cfg.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	if len(h.cfg.AllowedOrigins) > 0 {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// wsClaims autentica o pedido de upgrade. Navegadores não permitem headers
+// customizados no handshake, por isso o token também é aceito em ?token=.
+func wsClaims(r *http.Request) (*middleware.Claims, error) {
+	token := r.URL.Query().Get("token")
+	if header := r.Header.Get(echo.HeaderAuthorization); header != "" {
+		if !strings.HasPrefix(header, "Bearer ") {
+			return nil, errors.New("token inválido")
+		}
+		token = strings.TrimPrefix(header, "Bearer ")
+	}
+	if token == "" {
+		return nil, errors.New("token não fornecido")
+	}
+	return middleware.ParseToken(token)
+}
+
+// wsIdentity descreve o usuário autenticado na conexão
+func wsIdentity(claims *middleware.Claims) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":  claims.UserID,
+		"username": claims.Username,
+		"role":     claims.Role,
+	}
+}
+
+// wsFrame é uma mensagem aguardando envio
+type wsFrame struct {
+	kind int
+	data []byte
+}
+
+// wsConn envolve uma conexão WebSocket. Toda escrita passa por writeLoop,
+// já que a conexão aceita apenas um escritor por vez; o ping periódico e os
+// prazos de leitura encerram conexões cujo cliente parou de responder.
+type wsConn struct {
+	conn *websocket.Conn
+	cfg  config.WebSocketConfig
+	send chan wsFrame
+
+	done        chan struct{}
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+// newWSConn cria a conexão com uma fila de envio de buffer mensagens
+func newWSConn(conn *websocket.Conn, cfg config.WebSocketConfig, buffer int) *wsConn {
+	return &wsConn{
+		conn: conn,
+		cfg:  cfg,
+		send: make(chan wsFrame, buffer),
+		done: make(chan struct{}),
+	}
+}
+
+// Send enfileira uma mensagem. Retorna false se a conexão foi encerrada ou
+// se a fila está cheia, caso em que a mensagem é descartada.
+func (w *wsConn) Send(kind int, data []byte) bool {
+	select {
+	case <-w.done:
+		return false
+	default:
+	}
+	select {
+	case w.send <- wsFrame{kind: kind, data: data}:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendJSON enfileira v codificado como mensagem de texto JSON
+func (w *wsConn) SendJSON(v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	return w.Send(websocket.TextMessage, data)
+}
+
+// Close encerra a conexão normalmente; pode ser chamado mais de uma vez
+func (w *wsConn) Close() {
+	w.CloseWith(websocket.CloseNormalClosure, "")
+}
+
+// CloseWith encerra a conexão enviando o código e o motivo informados no
+// frame de fechamento. Apenas a primeira chamada tem efeito.
+func (w *wsConn) CloseWith(code int, reason string) {
+	w.closeOnce.Do(func() {
+		w.closeCode = code
+		w.closeReason = reason
+		close(w.done)
+	})
+}
+
+// Done é fechado quando a conexão é encerrada
+func (w *wsConn) Done() <-chan struct{} {
+	return w.done
+}
+
+// readLoop entrega as mensagens recebidas a handle até a conexão ser
+// encerrada. Cada pong ou mensagem renova o prazo de leitura.
+func (w *wsConn) readLoop(handle func(kind int, data []byte)) {
+	defer w.Close()
+
+	if w.cfg.MaxMessageSize > 0 {
+		w.conn.SetReadLimit(int64(w.cfg.MaxMessageSize))
+	}
+	w.extendReadDeadline()
+	w.conn.SetPongHandler(func(string) error {
+		w.extendReadDeadline()
+		return nil
+	})
+
+	for {
+		kind, data, err := w.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		w.extendReadDeadline()
+		handle(kind, data)
+	}
+}
+
+// extendReadDeadline renova o prazo para o cliente dar sinal de vida
+func (w *wsConn) extendReadDeadline() {
+	if w.cfg.PongTimeout > 0 {
+		w.conn.SetReadDeadline(time.Now().Add(w.cfg.PongTimeout))
+	}
+}
+
+// writeLoop envia as mensagens enfileiradas e os pings periódicos. Ao
+// encerrar, envia o frame de fechamento e libera a conexão.
+func (w *wsConn) writeLoop() {
+	var ping <-chan time.Time
+	if w.cfg.PingInterval > 0 {
+		ticker := time.NewTicker(w.cfg.PingInterval)
+		defer ticker.Stop()
+		ping = ticker.C
+	}
+	defer w.conn.Close()
+	defer w.Close()
+
+	for {
+		select {
+		case frame := <-w.send:
+			w.extendWriteDeadline()
+			if err := w.conn.WriteMessage(frame.kind, frame.data); err != nil {
+				return
+			}
+		case <-ping:
+			w.extendWriteDeadline()
+			if err := w.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-w.done:
+			w.extendWriteDeadline()
+			w.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(w.closeCode, w.closeReason))
+			return
+		}
+	}
+}
+
+// extendWriteDeadline limita o tempo de cada escrita
+func (w *wsConn) extendWriteDeadline() {
+	if w.cfg.WriteTimeout > 0 {
+		w.conn.SetWriteDeadline(time.Now().Add(w.cfg.WriteTimeout))
+	}
+}
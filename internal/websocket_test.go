@@ -0,0 +1,263 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"echo-playground/internal/chat"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/middleware"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+func newWebSocketTestServer(t *testing.T, cfg config.WebSocketConfig) string {
+	t.Helper()
+	e := echo.New()
+	e.GET("/ws", NewWebSocketHandlers(cfg, chat.NewHub(10)).WebSocketHandler)
+	server := httptest.NewServer(e)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func testWebSocketConfig() config.WebSocketConfig {
+	return config.WebSocketConfig{
+		AllowedOrigins: []string{"http://app.exemplo.com"},
+		MaxMessageSize: config.Kilobyte,
+		PingInterval:   time.Second,
+		PongTimeout:    2 * time.Second,
+		WriteTimeout:   time.Second,
+	}
+}
+
+func dialWebSocket(t *testing.T, url string, header http.Header) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("Failed to dial websocket (status %d): %v", status, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	if reply := readReply(t, conn); reply.Type != WSTypeWelcome {
+		t.Fatalf("Expected welcome message, got %+v", reply)
+	}
+	return conn
+}
+
+func readReply(t *testing.T, conn *websocket.Conn) WSMessage {
+	t.Helper()
+	kind, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	if kind != websocket.TextMessage {
+		t.Fatalf("Expected text message, got %d", kind)
+	}
+	var reply WSMessage
+	if err := json.Unmarshal(data, &reply); err != nil {
+		t.Fatalf("Failed to decode %q: %v", data, err)
+	}
+	return reply
+}
+
+func authHeader() http.Header {
+	return http.Header{"Authorization": {"Bearer " + middleware.DemoToken}}
+}
+
+func TestWebSocket_Authentication(t *testing.T) {
+	url := newWebSocketTestServer(t, testWebSocketConfig())
+
+	tests := []struct {
+		name   string
+		url    string
+		header http.Header
+		status int
+	}{
+		{"no token", url, nil, http.StatusUnauthorized},
+		{"invalid token", url, http.Header{"Authorization": {"Bearer invalido"}}, http.StatusUnauthorized},
+		{"malformed header", url, http.Header{"Authorization": {middleware.DemoToken}}, http.StatusUnauthorized},
+		{"header token", url, authHeader(), http.StatusSwitchingProtocols},
+		{"query token", url + "?token=" + middleware.DemoToken, nil, http.StatusSwitchingProtocols},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, resp, err := websocket.DefaultDialer.Dial(tt.url, tt.header)
+			if conn != nil {
+				conn.Close()
+			}
+			if resp == nil {
+				t.Fatalf("Expected a response, got error %v", err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestWebSocket_CheckOrigin(t *testing.T) {
+	url := newWebSocketTestServer(t, testWebSocketConfig())
+
+	header := authHeader()
+	header.Set("Origin", "http://app.exemplo.com")
+	dialWebSocket(t, url, header)
+
+	header.Set("Origin", "http://malicioso.exemplo.com")
+	_, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected status 403 for foreign origin, got %v", resp)
+	}
+
+	h := NewWebSocketHandlers(config.WebSocketConfig{}, chat.NewHub(10))
+	req := httptest.NewRequest(http.MethodGet, "http://api.exemplo.com/ws", nil)
+	req.Header.Set("Origin", "http://api.exemplo.com")
+	if !h.checkOrigin(req) {
+		t.Error("Expected same origin to be accepted when no origins are configured")
+	}
+	req.Header.Set("Origin", "http://outro.exemplo.com")
+	if h.checkOrigin(req) {
+		t.Error("Expected foreign origin to be rejected when no origins are configured")
+	}
+}
+
+func TestWebSocket_Echo(t *testing.T) {
+	conn := dialWebSocket(t, newWebSocketTestServer(t, testWebSocketConfig()), authHeader())
+
+	for _, msg := range []struct {
+		kind int
+		data string
+	}{
+		{websocket.TextMessage, "olá"},
+		{websocket.TextMessage, `{"sem":"tipo"}`},
+		{websocket.BinaryMessage, "\x00\x01\x02"},
+	} {
+		if err := conn.WriteMessage(msg.kind, []byte(msg.data)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		if kind != msg.kind || string(data) != msg.data {
+			t.Errorf("Expected echo %q (%d), got %q (%d)", msg.data, msg.kind, data, kind)
+		}
+	}
+}
+
+func TestWebSocket_Commands(t *testing.T) {
+	conn := dialWebSocket(t, newWebSocketTestServer(t, testWebSocketConfig()), authHeader())
+
+	tests := []struct {
+		request  string
+		wantType string
+		check    func(t *testing.T, reply WSMessage)
+	}{
+		{`{"type":"ping","id":"1"}`, WSTypePong, func(t *testing.T, reply WSMessage) {
+			if reply.ID != "1" {
+				t.Errorf("Expected id 1, got %q", reply.ID)
+			}
+		}},
+		{`{"type":"echo","data":{"a":[1,2]}}`, WSTypeEcho, func(t *testing.T, reply WSMessage) {
+			if string(reply.Data) != `{"a":[1,2]}` {
+				t.Errorf("Expected echoed data, got %s", reply.Data)
+			}
+		}},
+		{`{"type":"whoami"}`, WSTypeWhoAmI, func(t *testing.T, reply WSMessage) {
+			var identity struct {
+				UserID int `json:"user_id"`
+			}
+			json.Unmarshal(reply.Data, &identity)
+			if identity.UserID != middleware.DemoUserID {
+				t.Errorf("Expected user %d, got %d", middleware.DemoUserID, identity.UserID)
+			}
+		}},
+		{`{"type":"time"}`, WSTypeTime, nil},
+		{`{"type":"desconhecido","id":"x"}`, WSTypeError, func(t *testing.T, reply WSMessage) {
+			if reply.ID != "x" || reply.Error == "" {
+				t.Errorf("Expected error with id x, got %+v", reply)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(tt.request)); err != nil {
+			t.Fatalf("Failed to write: %v", err)
+		}
+		reply := readReply(t, conn)
+		if reply.Type != tt.wantType {
+			t.Errorf("Expected type %q for %s, got %q", tt.wantType, tt.request, reply.Type)
+			continue
+		}
+		if tt.check != nil {
+			tt.check(t, reply)
+		}
+	}
+}
+
+func TestWebSocket_MessageTooBig(t *testing.T) {
+	conn := dialWebSocket(t, newWebSocketTestServer(t, testWebSocketConfig()), authHeader())
+
+	if err := conn.WriteMessage(websocket.TextMessage, make([]byte, 2*config.Kilobyte)); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Errorf("Expected close 1009, got %v", err)
+	}
+}
+
+func TestWebSocket_Keepalive(t *testing.T) {
+	cfg := testWebSocketConfig()
+	cfg.PingInterval = 20 * time.Millisecond
+	cfg.PongTimeout = 100 * time.Millisecond
+	url := newWebSocketTestServer(t, cfg)
+
+	t.Run("client answers pings", func(t *testing.T) {
+		conn := dialWebSocket(t, url, authHeader())
+		pings := 0
+		conn.SetPingHandler(func(data string) error {
+			pings++
+			return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+
+		// Sem mensagens por 3x o prazo de pong: a leitura expira no cliente,
+		// mas o servidor mantém a conexão
+		conn.SetReadDeadline(time.Now().Add(3 * cfg.PongTimeout))
+		_, _, err := conn.ReadMessage()
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Errorf("Expected client read timeout, got %v", err)
+		}
+		if pings == 0 {
+			t.Error("Expected server pings")
+		}
+	})
+
+	t.Run("client ignores pings", func(t *testing.T) {
+		conn := dialWebSocket(t, url, authHeader())
+		conn.SetPingHandler(func(string) error { return nil })
+
+		start := time.Now()
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			t.Fatal("Expected connection to be closed")
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			t.Errorf("Expected server to close the connection, got client timeout after %v", time.Since(start))
+		}
+	})
+}
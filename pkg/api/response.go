@@ -2,10 +2,34 @@ package api
 
 // Response representa uma resposta padrão da API
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	RequestID  string      `json:"request_id,omitempty"`
+}
+
+// Pagination descreve a página retornada em uma listagem paginada
+type Pagination struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// NewPagination calcula os metadados de paginação
+func NewPagination(page, perPage, total int) *Pagination {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+	return &Pagination{
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}
 }
 
 // NewSuccessResponse cria uma resposta de sucesso
@@ -17,6 +41,16 @@ func NewSuccessResponse(message string, data interface{}) *Response {
 	}
 }
 
+// NewPaginatedResponse cria uma resposta de sucesso com metadados de paginação
+func NewPaginatedResponse(message string, data interface{}, pagination *Pagination) *Response {
+	return &Response{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		Pagination: pagination,
+	}
+}
+
 // NewErrorResponse cria uma resposta de erro
 func NewErrorResponse(message, error string) *Response {
 	return &Response{
@@ -109,3 +109,40 @@ func TestResponseJSONSerialization(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPagination(t *testing.T) {
+	tests := []struct {
+		page, perPage, total int
+		expectedPages        int
+	}{
+		{1, 10, 0, 0},
+		{1, 10, 10, 1},
+		{2, 10, 11, 2},
+		{1, 3, 10, 4},
+	}
+
+	for _, tt := range tests {
+		p := NewPagination(tt.page, tt.perPage, tt.total)
+		if p.TotalPages != tt.expectedPages {
+			t.Errorf("Expected %d pages for total %d/%d, got %d", tt.expectedPages, tt.total, tt.perPage, p.TotalPages)
+		}
+	}
+}
+
+func TestNewPaginatedResponse(t *testing.T) {
+	response := NewPaginatedResponse("Listagem", []string{"a"}, NewPagination(1, 10, 1))
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if _, ok := decoded["pagination"]; !ok {
+		t.Error("Expected pagination field in JSON")
+	}
+}
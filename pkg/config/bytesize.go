@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ByteSize é um tamanho em bytes que aceita sufixos como "512KB" e "10MB"
+// (múltiplos de 1024) no arquivo de configuração
+type ByteSize int64
+
+// Unidades reconhecidas por ParseByteSize
+const (
+	Byte     ByteSize = 1
+	Kilobyte          = 1024 * Byte
+	Megabyte          = 1024 * Kilobyte
+	Gigabyte          = 1024 * Megabyte
+)
+
+// ParseByteSize interpreta um tamanho como "10MB", "1.5GB" ou "2048"
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+
+	unit := Byte
+	for _, u := range []struct {
+		suffix string
+		size   ByteSize
+	}{{"GB", Gigabyte}, {"MB", Megabyte}, {"KB", Kilobyte}, {"B", Byte}} {
+		if strings.HasSuffix(value, u.suffix) {
+			unit = u.size
+			value = strings.TrimSpace(strings.TrimSuffix(value, u.suffix))
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("tamanho inválido: %q", s)
+	}
+	return ByteSize(n * float64(unit)), nil
+}
+
+// UnmarshalYAML implementa yaml.Unmarshaler
+func (b *ByteSize) UnmarshalYAML(node *yaml.Node) error {
+	size, err := ParseByteSize(node.Value)
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+// String formata o tamanho na maior unidade exata
+func (b ByteSize) String() string {
+	switch {
+	case b >= Gigabyte && b%Gigabyte == 0:
+		return fmt.Sprintf("%dGB", b/Gigabyte)
+	case b >= Megabyte && b%Megabyte == 0:
+		return fmt.Sprintf("%dMB", b/Megabyte)
+	case b >= Kilobyte && b%Kilobyte == 0:
+		return fmt.Sprintf("%dKB", b/Kilobyte)
+	}
+	return fmt.Sprintf("%dB", int64(b))
+}
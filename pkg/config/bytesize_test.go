@@ -0,0 +1,42 @@
+package config
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ByteSize
+	}{
+		{"10MB", 10 * Megabyte},
+		{"512kb", 512 * Kilobyte},
+		{"1.5GB", Gigabyte + 512*Megabyte},
+		{"2048", 2048},
+		{" 100 B ", 100},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.input)
+		if err != nil {
+			t.Errorf("ParseByteSize(%q): expected no error, got %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseByteSize(%q): expected %d, got %d", tt.input, tt.expected, got)
+		}
+	}
+
+	for _, invalid := range []string{"", "MB", "-1KB", "dez"} {
+		if _, err := ParseByteSize(invalid); err == nil {
+			t.Errorf("ParseByteSize(%q): expected error", invalid)
+		}
+	}
+}
+
+func TestByteSize_String(t *testing.T) {
+	if got := (10 * Megabyte).String(); got != "10MB" {
+		t.Errorf("Expected 10MB, got %s", got)
+	}
+	if got := ByteSize(1500).String(); got != "1500B" {
+		t.Errorf("Expected 1500B, got %s", got)
+	}
+}
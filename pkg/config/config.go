@@ -0,0 +1,224 @@
+// Package config carrega a configuração da aplicação a partir de um arquivo YAML.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config representa o conteúdo de config/config.yaml
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Logging   LoggingConfig   `yaml:"logging"`
+	API       APIConfig       `yaml:"api"`
+	Upload    UploadConfig    `yaml:"upload"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Currency  CurrencyConfig  `yaml:"currency"`
+	WebSocket WebSocketConfig `yaml:"websocket"`
+	Webhooks  WebhookConfig   `yaml:"webhooks"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
+
+// ServerConfig contém as configurações do servidor HTTP
+type ServerConfig struct {
+	Port         int           `yaml:"port"`
+	Host         string        `yaml:"host"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+}
+
+// LoggingConfig contém as configurações de log. Level é "debug", "info",
+// "warn" ou "error"; Format é "json" ou "text".
+type LoggingConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+}
+
+// APIConfig contém as configurações gerais da API
+type APIConfig struct {
+	Version     string `yaml:"version"`
+	Prefix      string `yaml:"prefix"`
+	DocsEnabled bool   `yaml:"docs_enabled"`
+}
+
+// UploadConfig contém os limites e o destino dos arquivos enviados.
+// AllowedTypes lista as extensões aceitas, sem o ponto; MaxFiles limita os
+// arquivos por requisição multipart; UserQuota limita o total enviado por
+// usuário (zero desativa a cota); ThumbnailSizes são os lados, em pixels,
+// das miniaturas geradas para imagens; SigningKey assina os links de
+// download temporários; TusExpiration é a validade de um upload resumível
+// sem atividade; Scan configura a verificação de malware.
+type UploadConfig struct {
+	MaxSize        ByteSize      `yaml:"max_size"`
+	MaxFiles       int           `yaml:"max_files"`
+	UserQuota      ByteSize      `yaml:"user_quota"`
+	AllowedTypes   []string      `yaml:"allowed_types"`
+	Directory      string        `yaml:"directory"`
+	ThumbnailSizes []int         `yaml:"thumbnail_sizes"`
+	SigningKey     string        `yaml:"signing_key"`
+	Scan           ScanConfig    `yaml:"scan"`
+	TusExpiration  time.Duration `yaml:"tus_expiration"`
+}
+
+// ScanConfig seleciona o scanner de malware executado após cada upload:
+// "eicar" detecta apenas o arquivo de teste EICAR, "command" executa um
+// antivírus externo e vazio desativa a verificação. OnInfected define o
+// destino de arquivos infectados: "flag" os mantém bloqueados no catálogo e
+// "reject" os remove.
+type ScanConfig struct {
+	Driver     string        `yaml:"driver"`
+	Command    []string      `yaml:"command"`
+	Timeout    time.Duration `yaml:"timeout"`
+	OnInfected string        `yaml:"on_infected"`
+}
+
+// WebSocketConfig contém os limites das conexões WebSocket. AllowedOrigins
+// lista as origens aceitas no upgrade ("*" aceita qualquer uma; vazio aceita
+// apenas a mesma origem do servidor); PingInterval é o intervalo entre pings
+// e PongTimeout o tempo máximo sem resposta antes de encerrar a conexão.
+// SendBuffer limita as mensagens aguardando envio a cada cliente, que é
+// desconectado quando a fila enche; ChatHistory é o número de mensagens
+// recentes de cada sala de chat entregues ao entrar.
+type WebSocketConfig struct {
+	AllowedOrigins []string      `yaml:"allowed_origins"`
+	MaxMessageSize ByteSize      `yaml:"max_message_size"`
+	PingInterval   time.Duration `yaml:"ping_interval"`
+	PongTimeout    time.Duration `yaml:"pong_timeout"`
+	WriteTimeout   time.Duration `yaml:"write_timeout"`
+	SendBuffer     int           `yaml:"send_buffer"`
+	ChatHistory    int           `yaml:"chat_history"`
+}
+
+// WebhookConfig contém os limites das entregas de webhooks. Timeout é o
+// prazo de cada requisição; uma entrega que falha é tentada de novo após
+// RetryBaseDelay, dobrando a espera a cada falha até RetryMaxDelay, e vai
+// para o estado "dead" depois de MaxAttempts tentativas.
+type WebhookConfig struct {
+	Timeout        time.Duration `yaml:"timeout"`
+	MaxAttempts    int           `yaml:"max_attempts"`
+	RetryBaseDelay time.Duration `yaml:"retry_base_delay"`
+	RetryMaxDelay  time.Duration `yaml:"retry_max_delay"`
+}
+
+// TracingConfig seleciona o exportador dos traces: "stdout" escreve os spans
+// em JSON na saída padrão, "otlp" os envia por OTLP/HTTP a um coletor em
+// Endpoint (host:porta; vazio usa OTEL_EXPORTER_OTLP_ENDPOINT ou
+// localhost:4318) e vazio desativa o tracing. Insecure usa HTTP sem TLS;
+// SampleRatio é a fração das novas traces registradas, de 0 a 1.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"`
+	Endpoint    string  `yaml:"endpoint"`
+	Insecure    bool    `yaml:"insecure"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// StorageConfig seleciona o driver de armazenamento dos arquivos enviados:
+// "local" grava em Upload.Directory e "s3" usa um bucket compatível com S3
+type StorageConfig struct {
+	Driver string   `yaml:"driver"`
+	S3     S3Config `yaml:"s3"`
+}
+
+// S3Config contém os dados de acesso ao bucket S3
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"access_key"`
+	SecretKey string `yaml:"secret_key"`
+}
+
+// CurrencyConfig contém a moeda base dos preços e a tabela de cotações,
+// expressa em unidades de cada moeda por uma unidade da moeda base
+type CurrencyConfig struct {
+	Base  string            `yaml:"base"`
+	Rates map[string]string `yaml:"rates"`
+}
+
+// Default retorna a configuração padrão da aplicação
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:         8080,
+			Host:         "0.0.0.0",
+			ReadTimeout:  30 * time.Second,
+			WriteTimeout: 30 * time.Second,
+			IdleTimeout:  120 * time.Second,
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+		},
+		API: APIConfig{
+			Version:     "v1",
+			Prefix:      "/api/v1",
+			DocsEnabled: true,
+		},
+		Upload: UploadConfig{
+			MaxSize:        10 * Megabyte,
+			MaxFiles:       10,
+			UserQuota:      100 * Megabyte,
+			AllowedTypes:   []string{"jpg", "jpeg", "png", "gif", "pdf", "txt"},
+			Directory:      "uploads",
+			ThumbnailSizes: []int{64, 256, 512},
+			TusExpiration:  24 * time.Hour,
+			Scan: ScanConfig{
+				Timeout:    30 * time.Second,
+				OnInfected: "flag",
+			},
+		},
+		Storage: StorageConfig{
+			Driver: "local",
+			S3:     S3Config{Region: "us-east-1"},
+		},
+		Currency: CurrencyConfig{
+			Base:  "BRL",
+			Rates: map[string]string{},
+		},
+		WebSocket: WebSocketConfig{
+			MaxMessageSize: 64 * Kilobyte,
+			PingInterval:   30 * time.Second,
+			PongTimeout:    60 * time.Second,
+			WriteTimeout:   10 * time.Second,
+			SendBuffer:     64,
+			ChatHistory:    50,
+		},
+		Webhooks: WebhookConfig{
+			Timeout:        10 * time.Second,
+			MaxAttempts:    6,
+			RetryBaseDelay: 30 * time.Second,
+			RetryMaxDelay:  time.Hour,
+		},
+		Tracing: TracingConfig{
+			ServiceName: "echo-playground",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load lê o arquivo YAML informado sobre a configuração padrão.
+// Se o arquivo não existir, a configuração padrão é retornada.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler configuração: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar configuração: %w", err)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+server:
+  port: 9090
+  read_timeout: 5s
+upload:
+  max_size: "2MB"
+  allowed_types: ["png"]
+currency:
+  base: "BRL"
+  rates:
+    USD: 0.18
+    EUR: "0.17"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != 9090 {
+		t.Errorf("Expected port 9090, got %d", cfg.Server.Port)
+	}
+
+	if cfg.Server.ReadTimeout != 5*time.Second {
+		t.Errorf("Expected read timeout 5s, got %v", cfg.Server.ReadTimeout)
+	}
+
+	if cfg.Server.IdleTimeout != 120*time.Second {
+		t.Errorf("Expected default idle timeout, got %v", cfg.Server.IdleTimeout)
+	}
+
+	if cfg.Upload.MaxSize != 2*Megabyte {
+		t.Errorf("Expected max upload size 2MB, got %s", cfg.Upload.MaxSize)
+	}
+
+	if len(cfg.Upload.AllowedTypes) != 1 || cfg.Upload.Directory != "uploads" {
+		t.Errorf("Unexpected upload config: %+v", cfg.Upload)
+	}
+
+	if cfg.Currency.Rates["USD"] != "0.18" || cfg.Currency.Rates["EUR"] != "0.17" {
+		t.Errorf("Unexpected rates: %v", cfg.Currency.Rates)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Server.Port != 8080 {
+		t.Errorf("Expected default port 8080, got %d", cfg.Server.Port)
+	}
+}
+
+func TestLoad_ProjectConfig(t *testing.T) {
+	if _, err := Load("../../config/config.yaml"); err != nil {
+		t.Errorf("Expected project config to load, got %v", err)
+	}
+}
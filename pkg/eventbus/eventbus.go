@@ -0,0 +1,227 @@
+// Package eventbus implementa um barramento de eventos em processo.
+// Os eventos são tipados: um assinante de E recebe apenas eventos do tipo E.
+// Assinantes síncronos rodam durante Publish; assíncronos têm uma fila e uma
+// goroutine próprias. Erros e panics de um assinante são entregues ao
+// OnError e nunca chegam a quem publicou.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// DefaultQueueSize é o tamanho padrão da fila de um assinante assíncrono
+const DefaultQueueSize = 256
+
+// ErrQueueFull indica que um evento foi descartado porque a fila do
+// assinante assíncrono estava cheia
+var ErrQueueFull = errors.New("fila do assinante cheia")
+
+// SubscriberError descreve a falha de um assinante ao tratar um evento
+type SubscriberError struct {
+	Subscriber string
+	Event      string
+	Err        error
+}
+
+func (e *SubscriberError) Error() string {
+	return fmt.Sprintf("assinante %s falhou ao tratar %s: %v", e.Subscriber, e.Event, e.Err)
+}
+
+func (e *SubscriberError) Unwrap() error {
+	return e.Err
+}
+
+// Options configura o barramento. OnError recebe as falhas dos assinantes
+// (por padrão, registradas no log); QueueSize é o tamanho da fila de cada
+// assinante assíncrono.
+type Options struct {
+	OnError   func(err error)
+	QueueSize int
+}
+
+// delivery é um evento aguardando um assinante assíncrono
+type delivery struct {
+	ctx   context.Context
+	event interface{}
+}
+
+// subscriber é uma assinatura de um tipo de evento
+type subscriber struct {
+	name   string
+	handle func(context.Context, interface{}) error
+	queue  chan delivery // nil para assinantes síncronos
+}
+
+// Bus distribui eventos aos assinantes de cada tipo
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[reflect.Type][]*subscriber
+	closed      bool
+	wg          sync.WaitGroup
+	onError     func(err error)
+	queueSize   int
+}
+
+// New cria um barramento
+func New(opts Options) *Bus {
+	if opts.OnError == nil {
+		opts.OnError = func(err error) { log.Printf("eventbus: %v", err) }
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultQueueSize
+	}
+	return &Bus{
+		subscribers: make(map[reflect.Type][]*subscriber),
+		onError:     opts.OnError,
+		queueSize:   opts.QueueSize,
+	}
+}
+
+// Subscribe registra fn para receber os eventos do tipo E durante o
+// Publish, na goroutine de quem publicou. fn deve ser rápida; para trabalho
+// lento ou com I/O use SubscribeAsync. Retorna a função que cancela a
+// assinatura.
+func Subscribe[E any](b *Bus, name string, fn func(context.Context, E) error) func() {
+	return b.subscribe(typeOf[E](), &subscriber{name: name, handle: handler(fn)})
+}
+
+// SubscribeAsync registra fn para receber os eventos do tipo E em uma
+// goroutine própria, na ordem de publicação. O contexto entregue mantém os
+// valores do contexto de Publish, mas não é cancelado com ele. Se a fila
+// enche, novos eventos são descartados e reportados com ErrQueueFull.
+func SubscribeAsync[E any](b *Bus, name string, fn func(context.Context, E) error) func() {
+	sub := &subscriber{name: name, handle: handler(fn), queue: make(chan delivery, b.queueSize)}
+	return b.subscribe(typeOf[E](), sub)
+}
+
+// Publish entrega o evento aos assinantes do seu tipo. Nunca falha: erros
+// dos assinantes vão para OnError. Um barramento nil ou fechado ignora o
+// evento.
+func Publish[E any](ctx context.Context, b *Bus, event E) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return
+	}
+	subs := b.subscribers[typeOf[E]()]
+	var direct []*subscriber
+	for _, sub := range subs {
+		if sub.queue == nil {
+			direct = append(direct, sub)
+			continue
+		}
+		select {
+		case sub.queue <- delivery{ctx: context.WithoutCancel(ctx), event: event}:
+		default:
+			b.onError(&SubscriberError{Subscriber: sub.name, Event: eventName(event), Err: ErrQueueFull})
+		}
+	}
+	b.mu.RUnlock()
+
+	// Fora do lock, para que um assinante possa publicar ou assinar
+	for _, sub := range direct {
+		b.deliver(sub, ctx, event)
+	}
+}
+
+// Close encerra o barramento: novos eventos são ignorados e Close espera
+// os assinantes assíncronos tratarem os eventos já enfileirados
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, subs := range b.subscribers {
+			for _, sub := range subs {
+				if sub.queue != nil {
+					close(sub.queue)
+				}
+			}
+		}
+		b.subscribers = make(map[reflect.Type][]*subscriber)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+// subscribe adiciona a assinatura e retorna a função que a remove
+func (b *Bus) subscribe(t reflect.Type, sub *subscriber) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return func() {}
+	}
+	b.subscribers[t] = append(b.subscribers[t], sub)
+
+	if sub.queue != nil {
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			for d := range sub.queue {
+				b.deliver(sub, d.ctx, d.event)
+			}
+		}()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.unsubscribe(t, sub) })
+	}
+}
+
+// unsubscribe remove a assinatura; a fila de um assinante assíncrono é
+// fechada e os eventos já enfileirados ainda são tratados
+func (b *Bus) unsubscribe(t reflect.Type, sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	subs := b.subscribers[t]
+	for i, s := range subs {
+		if s == sub {
+			b.subscribers[t] = append(subs[:i:i], subs[i+1:]...)
+			if sub.queue != nil {
+				close(sub.queue)
+			}
+			return
+		}
+	}
+}
+
+// deliver chama o assinante, convertendo erros e panics em SubscriberError
+func (b *Bus) deliver(sub *subscriber, ctx context.Context, event interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.onError(&SubscriberError{Subscriber: sub.name, Event: eventName(event), Err: fmt.Errorf("panic: %v", r)})
+		}
+	}()
+	if err := sub.handle(ctx, event); err != nil {
+		b.onError(&SubscriberError{Subscriber: sub.name, Event: eventName(event), Err: err})
+	}
+}
+
+// handler adapta uma função tipada para a assinatura interna
+func handler[E any](fn func(context.Context, E) error) func(context.Context, interface{}) error {
+	return func(ctx context.Context, event interface{}) error {
+		return fn(ctx, event.(E))
+	}
+}
+
+// typeOf retorna a chave das assinaturas de E
+func typeOf[E any]() reflect.Type {
+	return reflect.TypeOf((*E)(nil)).Elem()
+}
+
+// eventName identifica o tipo do evento nas mensagens de erro
+func eventName(event interface{}) string {
+	return fmt.Sprintf("%T", event)
+}
@@ -0,0 +1,207 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type orderPlaced struct{ ID int }
+
+type userCreated struct{ Name string }
+
+// errorRecorder guarda as falhas reportadas pelo barramento
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) all() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func newTestBus(queueSize int) (*Bus, *errorRecorder) {
+	rec := &errorRecorder{}
+	return New(Options{OnError: rec.record, QueueSize: queueSize}), rec
+}
+
+func TestBus_TypedSyncDelivery(t *testing.T) {
+	bus, _ := newTestBus(0)
+
+	var orders []int
+	var users []string
+	Subscribe(bus, "orders", func(ctx context.Context, e orderPlaced) error {
+		orders = append(orders, e.ID)
+		return nil
+	})
+	Subscribe(bus, "users", func(ctx context.Context, e userCreated) error {
+		users = append(users, e.Name)
+		return nil
+	})
+
+	Publish(context.Background(), bus, orderPlaced{ID: 1})
+	Publish(context.Background(), bus, userCreated{Name: "ana"})
+	Publish(context.Background(), bus, orderPlaced{ID: 2})
+
+	if len(orders) != 2 || orders[0] != 1 || orders[1] != 2 {
+		t.Errorf("Expected orders [1 2], got %v", orders)
+	}
+	if len(users) != 1 || users[0] != "ana" {
+		t.Errorf("Expected users [ana], got %v", users)
+	}
+}
+
+func TestBus_ErrorIsolation(t *testing.T) {
+	bus, rec := newTestBus(0)
+
+	delivered := 0
+	Subscribe(bus, "falha", func(ctx context.Context, e orderPlaced) error {
+		return errors.New("indisponível")
+	})
+	Subscribe(bus, "panico", func(ctx context.Context, e orderPlaced) error {
+		panic("inesperado")
+	})
+	Subscribe(bus, "ok", func(ctx context.Context, e orderPlaced) error {
+		delivered++
+		return nil
+	})
+
+	Publish(context.Background(), bus, orderPlaced{ID: 1})
+
+	if delivered != 1 {
+		t.Errorf("Expected healthy subscriber to receive the event, got %d", delivered)
+	}
+	errs := rec.all()
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 reported errors, got %v", errs)
+	}
+	var subErr *SubscriberError
+	if !errors.As(errs[0], &subErr) || subErr.Subscriber != "falha" || subErr.Event != "eventbus.orderPlaced" {
+		t.Errorf("Unexpected first error: %v", errs[0])
+	}
+	if !errors.As(errs[1], &subErr) || subErr.Subscriber != "panico" {
+		t.Errorf("Expected recovered panic, got %v", errs[1])
+	}
+}
+
+func TestBus_AsyncDelivery(t *testing.T) {
+	bus, _ := newTestBus(0)
+
+	type key struct{}
+	var got []int
+	var values []interface{}
+	release := make(chan struct{})
+	SubscribeAsync(bus, "async", func(ctx context.Context, e orderPlaced) error {
+		<-release
+		if ctx.Err() != nil {
+			t.Errorf("Expected async context not to be cancelled, got %v", ctx.Err())
+		}
+		got = append(got, e.ID)
+		values = append(values, ctx.Value(key{}))
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "req-1"))
+	for i := 1; i <= 3; i++ {
+		Publish(ctx, bus, orderPlaced{ID: i})
+	}
+	// Publish não espera o assinante assíncrono
+	cancel()
+	close(release)
+	bus.Close()
+
+	if len(got) != 3 || got[0] != 1 || got[2] != 3 {
+		t.Errorf("Expected events in order after Close, got %v", got)
+	}
+	if values[0] != "req-1" {
+		t.Errorf("Expected context values to be kept, got %v", values[0])
+	}
+
+	Publish(context.Background(), bus, orderPlaced{ID: 4})
+	if len(got) != 3 {
+		t.Errorf("Expected closed bus to ignore events, got %v", got)
+	}
+}
+
+func TestBus_AsyncQueueFull(t *testing.T) {
+	bus, rec := newTestBus(1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	SubscribeAsync(bus, "lento", func(ctx context.Context, e orderPlaced) error {
+		if e.ID == 1 {
+			close(started)
+		}
+		<-block
+		return nil
+	})
+
+	Publish(context.Background(), bus, orderPlaced{ID: 1})
+	<-started
+	Publish(context.Background(), bus, orderPlaced{ID: 2}) // ocupa a fila
+	Publish(context.Background(), bus, orderPlaced{ID: 3}) // descartado
+
+	errs := rec.all()
+	if len(errs) != 1 || !errors.Is(errs[0], ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", errs)
+	}
+	close(block)
+	bus.Close()
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	bus, _ := newTestBus(0)
+
+	syncCount, asyncCount := 0, 0
+	var mu sync.Mutex
+	unsubscribe := Subscribe(bus, "sync", func(ctx context.Context, e orderPlaced) error {
+		syncCount++
+		return nil
+	})
+	unsubscribeAsync := SubscribeAsync(bus, "async", func(ctx context.Context, e orderPlaced) error {
+		mu.Lock()
+		defer mu.Unlock()
+		asyncCount++
+		return nil
+	})
+
+	Publish(context.Background(), bus, orderPlaced{ID: 1})
+	unsubscribe()
+	unsubscribe()
+	unsubscribeAsync()
+	Publish(context.Background(), bus, orderPlaced{ID: 2})
+	bus.Close()
+
+	if syncCount != 1 {
+		t.Errorf("Expected 1 sync delivery, got %d", syncCount)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if asyncCount != 1 {
+		t.Errorf("Expected 1 async delivery, got %d", asyncCount)
+	}
+}
+
+func TestBus_NilBusIgnoresEvents(t *testing.T) {
+	var bus *Bus
+	done := make(chan struct{})
+	go func() {
+		Publish(context.Background(), bus, orderPlaced{ID: 1})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Publish on a nil bus to return")
+	}
+}
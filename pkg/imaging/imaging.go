@@ -0,0 +1,128 @@
+// Package imaging gera miniaturas e remove metadados de imagens usando
+// apenas os pacotes image da biblioteca padrão.
+package imaging
+
+import (
+	"errors"
+	"image"
+	_ "image/gif" // registra o decodificador GIF
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// MaxPixels limita as dimensões das imagens decodificadas, evitando que um
+// arquivo pequeno e muito comprimido consuma memória demais
+const MaxPixels = 50_000_000
+
+// JPEGQuality é a qualidade usada ao codificar miniaturas JPEG
+const JPEGQuality = 85
+
+// Erros retornados ao processar imagens
+var (
+	ErrUnsupported   = errors.New("imaging: formato de imagem não suportado")
+	ErrTooManyPixels = errors.New("imaging: imagem excede o número máximo de pixels")
+	ErrMalformed     = errors.New("imaging: imagem malformada")
+)
+
+// Supported informa se o tipo MIME é uma imagem que pode ter miniatura
+func Supported(contentType string) bool {
+	switch mediaType(contentType) {
+	case "image/jpeg", "image/png", "image/gif":
+		return true
+	}
+	return false
+}
+
+// ThumbnailType retorna o tipo MIME da miniatura de uma imagem: JPEG para
+// originais JPEG e PNG para os demais, preservando a transparência
+func ThumbnailType(contentType string) string {
+	if mediaType(contentType) == "image/jpeg" {
+		return "image/jpeg"
+	}
+	return "image/png"
+}
+
+// Decode lê a imagem, recusando dimensões acima de MaxPixels antes de
+// decodificar os pixels. Em GIFs animados é usado o primeiro quadro.
+func Decode(r io.ReadSeeker) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, ErrUnsupported
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > MaxPixels {
+		return nil, ErrTooManyPixels
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// Encode grava a imagem no formato indicado por contentType (veja
+// ThumbnailType)
+func Encode(w io.Writer, img image.Image, contentType string) error {
+	if mediaType(contentType) == "image/jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: JPEGQuality})
+	}
+	return png.Encode(w, img)
+}
+
+// Thumbnail reduz a imagem para caber em um quadrado de size pixels,
+// mantendo a proporção. Cada pixel da miniatura é a média da área
+// correspondente da imagem original. Imagens menores não são ampliadas.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, max(1, h*size/w)
+		} else {
+			tw, th = max(1, w*size/h), size
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		sy0 := y * h / th
+		sy1 := max((y+1)*h/th, sy0+1)
+		for x := 0; x < tw; x++ {
+			sx0 := x * w / tw
+			sx1 := max((x+1)*w/tw, sx0+1)
+
+			// As componentes de RGBA() são pré-multiplicadas pelo alfa, o
+			// que evita que pixels transparentes escureçam a média
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			if a == 0 {
+				continue
+			}
+			dst.Pix[i+0] = uint8(r * 0xff / a)
+			dst.Pix[i+1] = uint8(g * 0xff / a)
+			dst.Pix[i+2] = uint8(b * 0xff / a)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
+
+// mediaType retorna o tipo MIME sem parâmetros
+func mediaType(contentType string) string {
+	mt, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mt))
+}
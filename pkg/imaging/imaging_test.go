@@ -0,0 +1,140 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func newTestImage(w, h int, fill func(x, y int) color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, fill(x, y))
+		}
+	}
+	return img
+}
+
+func TestThumbnail(t *testing.T) {
+	img := newTestImage(400, 200, func(x, y int) color.Color {
+		if x%2 == 0 {
+			return color.NRGBA{R: 255, A: 255}
+		}
+		return color.NRGBA{B: 255, A: 255}
+	})
+
+	thumb := Thumbnail(img, 100)
+	if b := thumb.Bounds(); b.Dx() != 100 || b.Dy() != 50 {
+		t.Fatalf("Expected 100x50, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	c := color.NRGBAModel.Convert(thumb.At(10, 10)).(color.NRGBA)
+	if c.R < 120 || c.R > 135 || c.B < 120 || c.B > 135 || c.A != 255 {
+		t.Errorf("Expected averaged purple, got %+v", c)
+	}
+
+	tall := Thumbnail(newTestImage(50, 300, func(x, y int) color.Color { return color.White }), 100)
+	if b := tall.Bounds(); b.Dx() != 16 || b.Dy() != 100 {
+		t.Errorf("Expected 16x100, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	small := Thumbnail(newTestImage(20, 10, func(x, y int) color.Color { return color.White }), 100)
+	if b := small.Bounds(); b.Dx() != 20 || b.Dy() != 10 {
+		t.Errorf("Expected small image to keep 20x10, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestThumbnail_Transparency(t *testing.T) {
+	img := newTestImage(4, 4, func(x, y int) color.Color {
+		if x < 2 {
+			return color.NRGBA{R: 255, A: 255}
+		}
+		return color.NRGBA{}
+	})
+
+	c := color.NRGBAModel.Convert(Thumbnail(img, 1).At(0, 0)).(color.NRGBA)
+	if c.R != 255 || c.A < 126 || c.A > 128 {
+		t.Errorf("Expected half-transparent pure red, got %+v", c)
+	}
+}
+
+func TestDecode_TooManyPixels(t *testing.T) {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	data := buf.Bytes()
+	// Altera as dimensões declaradas no IHDR sem tocar nos pixels
+	binary.BigEndian.PutUint32(data[16:], 100000)
+	binary.BigEndian.PutUint32(data[20:], 100000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	if _, err := Decode(bytes.NewReader(data)); err != ErrTooManyPixels {
+		t.Errorf("Expected ErrTooManyPixels, got %v", err)
+	}
+}
+
+func TestStripMetadata_JPEG(t *testing.T) {
+	var buf bytes.Buffer
+	_ = jpeg.Encode(&buf, newTestImage(8, 8, func(x, y int) color.Color { return color.White }), nil)
+	original := buf.Bytes()
+
+	exif := append([]byte("Exif\x00\x00"), []byte("GPS -23.5505 -46.6333")...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(exif)+2))
+	withExif := append(append(append([]byte{}, original[:2]...), append(segment, exif...)...), original[2:]...)
+
+	stripped, err := StripMetadata("image/jpeg", withExif)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if bytes.Contains(stripped, []byte("Exif")) || bytes.Contains(stripped, []byte("GPS")) {
+		t.Error("Expected EXIF segment to be removed")
+	}
+	if !bytes.Equal(stripped, original) {
+		t.Error("Expected remaining segments to be preserved")
+	}
+
+	if _, err := StripMetadata("image/jpeg", []byte("não é jpeg")); err != ErrMalformed {
+		t.Errorf("Expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestStripMetadata_PNG(t *testing.T) {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, newTestImage(8, 8, func(x, y int) color.Color { return color.White }))
+	original := buf.Bytes()
+
+	exif := []byte("MM\x00\x2aGPS")
+	chunk := make([]byte, 8, 12+len(exif))
+	binary.BigEndian.PutUint32(chunk, uint32(len(exif)))
+	copy(chunk[4:], "eXIf")
+	chunk = append(chunk, exif...)
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	// O chunk eXIf é inserido logo após o IHDR
+	ihdrEnd := 8 + 12 + 13
+	withExif := append(append(append([]byte{}, original[:ihdrEnd]...), chunk...), original[ihdrEnd:]...)
+
+	stripped, err := StripMetadata("image/png", withExif)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !bytes.Equal(stripped, original) {
+		t.Error("Expected eXIf chunk to be removed")
+	}
+	if _, err := png.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("Expected valid PNG, got %v", err)
+	}
+}
+
+func TestStripMetadata_OtherTypes(t *testing.T) {
+	data := []byte("texto")
+	if out, err := StripMetadata("text/plain; charset=utf-8", data); err != nil || !bytes.Equal(out, data) {
+		t.Errorf("Expected data unchanged, got %q, %v", out, err)
+	}
+}
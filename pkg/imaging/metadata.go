@@ -0,0 +1,92 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// StripMetadata remove os metadados EXIF de imagens JPEG (segmentos APP1)
+// e PNG (chunks eXIf), sem recodificar os pixels. Outros tipos são
+// retornados sem alteração.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch mediaType(contentType) {
+	case "image/jpeg":
+		return stripJPEG(data)
+	case "image/png":
+		return stripPNG(data)
+	}
+	return data, nil
+}
+
+// stripJPEG copia os segmentos do cabeçalho JPEG, exceto APP1, até o
+// início dos dados comprimidos (SOS), que são copiados integralmente
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return nil, ErrMalformed
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	for i := 2; ; {
+		if i+1 >= len(data) || data[i] != 0xFF {
+			return nil, ErrMalformed
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF:
+			// Byte de preenchimento entre segmentos
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			out.Write(data[i:])
+			return out.Bytes(), nil
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7):
+			out.Write(data[i : i+2])
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, ErrMalformed
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) || end < i+4 {
+			return nil, ErrMalformed
+		}
+		if marker != 0xE1 {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+}
+
+// stripPNG copia os chunks PNG, exceto eXIf, até o chunk IEND
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrMalformed
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for i := len(pngSignature); ; {
+		if i+12 > len(data) {
+			return nil, ErrMalformed
+		}
+		length := int64(binary.BigEndian.Uint32(data[i:]))
+		end := int64(i) + 12 + length
+		if end > int64(len(data)) {
+			return nil, ErrMalformed
+		}
+
+		chunk := string(data[i+4 : i+8])
+		if chunk != "eXIf" {
+			out.Write(data[i:end])
+		}
+		if chunk == "IEND" {
+			return out.Bytes(), nil
+		}
+		i = int(end)
+	}
+}
@@ -0,0 +1,50 @@
+// Package logging cria o logger estruturado (log/slog) da aplicação a partir
+// da configuração.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"echo-playground/pkg/config"
+)
+
+// Formatos de saída aceitos em logging.format
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// New cria um logger que escreve em w no formato e a partir do nível
+// configurados. Nível vazio equivale a "info" e formato vazio, a "json".
+// Registros feitos com um contexto de WithRequestID incluem o request_id e,
+// com um span no contexto, o trace_id e o span_id.
+func New(cfg config.LoggingConfig, w io.Writer) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		return slog.New(contextHandler{slog.NewJSONHandler(w, opts)}), nil
+	case FormatText:
+		return slog.New(contextHandler{slog.NewTextHandler(w, opts)}), nil
+	}
+	return nil, fmt.Errorf("logging: formato desconhecido %q", cfg.Format)
+}
+
+// ParseLevel converte "debug", "info", "warn" ou "error" no nível do slog
+func ParseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("logging: nível desconhecido %q", name)
+	}
+	return level, nil
+}
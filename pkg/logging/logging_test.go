@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"echo-playground/pkg/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name  string
+		level slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		level, err := ParseLevel(tt.name)
+		if err != nil || level != tt.level {
+			t.Errorf("%q: expected %v, got %v (%v)", tt.name, tt.level, level, err)
+		}
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+func TestNew_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(config.LoggingConfig{Level: "warn", Format: "json"}, &buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logger.Info("ignorada")
+	logger.Warn("registrada", "status", 404)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the warn line, got %q", buf.String())
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected JSON line, got %q", lines[0])
+	}
+	if entry["msg"] != "registrada" || entry["level"] != "WARN" || entry["status"] != float64(404) {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+}
+
+func TestNew_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(config.LoggingConfig{Format: "text"}, &buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logger.Debug("ignorada")
+	logger.Info("iniciado", "port", 8080)
+
+	if got := buf.String(); !strings.Contains(got, "msg=iniciado port=8080") || strings.Contains(got, "ignorada") {
+		t.Errorf("Unexpected text output: %q", got)
+	}
+
+	if _, err := New(config.LoggingConfig{Format: "xml"}, &buf); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+func TestNew_RequestIDFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(config.LoggingConfig{}, &buf)
+
+	logger.InfoContext(WithRequestID(context.Background(), "req-1"), "com id")
+	logger.With("component", "teste").InfoContext(context.Background(), "sem id")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"request_id":"req-1"`) {
+		t.Errorf("Expected request_id in the first line, got %q", buf.String())
+	}
+	if strings.Contains(lines[1], "request_id") || !strings.Contains(lines[1], `"component":"teste"`) {
+		t.Errorf("Expected no request_id and kept attrs in the second line, got %q", lines[1])
+	}
+}
+
+func TestNew_TraceFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(config.LoggingConfig{}, &buf)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	logger.InfoContext(ctx, "com span")
+
+	var entry map[string]interface{}
+	json.Unmarshal(buf.Bytes(), &entry)
+	if entry["trace_id"] != traceID.String() || entry["span_id"] != spanID.String() {
+		t.Errorf("Expected trace_id and span_id, got %s", buf.String())
+	}
+}
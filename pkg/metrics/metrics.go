@@ -0,0 +1,162 @@
+// Package metrics expõe as métricas da aplicação no formato do Prometheus:
+// requisições HTTP por rota, uploads, conexões de longa duração e as
+// estatísticas do runtime Go. Os métodos aceitam um *Metrics nil, que não
+// registra nada.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace é o prefixo das métricas da aplicação
+const Namespace = "echo_playground"
+
+// Tipos de conexão de longa duração acompanhados em ConnectionOpened
+const (
+	ConnectionWebSocket = "websocket"
+	ConnectionSSE       = "sse"
+)
+
+// routeNotFound é o rótulo de rota das requisições que não casaram com
+// nenhuma rota, para não criar uma série por URL desconhecida
+const routeNotFound = "not_found"
+
+// Metrics reúne os coletores registrados em um registry próprio
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests    *prometheus.CounterVec
+	duration    *prometheus.HistogramVec
+	inFlight    prometheus.Gauge
+	uploads     prometheus.Counter
+	uploadBytes prometheus.Counter
+	connections *prometheus.GaugeVec
+}
+
+// New cria e registra as métricas, incluindo as do runtime Go e do processo
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "http_requests_total",
+			Help:      "Requisições HTTP atendidas, por método, rota e status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duração das requisições HTTP, por método, rota e status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Requisições HTTP em andamento.",
+		}),
+		uploads: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "uploads_total",
+			Help:      "Arquivos aceitos pelos endpoints de upload.",
+		}),
+		uploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "upload_bytes_total",
+			Help:      "Bytes dos arquivos aceitos pelos endpoints de upload.",
+		}),
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "active_connections",
+			Help:      "Conexões de longa duração abertas, por tipo (websocket, sse).",
+		}, []string{"type"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.duration,
+		m.inFlight,
+		m.uploads,
+		m.uploadBytes,
+		m.connections,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	// As séries de conexões existem desde o início, com valor zero
+	m.connections.WithLabelValues(ConnectionWebSocket)
+	m.connections.WithLabelValues(ConnectionSSE)
+	return m
+}
+
+// Handler responde com as métricas no formato texto do Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware conta as requisições e mede sua duração, rotulando-as pelo
+// modelo da rota (por exemplo /api/v1/products/:id), nunca pela URL
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if m == nil {
+				return next(c)
+			}
+
+			m.inFlight.Inc()
+			defer m.inFlight.Dec()
+			start := time.Now()
+
+			err := next(c)
+
+			route := c.Path()
+			if route == "" {
+				route = routeNotFound
+			}
+			status := strconv.Itoa(responseStatus(c, err))
+			method := c.Request().Method
+			m.requests.WithLabelValues(method, route, status).Inc()
+			m.duration.WithLabelValues(method, route, status).Observe(time.Since(start).Seconds())
+
+			return err
+		}
+	}
+}
+
+// UploadAccepted registra um arquivo aceito com size bytes
+func (m *Metrics) UploadAccepted(size int64) {
+	if m == nil {
+		return
+	}
+	m.uploads.Inc()
+	m.uploadBytes.Add(float64(size))
+}
+
+// ConnectionOpened conta uma conexão do tipo informado como aberta e
+// retorna a função que a conta como encerrada
+func (m *Metrics) ConnectionOpened(kind string) func() {
+	if m == nil {
+		return func() {}
+	}
+	gauge := m.connections.WithLabelValues(kind)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+// responseStatus retorna o status enviado ou, se o erro ainda não foi
+// tratado pelo HTTPErrorHandler, o status que ele vai gerar
+func responseStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// scrape lê as métricas expostas pelo Handler
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestMiddleware_LabelsByRouteTemplate(t *testing.T) {
+	m := New()
+	e := echo.New()
+	e.Use(m.Middleware())
+	e.GET("/products/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return echo.NewHTTPError(http.StatusNotFound, "não encontrado")
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.GET("/boom", func(c echo.Context) error {
+		return errors.New("falhou")
+	})
+
+	for _, path := range []string{"/products/1", "/products/2", "/products/0", "/boom", "/nao-existe"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, m)
+	expected := []string{
+		`echo_playground_http_requests_total{method="GET",route="/products/:id",status="204"} 2`,
+		`echo_playground_http_requests_total{method="GET",route="/products/:id",status="404"} 1`,
+		`echo_playground_http_requests_total{method="GET",route="/boom",status="500"} 1`,
+		`echo_playground_http_requests_total{method="GET",route="not_found",status="404"} 1`,
+		`echo_playground_http_request_duration_seconds_count{method="GET",route="/products/:id",status="204"} 2`,
+		`echo_playground_http_requests_in_flight 0`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected %q in metrics output", line)
+		}
+	}
+	if strings.Contains(body, "/products/1") {
+		t.Error("Expected no series labelled with the raw URL")
+	}
+}
+
+func TestMiddleware_InFlight(t *testing.T) {
+	m := New()
+	e := echo.New()
+	e.Use(m.Middleware())
+
+	var during string
+	e.GET("/", func(c echo.Context) error {
+		during = scrape(t, m)
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !strings.Contains(during, "echo_playground_http_requests_in_flight 1") {
+		t.Errorf("Expected one request in flight during the handler, got:\n%s", during)
+	}
+}
+
+func TestUploadsAndConnections(t *testing.T) {
+	m := New()
+	m.UploadAccepted(100)
+	m.UploadAccepted(24)
+
+	closeWS := m.ConnectionOpened(ConnectionWebSocket)
+	m.ConnectionOpened(ConnectionWebSocket)
+	closeSSE := m.ConnectionOpened(ConnectionSSE)
+	closeWS()
+	closeSSE()
+
+	body := scrape(t, m)
+	expected := []string{
+		"echo_playground_uploads_total 2",
+		"echo_playground_upload_bytes_total 124",
+		`echo_playground_active_connections{type="websocket"} 1`,
+		`echo_playground_active_connections{type="sse"} 0`,
+		"go_goroutines",
+		"go_memstats_alloc_bytes",
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected %q in metrics output", line)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.UploadAccepted(10)
+	m.ConnectionOpened(ConnectionSSE)()
+
+	e := echo.New()
+	e.Use(m.Middleware())
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", rec.Code)
+	}
+}
@@ -1,11 +1,83 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 )
 
+// JWTSecret é a chave usada para assinar e validar os tokens JWT
+var JWTSecret = []byte("secret")
+
+// DemoToken é um token estático aceito para facilitar testes manuais
+const DemoToken = "valid-token"
+
+// DemoUserID é o ID de usuário associado ao DemoToken. É negativo para
+// nunca coincidir com um ID atribuído pelo repositório de usuários, que
+// começa em 1.
+const DemoUserID = -1
+
+// Chaves usadas para armazenar os dados do usuário autenticado no contexto
+const (
+	ContextKeyUserID   = "user_id"
+	ContextKeyUsername = "username"
+	ContextKeyRole     = "role"
+)
+
+// Papéis de usuário reconhecidos pela aplicação
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Claims contém os dados extraídos de um token válido
+type Claims struct {
+	UserID   int
+	Username string
+	Role     string
+}
+
+// ParseToken valida um token JWT e extrai suas claims
+func ParseToken(tokenString string) (*Claims, error) {
+	if tokenString == DemoToken {
+		return &Claims{UserID: DemoUserID, Username: "demo", Role: RoleUser}, nil
+	}
+
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("método de assinatura inesperado: %v", t.Header["alg"])
+		}
+		return JWTSecret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("claims inválidas")
+	}
+
+	userID, ok := mapClaims["user_id"].(float64)
+	if !ok {
+		return nil, errors.New("claim user_id ausente")
+	}
+
+	claims := &Claims{UserID: int(userID), Role: RoleUser}
+	if username, ok := mapClaims["username"].(string); ok {
+		claims.Username = username
+	}
+	if role, ok := mapClaims["role"].(string); ok && role != "" {
+		claims.Role = role
+	}
+
+	return claims, nil
+}
+
 // AuthMiddleware cria um middleware para autenticação JWT
 func AuthMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -19,9 +91,16 @@ func AuthMiddleware() echo.MiddlewareFunc {
 				})
 			}
 
-			// Aqui você implementaria a validação real do JWT
-			// Por simplicidade, apenas verificamos se o token existe
-			if token != "Bearer valid-token" {
+			if !strings.HasPrefix(token, "Bearer ") {
+				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
+					"success": false,
+					"message": "Token inválido",
+					"error":   "",
+				})
+			}
+
+			claims, err := ParseToken(strings.TrimPrefix(token, "Bearer "))
+			if err != nil {
 				return c.JSON(http.StatusUnauthorized, map[string]interface{}{
 					"success": false,
 					"message": "Token inválido",
@@ -29,7 +108,65 @@ func AuthMiddleware() echo.MiddlewareFunc {
 				})
 			}
 
+			setClaims(c, claims)
+			return next(c)
+		}
+	}
+}
+
+// OptionalAuth cria um middleware que identifica o usuário quando o header
+// Authorization é enviado, mas permite requisições anônimas. Um token
+// inválido continua sendo recusado.
+func OptionalAuth() echo.MiddlewareFunc {
+	auth := AuthMiddleware()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := auth(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+			return authenticated(c)
+		}
+	}
+}
+
+// RequireRole cria um middleware que só permite usuários com o papel informado.
+// Deve ser usado após AuthMiddleware.
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if Role(c) != role {
+				return c.JSON(http.StatusForbidden, map[string]interface{}{
+					"success": false,
+					"message": "Acesso negado",
+					"error":   "",
+				})
+			}
 			return next(c)
 		}
 	}
 }
+
+// setClaims armazena os dados do usuário autenticado no contexto
+func setClaims(c echo.Context, claims *Claims) {
+	c.Set(ContextKeyUserID, claims.UserID)
+	c.Set(ContextKeyUsername, claims.Username)
+	c.Set(ContextKeyRole, claims.Role)
+}
+
+// UserID retorna o ID do usuário autenticado, se houver
+func UserID(c echo.Context) (int, bool) {
+	id, ok := c.Get(ContextKeyUserID).(int)
+	return id, ok
+}
+
+// Role retorna o papel do usuário autenticado
+func Role(c echo.Context) string {
+	role, _ := c.Get(ContextKeyRole).(string)
+	return role
+}
+
+// IsAdmin informa se o usuário autenticado é administrador
+func IsAdmin(c echo.Context) bool {
+	return Role(c) == RoleAdmin
+}
@@ -3,9 +3,12 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
 )
 
@@ -132,3 +135,142 @@ func TestAuthMiddleware_InvalidAuthorizationFormat(t *testing.T) {
 		t.Errorf("Expected response to contain 'Token inválido', got '%s'", responseBody)
 	}
 }
+
+func TestAuthMiddleware_JWT(t *testing.T) {
+	e := echo.New()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":  42,
+		"username": "maria",
+		"role":     RoleAdmin,
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString(JWTSecret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	handler := func(c echo.Context) error {
+		id, ok := UserID(c)
+		if !ok || id != 42 {
+			t.Errorf("Expected user ID 42, got %d", id)
+		}
+		if !IsAdmin(c) {
+			t.Error("Expected admin role")
+		}
+		return c.String(http.StatusOK, "success")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := AuthMiddleware()(handler)(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestAuthMiddleware_ExpiredJWT(t *testing.T) {
+	e := echo.New()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": 42,
+		"exp":     time.Now().Add(-time.Hour).Unix(),
+	})
+	signed, _ := token.SignedString(JWTSecret)
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "success")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := AuthMiddleware()(handler)(c); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", rec.Code)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	e := echo.New()
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "success")
+	}
+
+	tests := []struct {
+		role     string
+		expected int
+	}{
+		{RoleAdmin, http.StatusOK},
+		{RoleUser, http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if tt.role != "" {
+			c.Set(ContextKeyRole, tt.role)
+		}
+
+		if err := RequireRole(RoleAdmin)(handler)(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		if rec.Code != tt.expected {
+			t.Errorf("Role %q: expected status %d, got %d", tt.role, tt.expected, rec.Code)
+		}
+	}
+}
+
+func TestOptionalAuth(t *testing.T) {
+	e := echo.New()
+
+	handler := func(c echo.Context) error {
+		id, _ := UserID(c)
+		return c.String(http.StatusOK, strconv.Itoa(id))
+	}
+
+	tests := []struct {
+		header   string
+		status   int
+		expected string
+	}{
+		{"", http.StatusOK, "0"},
+		{"Bearer valid-token", http.StatusOK, strconv.Itoa(DemoUserID)},
+		{"Bearer invalid-token", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := OptionalAuth()(handler)(c); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+
+		if rec.Code != tt.status {
+			t.Errorf("Header %q: expected status %d, got %d", tt.header, tt.status, rec.Code)
+		}
+		if tt.expected != "" && rec.Body.String() != tt.expected {
+			t.Errorf("Header %q: expected user %s, got %s", tt.header, tt.expected, rec.Body.String())
+		}
+	}
+}
@@ -1,28 +1,57 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
-// CustomLogger cria um middleware para logging customizado
-func CustomLogger() echo.MiddlewareFunc {
+// AccessLog cria um middleware que registra uma linha por requisição com o
+// método, a rota, o status, os bytes enviados, a latência, o IP de origem e
+// o usuário autenticado. Deve vir depois do RequestIDMiddleware, do
+// TracingMiddleware e do middleware de métricas, para que a linha leve o
+// request_id e o trace_id, e antes dos demais: os erros são tratados aqui,
+// pelo HTTPErrorHandler, para que o status registrado seja o enviado ao
+// cliente. Respostas 4xx são registradas como warn e 5xx, como error.
+func AccessLog(logger *slog.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			start := time.Now()
 
-			// Log antes da requisição
-			log.Printf("Iniciando requisição: %s %s", c.Request().Method, c.Request().URL.Path)
-
 			err := next(c)
+			if err != nil {
+				c.Error(err)
+			}
+
+			req := c.Request()
+			res := c.Response()
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("route", c.Path()),
+				slog.String("path", req.URL.Path),
+				slog.Int("status", res.Status),
+				slog.Int64("bytes", res.Size),
+				slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remote_ip", c.RealIP()),
+			}
+			if userID, ok := UserID(c); ok {
+				attrs = append(attrs, slog.Int("user_id", userID))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
 
-			// Log após a requisição
-			duration := time.Since(start)
-			log.Printf("Requisição finalizada: %s %s - Duração: %v", c.Request().Method, c.Request().URL.Path, duration)
+			level := slog.LevelInfo
+			switch {
+			case res.Status >= 500:
+				level = slog.LevelError
+			case res.Status >= 400:
+				level = slog.LevelWarn
+			}
+			logger.LogAttrs(req.Context(), level, "request", attrs...)
 
-			return err
+			return nil
 		}
 	}
 }
@@ -2,127 +2,117 @@ package middleware
 
 import (
 	"bytes"
-	"io"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
 )
 
-func TestLoggerMiddleware(t *testing.T) {
-	e := echo.New()
-
-	// Capturar a saída de log
+// runAccessLog executa o handler com o AccessLog e retorna a resposta e a
+// única linha de log gerada
+func runAccessLog(t *testing.T, handler echo.HandlerFunc, req *http.Request, route string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
 	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
 
-	// Criar um pipe para capturar stdout
-	r, w, _ := os.Pipe()
-	stdout := os.Stdout
-	os.Stdout = w
-
-	handler := func(c echo.Context) error {
-		return c.String(http.StatusOK, "success")
-	}
-
-	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	e := echo.New()
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
+	c.SetPath(route)
 
-	middleware := CustomLogger()
-	wrappedHandler := middleware(handler)
+	if err := AccessLog(logger)(handler)(c); err != nil {
+		t.Errorf("Expected errors to be handled by the middleware, got %v", err)
+	}
 
-	err := wrappedHandler(c)
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected exactly one log line, got %q", buf.String())
 	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected JSON log line, got %q", lines[0])
+	}
+	return rec, entry
+}
 
-	// Restaurar stdout
-	if err := w.Close(); err != nil {
-		t.Errorf("Failed to close writer: %v", err)
+func TestAccessLog(t *testing.T) {
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "success")
 	}
-	os.Stdout = stdout
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
 
-	// Ler a saída capturada
-	_, err = io.Copy(&buf, r)
-	if err != nil {
-		t.Errorf("Failed to copy output: %v", err)
+	rec, entry := runAccessLog(t, handler, req, "/users/:id")
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "success" {
+		t.Errorf("Expected 200 success, got %d %q", rec.Code, rec.Body.String())
 	}
-	if err := r.Close(); err != nil {
-		t.Errorf("Failed to close reader: %v", err)
+	expected := map[string]interface{}{
+		"level":     "INFO",
+		"msg":       "request",
+		"method":    "GET",
+		"route":     "/users/:id",
+		"path":      "/users/42",
+		"status":    float64(200),
+		"bytes":     float64(len("success")),
+		"remote_ip": "203.0.113.7",
 	}
-
-	// Verificar se a resposta foi bem sucedida
-	if rec.Code != http.StatusOK {
-		t.Errorf("Expected status 200, got %d", rec.Code)
+	for key, want := range expected {
+		if entry[key] != want {
+			t.Errorf("Expected %s=%v, got %v", key, want, entry[key])
+		}
 	}
-
-	if strings.TrimSpace(rec.Body.String()) != "success" {
-		t.Errorf("Expected 'success', got '%s'", strings.TrimSpace(rec.Body.String()))
+	if _, ok := entry["latency_ms"].(float64); !ok {
+		t.Errorf("Expected numeric latency_ms, got %v", entry["latency_ms"])
+	}
+	if _, ok := entry["user_id"]; ok {
+		t.Errorf("Expected no user_id for anonymous request, got %v", entry["user_id"])
 	}
 }
 
-func TestLoggerMiddleware_POSTRequest(t *testing.T) {
-	e := echo.New()
-
+func TestAccessLog_AuthenticatedUser(t *testing.T) {
 	handler := func(c echo.Context) error {
+		setClaims(c, &Claims{UserID: 7, Username: "ana", Role: RoleUser})
 		return c.JSON(http.StatusCreated, map[string]string{"status": "created"})
 	}
-
 	req := httptest.NewRequest(http.MethodPost, "/api/users", strings.NewReader(`{"name":"test"}`))
 	req.Header.Set("Content-Type", "application/json")
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
-
-	middleware := CustomLogger()
-	wrappedHandler := middleware(handler)
 
-	err := wrappedHandler(c)
-	if err != nil {
-		t.Errorf("Expected no error, got %v", err)
-	}
+	rec, entry := runAccessLog(t, handler, req, "/api/users")
 
-	// Verificar se a resposta foi bem sucedida
 	if rec.Code != http.StatusCreated {
 		t.Errorf("Expected status 201, got %d", rec.Code)
 	}
-
-	responseBody := strings.TrimSpace(rec.Body.String())
-	if !strings.Contains(responseBody, "created") {
-		t.Errorf("Expected response to contain 'created', got '%s'", responseBody)
+	if entry["user_id"] != float64(7) || entry["status"] != float64(201) {
+		t.Errorf("Expected user 7 and status 201, got %v", entry)
 	}
 }
 
-func TestLoggerMiddleware_ErrorResponse(t *testing.T) {
-	e := echo.New()
-
-	handler := func(c echo.Context) error {
-		return echo.NewHTTPError(http.StatusBadRequest, "Bad request")
+func TestAccessLog_ErrorResponse(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		level  string
+	}{
+		{echo.NewHTTPError(http.StatusBadRequest, "Bad request"), http.StatusBadRequest, "WARN"},
+		{errors.New("falha inesperada"), http.StatusInternalServerError, "ERROR"},
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/error", nil)
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
-
-	middleware := CustomLogger()
-	wrappedHandler := middleware(handler)
-
-	err := wrappedHandler(c)
-	if err == nil {
-		t.Error("Expected error, got none")
-	}
+	for _, tt := range tests {
+		handler := func(c echo.Context) error { return tt.err }
+		rec, entry := runAccessLog(t, handler, httptest.NewRequest(http.MethodGet, "/error", nil), "/error")
 
-	// Verificar se é um echo.HTTPError
-	if httpErr, ok := err.(*echo.HTTPError); ok {
-		if httpErr.Code != http.StatusBadRequest {
-			t.Errorf("Expected status 400, got %d", httpErr.Code)
+		// O status registrado é o enviado pelo HTTPErrorHandler
+		if rec.Code != tt.status || entry["status"] != float64(tt.status) {
+			t.Errorf("Expected status %d, got response %d and log %v", tt.status, rec.Code, entry["status"])
 		}
-		if httpErr.Message != "Bad request" {
-			t.Errorf("Expected 'Bad request', got '%s'", httpErr.Message)
+		if entry["level"] != tt.level || entry["error"] == nil {
+			t.Errorf("Expected %s entry with error, got %v", tt.level, entry)
 		}
-	} else {
-		t.Errorf("Expected echo.HTTPError, got %T", err)
 	}
 }
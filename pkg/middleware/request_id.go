@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/logging"
+	"echo-playground/pkg/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ContextKeyRequestID é a chave do ID da requisição no contexto do Echo
+const ContextKeyRequestID = "request_id"
+
+// MaxRequestIDLength é o tamanho máximo de um X-Request-ID aceito do cliente
+const MaxRequestIDLength = 128
+
+// RequestIDMiddleware cria um middleware que identifica cada requisição pelo
+// header X-Request-ID enviado pelo cliente ou, se ausente ou inválido, por um
+// ID gerado. O ID é devolvido no header da resposta, guardado no contexto do
+// Echo e no contexto da requisição, para constar nos logs. Deve vir antes do
+// AccessLog.
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(id) {
+				id = utils.NewID()
+			}
+
+			c.Set(ContextKeyRequestID, id)
+			c.SetRequest(req.WithContext(logging.WithRequestID(req.Context(), id)))
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+
+			return next(c)
+		}
+	}
+}
+
+// RequestID retorna o ID da requisição atual, se houver
+func RequestID(c echo.Context) string {
+	id, _ := c.Get(ContextKeyRequestID).(string)
+	return id
+}
+
+// validRequestID aceita IDs curtos formados por letras, dígitos e os
+// caracteres - _ . : evitando que o cliente injete conteúdo nos logs
+func validRequestID(id string) bool {
+	if id == "" || len(id) > MaxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// JSONSerializer inclui o request_id nos corpos de erro em JSON: respostas
+// api.Response sem sucesso e mapas com "success": false. Os demais valores
+// são serializados sem alteração.
+type JSONSerializer struct {
+	echo.DefaultJSONSerializer
+}
+
+// Serialize acrescenta o ID da requisição aos corpos de erro
+func (s JSONSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	if id := RequestID(c); id != "" {
+		switch body := i.(type) {
+		case *api.Response:
+			if !body.Success && body.RequestID == "" {
+				withID := *body
+				withID.RequestID = id
+				i = &withID
+			}
+		case map[string]interface{}:
+			if success, ok := body["success"].(bool); ok && !success {
+				if _, exists := body["request_id"]; !exists {
+					withID := make(map[string]interface{}, len(body)+1)
+					for k, v := range body {
+						withID[k] = v
+					}
+					withID["request_id"] = id
+					i = withID
+				}
+			}
+		}
+	}
+	return s.DefaultJSONSerializer.Serialize(c, i, indent)
+}
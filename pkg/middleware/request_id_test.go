@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"echo-playground/pkg/api"
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/logging"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newRequestIDServer(handler echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.JSONSerializer = JSONSerializer{}
+	e.Use(RequestIDMiddleware())
+	e.GET("/", handler)
+	return e
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var fromContext, fromRequest string
+	e := newRequestIDServer(func(c echo.Context) error {
+		fromContext = RequestID(c)
+		fromRequest = logging.RequestID(c.Request().Context())
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		header string
+		keep   bool
+	}{
+		{"", false},
+		{"cliente-123:abc.DEF_4", true},
+		{"com espaço", false},
+		{"quebra\nde-linha", false},
+		{strings.Repeat("a", MaxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set(echo.HeaderXRequestID, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		id := rec.Header().Get(echo.HeaderXRequestID)
+		if tt.keep && id != tt.header {
+			t.Errorf("%q: expected client ID to be kept, got %q", tt.header, id)
+		}
+		if !tt.keep && (id == "" || id == tt.header) {
+			t.Errorf("%q: expected a generated ID, got %q", tt.header, id)
+		}
+		if fromContext != id || fromRequest != id {
+			t.Errorf("%q: expected %q in both contexts, got %q and %q", tt.header, id, fromContext, fromRequest)
+		}
+	}
+}
+
+func TestJSONSerializer_ErrorBodies(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   interface{}
+		withID bool
+	}{
+		{"api error", api.NewErrorResponse("Falhou", "detalhe"), true},
+		{"api success", api.NewSuccessResponse("Ok", nil), false},
+		{"map error", map[string]interface{}{"success": false, "message": "Token inválido"}, true},
+		{"map without success", map[string]interface{}{"message": "oi"}, false},
+		{"other", []int{1, 2}, false},
+	}
+
+	for _, tt := range tests {
+		e := newRequestIDServer(func(c echo.Context) error {
+			return c.JSON(http.StatusOK, tt.body)
+		})
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderXRequestID, "req-1")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		has := strings.Contains(rec.Body.String(), `"request_id":"req-1"`)
+		if has != tt.withID {
+			t.Errorf("%s: expected request_id=%v, got %s", tt.name, tt.withID, rec.Body.String())
+		}
+	}
+
+	// O valor original não é alterado
+	body := api.NewErrorResponse("Falhou", "")
+	e := newRequestIDServer(func(c echo.Context) error { return c.JSON(http.StatusBadRequest, body) })
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if body.RequestID != "" {
+		t.Errorf("Expected response value to stay unchanged, got %q", body.RequestID)
+	}
+}
+
+func TestRequestIDMiddleware_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := logging.New(config.LoggingConfig{}, &buf)
+
+	e := echo.New()
+	e.Use(RequestIDMiddleware(), AccessLog(logger))
+	e.GET("/", func(c echo.Context) error {
+		logger.InfoContext(c.Request().Context(), "dentro do handler")
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-42")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected handler and access log lines, got %q", buf.String())
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		json.Unmarshal([]byte(line), &entry)
+		if entry["request_id"] != "req-42" {
+			t.Errorf("Expected request_id in every line, got %s", line)
+		}
+	}
+}
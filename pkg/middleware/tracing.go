@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"echo-playground/pkg/tracing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware abre um span de servidor para cada requisição, filho do
+// span remoto do header traceparent, se houver. O span é nomeado pelo
+// método e pelo modelo da rota e registra o status e o usuário autenticado.
+// Deve vir depois do RequestIDMiddleware e antes do AccessLog, para que os
+// logs da requisição tragam o trace_id.
+func TracingMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route := c.Path()
+			name := req.Method
+			if route != "" {
+				name += " " + route
+			}
+
+			ctx := tracing.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := tracing.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("http.route", route),
+					attribute.String("url.path", req.URL.Path),
+					attribute.String("client.address", c.RealIP()),
+					attribute.String("request.id", RequestID(c)),
+				),
+			)
+			defer span.End()
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+
+			status := tracedStatus(c, err)
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if id, ok := UserID(c); ok {
+				span.SetAttributes(attribute.String("enduser.id", strconv.Itoa(id)), attribute.String("enduser.role", Role(c)))
+			}
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+			return err
+		}
+	}
+}
+
+// tracedStatus retorna o status enviado ou, se o erro ainda não foi tratado
+// pelo HTTPErrorHandler, o status que ele vai gerar
+func tracedStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"echo-playground/pkg/tracing"
+	"echo-playground/pkg/tracing/tracingtest"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	var inner trace.SpanContext
+	e := echo.New()
+	e.Use(RequestIDMiddleware(), TracingMiddleware())
+	e.GET("/products/:id", func(c echo.Context) error {
+		setClaims(c, &Claims{UserID: 7, Username: "ana", Role: RoleUser})
+		_, span := tracing.StartChild(c.Request().Context(), "products.Get")
+		inner = span.SpanContext()
+		span.End()
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/products/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set(echo.HeaderXRequestID, "req-1")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+
+	span := tracingtest.Find(recorder, "GET /products/:id")
+	if span == nil {
+		t.Fatalf("Expected server span, got %d spans", len(recorder.Ended()))
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("Expected server span kind, got %v", span.SpanKind())
+	}
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected trace from traceparent, got %s", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" || !span.Parent().IsRemote() {
+		t.Errorf("Expected remote parent 00f067aa0ba902b7, got %s", got)
+	}
+
+	expected := map[string]string{
+		"http.route":                "/products/:id",
+		"http.request.method":       "GET",
+		"http.response.status_code": "204",
+		"enduser.id":                "7",
+		"enduser.role":              RoleUser,
+		"request.id":                "req-1",
+	}
+	for key, value := range expected {
+		if got := tracingtest.Attr(span, key); got != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, got)
+		}
+	}
+
+	// Os spans abertos pelo handler são filhos do span da requisição
+	if inner.TraceID() != span.SpanContext().TraceID() {
+		t.Error("Expected handler spans in the request trace")
+	}
+	if child := tracingtest.Find(recorder, "products.Get"); child == nil || child.Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Error("Expected handler span to be a child of the request span")
+	}
+}
+
+func TestTracingMiddleware_Errors(t *testing.T) {
+	tests := []struct {
+		err    error
+		status string
+		code   codes.Code
+	}{
+		{echo.NewHTTPError(http.StatusNotFound, "não encontrado"), "404", codes.Unset},
+		{errors.New("falha inesperada"), "500", codes.Error},
+	}
+
+	for _, tt := range tests {
+		recorder := tracingtest.Record(t)
+		e := echo.New()
+		e.Use(TracingMiddleware())
+		e.GET("/error", func(c echo.Context) error { return tt.err })
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/error", nil))
+
+		span := tracingtest.Find(recorder, "GET /error")
+		if span == nil {
+			t.Fatal("Expected server span")
+		}
+		if got := tracingtest.Attr(span, "http.response.status_code"); got != tt.status {
+			t.Errorf("Expected status %s, got %s", tt.status, got)
+		}
+		if span.Status().Code != tt.code {
+			t.Errorf("%s: expected span status %v, got %v", tt.status, tt.code, span.Status().Code)
+		}
+		if span.Parent().IsValid() {
+			t.Error("Expected a new trace without traceparent")
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// File descreve um arquivo enviado. Name é a chave do conteúdo no
+// armazenamento, derivada do SHA-256 e compartilhada por arquivos de mesmo
+// conteúdo; OriginalName é o nome informado pelo cliente, já sanitizado, e
+// serve apenas para exibição.
+type File struct {
+	ID           string     `json:"id" xml:"id"`
+	Name         string     `json:"filename" xml:"filename"`
+	OriginalName string     `json:"original_name" xml:"original_name"`
+	Size         int64      `json:"size" xml:"size"`
+	ContentType  string     `json:"type" xml:"type"`
+	SHA256       string     `json:"sha256" xml:"sha256"`
+	UploaderID   int        `json:"uploader_id,omitempty" xml:"uploader_id,omitempty"`
+	UploadedAt   time.Time  `json:"uploaded_at" xml:"uploaded_at"`
+	Status       string     `json:"status" xml:"status"`
+	Threat       string     `json:"threat,omitempty" xml:"threat,omitempty"`
+	ScannedAt    *time.Time `json:"scanned_at,omitempty" xml:"scanned_at,omitempty"`
+	URL          string     `json:"url,omitempty" xml:"url,omitempty"`
+}
+
+// Situações de um arquivo quanto à verificação de malware. Arquivos
+// pending ficam em quarentena até serem verificados.
+const (
+	FileStatusPending    = "pending"
+	FileStatusClean      = "clean"
+	FileStatusInfected   = "infected"
+	FileStatusScanFailed = "scan_failed"
+	FileStatusUnscanned  = "unscanned"
+)
+
+// Downloadable informa se o conteúdo do arquivo pode ser entregue: apenas
+// arquivos verificados sem ameaças ou enviados com a verificação desativada
+func (f *File) Downloadable() bool {
+	return f.Status == FileStatusClean || f.Status == FileStatusUnscanned
+}
+
+// FileUsage resume o espaço ocupado pelos arquivos de um usuário. QuotaBytes
+// zero indica que não há limite.
+type FileUsage struct {
+	UserID         int   `json:"user_id" xml:"user_id"`
+	Files          int   `json:"files" xml:"files"`
+	UsedBytes      int64 `json:"used_bytes" xml:"used_bytes"`
+	QuotaBytes     int64 `json:"quota_bytes" xml:"quota_bytes"`
+	AvailableBytes int64 `json:"available_bytes" xml:"available_bytes"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// StockReason representa o motivo de uma movimentação de estoque
+type StockReason string
+
+// Motivos de movimentação de estoque aceitos
+const (
+	StockReasonInitial    StockReason = "initial"
+	StockReasonRestock    StockReason = "restock"
+	StockReasonSale       StockReason = "sale"
+	StockReasonReturn     StockReason = "return"
+	StockReasonDamage     StockReason = "damage"
+	StockReasonCorrection StockReason = "correction"
+)
+
+// Valid informa se o motivo é um dos códigos conhecidos
+func (r StockReason) Valid() bool {
+	switch r {
+	case StockReasonInitial, StockReasonRestock, StockReasonSale,
+		StockReasonReturn, StockReasonDamage, StockReasonCorrection:
+		return true
+	}
+	return false
+}
+
+// StockMovement registra uma alteração na quantidade em estoque
+type StockMovement struct {
+	ProductID int         `json:"product_id" xml:"product_id"`
+	Delta     int         `json:"delta" xml:"delta"`
+	Reason    StockReason `json:"reason" xml:"reason"`
+	Note      string      `json:"note,omitempty" xml:"note,omitempty"`
+	Balance   int         `json:"balance" xml:"balance"`
+	CreatedAt time.Time   `json:"created_at" xml:"created_at"`
+}
+
+// StockLevel resume a situação do estoque de um produto
+type StockLevel struct {
+	ProductID int `json:"product_id" xml:"product_id"`
+	OnHand    int `json:"on_hand" xml:"on_hand"`
+	Reserved  int `json:"reserved" xml:"reserved"`
+	Available int `json:"available" xml:"available"`
+}
+
+// ReservationStatus representa o estado de uma reserva de estoque
+type ReservationStatus string
+
+// Estados possíveis de uma reserva
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+	ReservationCommitted ReservationStatus = "committed"
+)
+
+// Reservation bloqueia uma quantidade de estoque por tempo limitado
+type Reservation struct {
+	ID        string            `json:"id" xml:"id"`
+	ProductID int               `json:"product_id" xml:"product_id"`
+	UserID    int               `json:"user_id" xml:"user_id"`
+	Quantity  int               `json:"quantity" xml:"quantity"`
+	Status    ReservationStatus `json:"status" xml:"status"`
+	CreatedAt time.Time         `json:"created_at" xml:"created_at"`
+	ExpiresAt time.Time         `json:"expires_at" xml:"expires_at"`
+}
+
+// Active informa se a reserva ainda retém estoque no instante informado
+func (r *Reservation) Active(now time.Time) bool {
+	return r.Status == ReservationActive && now.Before(r.ExpiresAt)
+}
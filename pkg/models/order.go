@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"echo-playground/pkg/money"
+)
+
+// CartItem representa uma linha do carrinho de compras
+type CartItem struct {
+	ProductID int         `json:"product_id" xml:"product_id"`
+	Name      string      `json:"name" xml:"name"`
+	Quantity  int         `json:"quantity" xml:"quantity"`
+	UnitPrice money.Money `json:"unit_price" xml:"unit_price"`
+}
+
+// Subtotal retorna o valor da linha
+func (i CartItem) Subtotal() money.Money {
+	return i.UnitPrice.Mul(int64(i.Quantity))
+}
+
+// Cart representa o carrinho de compras de um usuário. Todas as linhas
+// usam a moeda do carrinho.
+type Cart struct {
+	UserID    int         `json:"user_id" xml:"user_id"`
+	Items     []CartItem  `json:"items" xml:"items>item"`
+	Total     money.Money `json:"total" xml:"total"`
+	UpdatedAt time.Time   `json:"updated_at" xml:"updated_at"`
+}
+
+// Recalculate atualiza o total do carrinho a partir das linhas
+func (c *Cart) Recalculate() error {
+	total, err := sumItems(c.Total.Currency, c.Items)
+	if err != nil {
+		return err
+	}
+	c.Total = total
+	return nil
+}
+
+// sumItems soma os subtotais das linhas na moeda informada
+func sumItems(currency string, items []CartItem) (money.Money, error) {
+	total := money.Zero(currency)
+	for _, item := range items {
+		var err error
+		if total, err = total.Add(item.Subtotal()); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// OrderStatus representa o estado de um pedido
+type OrderStatus string
+
+// Estados possíveis de um pedido
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderPaid      OrderStatus = "paid"
+	OrderShipped   OrderStatus = "shipped"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions define as transições permitidas entre estados
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending: {OrderPaid, OrderCancelled},
+	OrderPaid:    {OrderShipped, OrderCancelled},
+}
+
+// CanTransitionTo informa se o pedido pode passar para o estado informado
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Order representa um pedido finalizado a partir de um carrinho
+type Order struct {
+	ID        int         `json:"id" xml:"id"`
+	UserID    int         `json:"user_id" xml:"user_id"`
+	Items     []CartItem  `json:"items" xml:"items>item"`
+	Total     money.Money `json:"total" xml:"total"`
+	Status    OrderStatus `json:"status" xml:"status"`
+	CreatedAt time.Time   `json:"created_at" xml:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" xml:"updated_at"`
+}
+
+// NewOrder cria um pedido pendente com as linhas informadas
+func NewOrder(userID int, currency string, items []CartItem) (*Order, error) {
+	total, err := sumItems(currency, items)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &Order{
+		UserID:    userID,
+		Items:     items,
+		Total:     total,
+		Status:    OrderPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+
+	"echo-playground/pkg/money"
+)
+
+func TestOrderStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     OrderStatus
+		to       OrderStatus
+		expected bool
+	}{
+		{OrderPending, OrderPaid, true},
+		{OrderPending, OrderCancelled, true},
+		{OrderPending, OrderShipped, false},
+		{OrderPaid, OrderShipped, true},
+		{OrderPaid, OrderCancelled, true},
+		{OrderShipped, OrderCancelled, false},
+		{OrderCancelled, OrderPaid, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNewOrder(t *testing.T) {
+	items := []CartItem{
+		{ProductID: 1, Name: "Mouse", Quantity: 2, UnitPrice: money.MustParse("50.10", "BRL")},
+		{ProductID: 2, Name: "Teclado", Quantity: 1, UnitPrice: money.MustParse("199.80", "BRL")},
+	}
+
+	order, err := NewOrder(7, "BRL", items)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if order.Status != OrderPending {
+		t.Errorf("Expected status pending, got %s", order.Status)
+	}
+
+	if order.Total.Decimal() != "300.00" {
+		t.Errorf("Expected total 300.00, got %s", order.Total)
+	}
+}
+
+func TestNewOrder_MixedCurrencies(t *testing.T) {
+	items := []CartItem{
+		{ProductID: 1, Quantity: 1, UnitPrice: money.MustParse("10", "BRL")},
+		{ProductID: 2, Quantity: 1, UnitPrice: money.MustParse("10", "USD")},
+	}
+
+	if _, err := NewOrder(7, "BRL", items); err == nil {
+		t.Error("Expected error for mixed currencies")
+	}
+}
@@ -1,16 +1,20 @@
 package models
 
+import "echo-playground/pkg/money"
+
 // Product representa um produto no sistema
 type Product struct {
-	ID          int     `json:"id" xml:"id"`
-	Name        string  `json:"name" xml:"name"`
-	Price       float64 `json:"price" xml:"price"`
-	Description string  `json:"description" xml:"description"`
-	Category    string  `json:"category" xml:"category"`
+	ID          int            `json:"id" xml:"id"`
+	Name        string         `json:"name" xml:"name"`
+	Price       money.Money    `json:"price" xml:"price"`
+	Description string         `json:"description" xml:"description"`
+	Category    string         `json:"category" xml:"category"`
+	Stock       int            `json:"stock" xml:"stock"`
+	Rating      *RatingSummary `json:"rating,omitempty" xml:"rating,omitempty"`
 }
 
 // NewProduct cria um novo produto
-func NewProduct(name, description, category string, price float64) *Product {
+func NewProduct(name, description, category string, price money.Money) *Product {
 	return &Product{
 		Name:        name,
 		Price:       price,
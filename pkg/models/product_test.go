@@ -2,13 +2,15 @@ package models
 
 import (
 	"testing"
+
+	"echo-playground/pkg/money"
 )
 
 func TestNewProduct(t *testing.T) {
 	name := "Laptop Test"
 	description := "Laptop para testes"
 	category := "Eletrônicos"
-	price := 2999.99
+	price := money.MustParse("2999.99", "BRL")
 
 	product := NewProduct(name, description, category, price)
 
@@ -25,7 +27,7 @@ func TestNewProduct(t *testing.T) {
 	}
 
 	if product.Price != price {
-		t.Errorf("Expected price %.2f, got %.2f", price, product.Price)
+		t.Errorf("Expected price %s, got %s", price, product.Price)
 	}
 
 	if product.ID != 0 {
@@ -34,7 +36,7 @@ func TestNewProduct(t *testing.T) {
 }
 
 func TestProductSetID(t *testing.T) {
-	product := NewProduct("Test Product", "Description", "Category", 100.0)
+	product := NewProduct("Test Product", "Description", "Category", money.MustParse("100", "BRL"))
 	expectedID := 123
 
 	product.SetID(expectedID)
@@ -50,25 +52,25 @@ func TestProductValidation(t *testing.T) {
 		productName string
 		description string
 		category    string
-		price       float64
+		price       string
 		expectValid bool
 	}{
-		{"Valid product", "Laptop", "Gaming laptop", "Electronics", 1999.99, true},
-		{"Empty name", "", "Description", "Category", 100.0, false},
-		{"Empty description", "Product", "", "Category", 100.0, false},
-		{"Empty category", "Product", "Description", "", 100.0, false},
-		{"Negative price", "Product", "Description", "Category", -10.0, false},
-		{"Zero price", "Product", "Description", "Category", 0.0, false},
+		{"Valid product", "Laptop", "Gaming laptop", "Electronics", "1999.99", true},
+		{"Empty name", "", "Description", "Category", "100", false},
+		{"Empty description", "Product", "", "Category", "100", false},
+		{"Empty category", "Product", "Description", "", "100", false},
+		{"Negative price", "Product", "Description", "Category", "-10", false},
+		{"Zero price", "Product", "Description", "Category", "0", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			product := NewProduct(tt.productName, tt.description, tt.category, tt.price)
+			product := NewProduct(tt.productName, tt.description, tt.category, money.MustParse(tt.price, "BRL"))
 
 			isValid := product.Name != "" &&
 				product.Description != "" &&
 				product.Category != "" &&
-				product.Price > 0
+				product.Price.IsPositive()
 
 			if isValid != tt.expectValid {
 				t.Errorf("Expected valid=%t, got valid=%t for %s", tt.expectValid, isValid, tt.name)
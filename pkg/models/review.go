@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Limites da nota de uma avaliação
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Review representa a avaliação de um produto feita por um usuário
+type Review struct {
+	ID             int       `json:"id" xml:"id"`
+	ProductID      int       `json:"product_id" xml:"product_id"`
+	UserID         int       `json:"user_id" xml:"user_id"`
+	Rating         int       `json:"rating" xml:"rating"`
+	Text           string    `json:"text" xml:"text"`
+	Hidden         bool      `json:"hidden" xml:"hidden"`
+	ModerationNote string    `json:"moderation_note,omitempty" xml:"moderation_note,omitempty"`
+	CreatedAt      time.Time `json:"created_at" xml:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// NewReview cria uma nova avaliação visível
+func NewReview(productID, userID, rating int, text string) *Review {
+	now := time.Now()
+	return &Review{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    rating,
+		Text:      text,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ValidRating informa se a nota está entre MinRating e MaxRating
+func (r *Review) ValidRating() bool {
+	return r.Rating >= MinRating && r.Rating <= MaxRating
+}
+
+// RatingSummary agrega as notas visíveis de um produto
+type RatingSummary struct {
+	Average float64 `json:"average" xml:"average"`
+	Count   int     `json:"count" xml:"count"`
+}
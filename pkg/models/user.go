@@ -1,14 +1,24 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 // User representa um usuário no sistema
 type User struct {
-	ID      int    `json:"id" xml:"id"`
-	Name    string `json:"name" xml:"name"`
-	Email   string `json:"email" xml:"email"`
-	Age     int    `json:"age" xml:"age"`
-	Created string `json:"created" xml:"created"`
+	ID                int    `json:"id" xml:"id"`
+	Name              string `json:"name" xml:"name"`
+	Email             string `json:"email" xml:"email"`
+	Age               int    `json:"age" xml:"age"`
+	Role              string `json:"role,omitempty" xml:"role,omitempty"`
+	EmailVerified     bool   `json:"email_verified" xml:"email_verified"`
+	PendingEmail      string `json:"pending_email,omitempty" xml:"pending_email,omitempty"`
+	VerificationToken string `json:"-" xml:"-"`
+	PasswordHash      string `json:"-" xml:"-"`
+	Created           string `json:"created" xml:"created"`
+	Updated           string `json:"updated,omitempty" xml:"updated,omitempty"`
 }
 
 // NewUser cria um novo usuário com timestamp
@@ -21,6 +31,25 @@ func NewUser(name, email string, age int) *User {
 	}
 }
 
+// SetPassword guarda o hash bcrypt da senha; a senha em si não é armazenada
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// CheckPassword informa se a senha confere com o hash guardado. Usuários
+// sem senha definida não passam na verificação.
+func (u *User) CheckPassword(password string) bool {
+	if u.PasswordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
 // SetID define o ID do usuário
 func (u *User) SetID(id int) {
 	u.ID = id
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Eventos que podem ser assinados por um webhook. WebhookEventAll assina
+// todos; WebhookEventPing é enviado apenas sob demanda, para testar o
+// destino.
+const (
+	WebhookEventAll            = "*"
+	WebhookEventPing           = "ping"
+	WebhookEventProductCreated = "product.created"
+	WebhookEventProductUpdated = "product.updated"
+	WebhookEventProductDeleted = "product.deleted"
+	WebhookEventUserCreated    = "user.created"
+	WebhookEventUserUpdated    = "user.updated"
+	WebhookEventUserDeleted    = "user.deleted"
+)
+
+// WebhookEvents lista os eventos aceitos na assinatura de um webhook
+var WebhookEvents = []string{
+	WebhookEventAll,
+	WebhookEventProductCreated,
+	WebhookEventProductUpdated,
+	WebhookEventProductDeleted,
+	WebhookEventUserCreated,
+	WebhookEventUserUpdated,
+	WebhookEventUserDeleted,
+}
+
+// ValidWebhookEvent informa se o evento pode ser assinado
+func ValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook é a assinatura de um sistema externo que recebe, por POST na URL
+// informada, os eventos listados em Events. Secret assina cada entrega e
+// nunca é serializado.
+type Webhook struct {
+	ID        string    `json:"id" xml:"id"`
+	URL       string    `json:"url" xml:"url"`
+	Events    []string  `json:"events" xml:"events"`
+	Secret    string    `json:"-" xml:"-"`
+	Active    bool      `json:"active" xml:"active"`
+	CreatedAt time.Time `json:"created_at" xml:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" xml:"updated_at"`
+}
+
+// Subscribed informa se o webhook está ativo e assina o evento
+func (w *Webhook) Subscribed(event string) bool {
+	if !w.Active {
+		return false
+	}
+	for _, e := range w.Events {
+		if e == event || e == WebhookEventAll {
+			return true
+		}
+	}
+	return false
+}
+
+// Situações de uma entrega. Entregas pending e retrying aguardam uma
+// tentativa em NextAttemptAt; dead indica que todas as tentativas falharam
+// (dead-letter) e a entrega só volta a ser enviada por um reenvio manual.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryRetrying  = "retrying"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookDelivery é o envio de um evento a um webhook. Payload é o corpo
+// enviado, idêntico em todas as tentativas. TraceContext guarda o
+// traceparent da operação que gerou a entrega, para que as tentativas,
+// feitas em segundo plano, continuem a mesma trace.
+type WebhookDelivery struct {
+	ID            string            `json:"id" xml:"id"`
+	WebhookID     string            `json:"webhook_id" xml:"webhook_id"`
+	Event         string            `json:"event" xml:"event"`
+	Payload       json.RawMessage   `json:"payload" xml:"-"`
+	Status        string            `json:"status" xml:"status"`
+	Attempts      []WebhookAttempt  `json:"attempts" xml:"attempts"`
+	NextAttemptAt *time.Time        `json:"next_attempt_at,omitempty" xml:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time        `json:"delivered_at,omitempty" xml:"delivered_at,omitempty"`
+	CreatedAt     time.Time         `json:"created_at" xml:"created_at"`
+	TraceContext  map[string]string `json:"-" xml:"-"`
+}
+
+// Due informa se a entrega aguarda uma tentativa até o instante informado
+func (d *WebhookDelivery) Due(now time.Time) bool {
+	if d.Status != WebhookDeliveryPending && d.Status != WebhookDeliveryRetrying {
+		return false
+	}
+	return d.NextAttemptAt != nil && !d.NextAttemptAt.After(now)
+}
+
+// WebhookAttempt registra uma tentativa de entrega. StatusCode é zero quando
+// o destino não respondeu.
+type WebhookAttempt struct {
+	At         time.Time `json:"at" xml:"at"`
+	StatusCode int       `json:"status_code,omitempty" xml:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" xml:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms" xml:"duration_ms"`
+}
@@ -0,0 +1,116 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Converter converte valores entre moedas usando uma tabela estática de
+// cotações. Cada cotação indica quantas unidades da moeda valem uma
+// unidade da moeda base.
+type Converter struct {
+	base  string
+	rates map[string]*big.Rat
+}
+
+// NewConverter cria um conversor a partir de cotações decimais em texto,
+// ex.: {"USD": "0.18"} para base BRL
+func NewConverter(base string, rates map[string]string) (*Converter, error) {
+	baseCurrency, err := LookupCurrency(base)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Converter{
+		base:  baseCurrency.Code,
+		rates: map[string]*big.Rat{baseCurrency.Code: big.NewRat(1, 1)},
+	}
+
+	for code, value := range rates {
+		currency, err := LookupCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+
+		rate, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("cotação inválida para %s: %q", currency.Code, value)
+		}
+		c.rates[currency.Code] = rate
+	}
+
+	return c, nil
+}
+
+// Base retorna a moeda base do conversor
+func (c *Converter) Base() string {
+	return c.base
+}
+
+// Supports informa se há cotação para a moeda informada
+func (c *Converter) Supports(currency string) bool {
+	_, ok := c.rates[strings.ToUpper(currency)]
+	return ok
+}
+
+// Convert converte o valor para a moeda informada, arredondando a
+// unidade menor resultante para o inteiro mais próximo (meio para longe do zero)
+func (c *Converter) Convert(m Money, to string) (Money, error) {
+	target, err := LookupCurrency(to)
+	if err != nil {
+		return Money{}, err
+	}
+	if m.Currency == target.Code {
+		return m, nil
+	}
+
+	source, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	fromRate, ok := c.rates[source.Code]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: sem cotação para %s", ErrUnknownCurrency, source.Code)
+	}
+	toRate, ok := c.rates[target.Code]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: sem cotação para %s", ErrUnknownCurrency, target.Code)
+	}
+
+	// valor na moeda destino = amount / 10^exp_origem / cotação_origem * cotação_destino * 10^exp_destino
+	value := new(big.Rat).SetInt64(m.Amount)
+	value.Quo(value, new(big.Rat).SetInt(pow10(source.Exponent)))
+	value.Quo(value, fromRate)
+	value.Mul(value, toRate)
+	value.Mul(value, new(big.Rat).SetInt(pow10(target.Exponent)))
+
+	amount := roundHalfAwayFromZero(value)
+	if !amount.IsInt64() {
+		return Money{}, fmt.Errorf("%w: resultado fora do intervalo", ErrInvalidAmount)
+	}
+
+	return Money{Amount: amount.Int64(), Currency: target.Code}, nil
+}
+
+// pow10 retorna 10^n como big.Int
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// roundHalfAwayFromZero arredonda um racional para o inteiro mais próximo
+func roundHalfAwayFromZero(r *big.Rat) *big.Int {
+	num := new(big.Int).Abs(r.Num())
+	den := r.Denom()
+
+	// (2*num + den) / (2*den) arredonda o valor absoluto para cima em .5
+	twice := new(big.Int).Mul(num, big.NewInt(2))
+	twice.Add(twice, den)
+	result := twice.Quo(twice, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if r.Sign() < 0 {
+		result.Neg(result)
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestConverter(t *testing.T) *Converter {
+	t.Helper()
+	c, err := NewConverter("BRL", map[string]string{
+		"USD": "0.20",
+		"JPY": "30",
+		"KWD": "0.06",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create converter: %v", err)
+	}
+	return c
+}
+
+func TestConverter_Convert(t *testing.T) {
+	c := newTestConverter(t)
+
+	tests := []struct {
+		from     Money
+		to       string
+		expected Money
+	}{
+		{MustParse("100.00", "BRL"), "USD", MustParse("20.00", "USD")},
+		{MustParse("20.00", "USD"), "BRL", MustParse("100.00", "BRL")},
+		{MustParse("10.00", "BRL"), "JPY", MustParse("300", "JPY")},
+		{MustParse("300", "JPY"), "USD", MustParse("2.00", "USD")},
+		{MustParse("0.01", "BRL"), "JPY", MustParse("0", "JPY")},
+		{MustParse("0.02", "BRL"), "JPY", MustParse("1", "JPY")},
+		{MustParse("1.00", "BRL"), "KWD", MustParse("0.060", "KWD")},
+		{MustParse("89.99", "BRL"), "USD", MustParse("18.00", "USD")},
+		{MustParse("-89.99", "BRL"), "USD", MustParse("-18.00", "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"->"+tt.to, func(t *testing.T) {
+			got, err := c.Convert(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConverter_MissingRate(t *testing.T) {
+	c := newTestConverter(t)
+
+	if _, err := c.Convert(MustParse("1", "BRL"), "GBP"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Expected ErrUnknownCurrency, got %v", err)
+	}
+
+	if c.Supports("GBP") {
+		t.Error("Expected GBP to be unsupported")
+	}
+}
+
+func TestNewConverter_InvalidRate(t *testing.T) {
+	if _, err := NewConverter("BRL", map[string]string{"USD": "-1"}); err == nil {
+		t.Error("Expected error for negative rate")
+	}
+
+	if _, err := NewConverter("BRL", map[string]string{"XYZ": "1"}); err == nil {
+		t.Error("Expected error for unknown currency")
+	}
+}
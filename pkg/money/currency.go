@@ -0,0 +1,42 @@
+package money
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Currency descreve uma moeda ISO 4217 e a quantidade de casas decimais
+// das suas unidades menores (centavos, pence, etc.)
+type Currency struct {
+	Code     string
+	Exponent int
+	Name     string
+}
+
+// currencies contém as moedas ISO 4217 suportadas
+var currencies = map[string]Currency{
+	"ARS": {"ARS", 2, "Peso argentino"},
+	"AUD": {"AUD", 2, "Dólar australiano"},
+	"BHD": {"BHD", 3, "Dinar bareinita"},
+	"BRL": {"BRL", 2, "Real brasileiro"},
+	"CAD": {"CAD", 2, "Dólar canadense"},
+	"CHF": {"CHF", 2, "Franco suíço"},
+	"CLP": {"CLP", 0, "Peso chileno"},
+	"CNY": {"CNY", 2, "Yuan chinês"},
+	"EUR": {"EUR", 2, "Euro"},
+	"GBP": {"GBP", 2, "Libra esterlina"},
+	"INR": {"INR", 2, "Rúpia indiana"},
+	"JPY": {"JPY", 0, "Iene japonês"},
+	"KWD": {"KWD", 3, "Dinar kuwaitiano"},
+	"MXN": {"MXN", 2, "Peso mexicano"},
+	"USD": {"USD", 2, "Dólar americano"},
+}
+
+// LookupCurrency retorna a moeda correspondente ao código ISO 4217
+func LookupCurrency(code string) (Currency, error) {
+	c, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
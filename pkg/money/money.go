@@ -0,0 +1,200 @@
+// Package money representa valores monetários em unidades menores inteiras,
+// evitando os erros de arredondamento de float64.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Erros retornados pelo pacote
+var (
+	ErrUnknownCurrency  = errors.New("moeda desconhecida")
+	ErrCurrencyMismatch = errors.New("moedas diferentes")
+	ErrInvalidAmount    = errors.New("valor monetário inválido")
+)
+
+// Money é um valor em unidades menores (ex.: centavos) de uma moeda ISO 4217
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// New cria um valor monetário validando o código da moeda
+func New(amount int64, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: c.Code}, nil
+}
+
+// Zero retorna o valor zero na moeda informada
+func Zero(currency string) Money {
+	return Money{Currency: strings.ToUpper(currency)}
+}
+
+// Parse converte uma string decimal como "2999.99" em Money sem passar
+// por float64. Casas decimais além das suportadas pela moeda são recusadas.
+func Parse(value, currency string) (Money, error) {
+	c, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value = strings.TrimSpace(value)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, frac, hasFrac := strings.Cut(value, ".")
+	if whole == "" || (hasFrac && frac == "") || len(frac) > c.Exponent {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	frac += strings.Repeat("0", c.Exponent-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || strings.ContainsAny(whole+frac, "+-") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: c.Code}, nil
+}
+
+// MustParse é como Parse, mas entra em pânico em caso de erro
+func MustParse(value, currency string) Money {
+	m, err := Parse(value, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Decimal formata o valor com as casas decimais da moeda, ex.: "2999.99"
+func (m Money) Decimal() string {
+	exp := 2
+	if c, err := LookupCurrency(m.Currency); err == nil {
+		exp = c.Exponent
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String formata o valor com o código da moeda, ex.: "2999.99 BRL"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Add soma dois valores da mesma moeda
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s e %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Mul multiplica o valor por uma quantidade inteira
+func (m Money) Mul(quantity int64) Money {
+	return Money{Amount: m.Amount * quantity, Currency: m.Currency}
+}
+
+// IsPositive informa se o valor é maior que zero
+func (m Money) IsPositive() bool {
+	return m.Amount > 0
+}
+
+// Valid informa se a moeda do valor é suportada
+func (m Money) Valid() bool {
+	_, err := LookupCurrency(m.Currency)
+	return err == nil
+}
+
+// jsonMoney é a representação JSON de Money
+type jsonMoney struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+	Display  string      `json:"display,omitempty"`
+}
+
+// MarshalJSON codifica o valor como {"amount":299999,"currency":"BRL","display":"2999.99"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMoney{
+		Amount:   json.Number(strconv.FormatInt(m.Amount, 10)),
+		Currency: m.Currency,
+		Display:  m.Decimal(),
+	})
+}
+
+// UnmarshalJSON decodifica o valor exigindo amount inteiro em unidades menores
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var v jsonMoney
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%w: esperado objeto {amount, currency}", ErrInvalidAmount)
+	}
+
+	amount, err := strconv.ParseInt(v.Amount.String(), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: amount deve ser um inteiro em unidades menores", ErrInvalidAmount)
+	}
+
+	parsed, err := New(amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// xmlMoney é a representação XML de Money
+type xmlMoney struct {
+	Amount   int64  `xml:"amount,attr"`
+	Currency string `xml:"currency,attr"`
+	Display  string `xml:",chardata"`
+}
+
+// MarshalXML codifica o valor como <price amount="299999" currency="BRL">2999.99</price>
+func (m Money) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(xmlMoney{Amount: m.Amount, Currency: m.Currency, Display: m.Decimal()}, start)
+}
+
+// UnmarshalXML decodifica o valor a partir dos atributos amount e currency
+func (m *Money) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v xmlMoney
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	parsed, err := New(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,140 @@
+package money
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		expected int64
+		wantErr  bool
+	}{
+		{"2999.99", "BRL", 299999, false},
+		{"0.1", "USD", 10, false},
+		{"100", "BRL", 10000, false},
+		{"-1.50", "EUR", -150, false},
+		{"1500", "JPY", 1500, false},
+		{"1.234", "KWD", 1234, false},
+		{"1.234", "BRL", 0, true},
+		{"1.5", "JPY", 0, true},
+		{"abc", "BRL", 0, true},
+		{"1.", "BRL", 0, true},
+		{"+1", "BRL", 0, true},
+		{"10", "XYZ", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.currency, func(t *testing.T) {
+			m, err := Parse(tt.value, tt.currency)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %v", m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if m.Amount != tt.expected {
+				t.Errorf("Expected amount %d, got %d", tt.expected, m.Amount)
+			}
+		})
+	}
+}
+
+func TestMoney_Decimal(t *testing.T) {
+	tests := []struct {
+		money    Money
+		expected string
+	}{
+		{Money{299999, "BRL"}, "2999.99"},
+		{Money{5, "USD"}, "0.05"},
+		{Money{-150, "EUR"}, "-1.50"},
+		{Money{1500, "JPY"}, "1500"},
+		{Money{1, "KWD"}, "0.001"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+}
+
+func TestMoney_SumHasNoFloatDrift(t *testing.T) {
+	total := Zero("BRL")
+	dime := MustParse("0.10", "BRL")
+	for i := 0; i < 10; i++ {
+		total, _ = total.Add(dime)
+	}
+
+	if total.Decimal() != "1.00" {
+		t.Errorf("Expected 1.00, got %s", total.Decimal())
+	}
+}
+
+func TestMoney_AddCurrencyMismatch(t *testing.T) {
+	_, err := MustParse("1", "BRL").Add(MustParse("1", "USD"))
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(MustParse("89.99", "BRL"))
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	expected := `{"amount":8999,"currency":"BRL","display":"89.99"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	var m Money
+	if err := json.Unmarshal([]byte(`{"amount":1050,"currency":"usd"}`), &m); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if m.Amount != 1050 || m.Currency != "USD" {
+		t.Errorf("Unexpected value: %+v", m)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":10.5,"currency":"USD"}`), &m); err == nil {
+		t.Error("Expected error for fractional minor units")
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":10,"currency":"XYZ"}`), &m); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestMoney_XML(t *testing.T) {
+	type product struct {
+		XMLName xml.Name `xml:"product"`
+		Price   Money    `xml:"price"`
+	}
+
+	data, err := xml.Marshal(product{Price: MustParse("2999.99", "BRL")})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+
+	expected := `<product><price amount="299999" currency="BRL">2999.99</price></product>`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	var decoded product
+	if err := xml.NewDecoder(strings.NewReader(expected)).Decode(&decoded); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if decoded.Price != MustParse("2999.99", "BRL") {
+		t.Errorf("Unexpected value: %+v", decoded.Price)
+	}
+}
@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Command executa um antivírus externo, enviando o conteúdo pela entrada
+// padrão. Segue a convenção do ClamAV (clamscan/clamdscan): código de saída
+// 0 indica conteúdo limpo, 1 indica ameaça encontrada e qualquer outro
+// valor, falha na verificação.
+type Command struct {
+	Args    []string
+	Timeout time.Duration
+}
+
+// maxCommandOutput limita a saída guardada do comando
+const maxCommandOutput = 4 << 10
+
+// foundPattern extrai o nome da ameaça de linhas como "stream: Nome FOUND"
+var foundPattern = regexp.MustCompile(`:\s*(.+?)\s+FOUND`)
+
+// Scan implementa Scanner
+func (s *Command) Scan(ctx context.Context, r io.Reader) (Verdict, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	output := &limitedBuffer{max: maxCommandOutput}
+	cmd := exec.CommandContext(ctx, s.Args[0], s.Args[1:]...)
+	cmd.Stdin = r
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return Verdict{}, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && ctx.Err() == nil:
+		return Verdict{Infected: true, Threat: threatName(output.String())}, nil
+	}
+	return Verdict{}, fmt.Errorf("scanner: %s: %w: %s", s.Args[0], err, strings.TrimSpace(output.String()))
+}
+
+// threatName obtém o nome da ameaça da saída do comando
+func threatName(output string) string {
+	if m := foundPattern.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	if line, _, _ := strings.Cut(strings.TrimSpace(output), "\n"); line != "" {
+		return line
+	}
+	return "desconhecida"
+}
+
+// limitedBuffer descarta o que exceder max bytes
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// EICARSignature é o arquivo de teste padrão EICAR, reconhecido por
+// antivírus como uma ameaça inofensiva
+const EICARSignature = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// EICARThreat é o nome da ameaça reportada pelo scanner EICAR
+const EICARThreat = "Eicar-Test-Signature"
+
+// EICAR é um scanner de teste que detecta apenas a assinatura EICAR, em
+// qualquer posição do conteúdo
+type EICAR struct{}
+
+// Scan implementa Scanner
+func (EICAR) Scan(ctx context.Context, r io.Reader) (Verdict, error) {
+	signature := []byte(EICARSignature)
+	buf := make([]byte, 32<<10)
+
+	// window guarda o final do bloco anterior, para encontrar a assinatura
+	// mesmo quando ela é dividida entre duas leituras
+	var window []byte
+	for {
+		if err := ctx.Err(); err != nil {
+			return Verdict{}, err
+		}
+
+		n, err := r.Read(buf)
+		if n > 0 {
+			window = append(window, buf[:n]...)
+			if bytes.Contains(window, signature) {
+				return Verdict{Infected: true, Threat: EICARThreat}, nil
+			}
+			if keep := len(signature) - 1; len(window) > keep {
+				window = append(window[:0], window[len(window)-keep:]...)
+			}
+		}
+		if err == io.EOF {
+			return Verdict{}, nil
+		}
+		if err != nil {
+			return Verdict{}, err
+		}
+	}
+}
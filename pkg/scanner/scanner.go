@@ -0,0 +1,54 @@
+// Package scanner verifica o conteúdo dos arquivos enviados em busca de
+// malware, com um scanner de teste baseado na assinatura EICAR e um scanner
+// que executa um antivírus externo.
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"echo-playground/pkg/config"
+)
+
+// ErrUnknownDriver indica um driver de verificação não suportado
+var ErrUnknownDriver = errors.New("scanner: driver desconhecido")
+
+// Verdict é o resultado da verificação de um conteúdo
+type Verdict struct {
+	Infected bool
+	Threat   string
+}
+
+// Scanner é implementado pelos verificadores de conteúdo
+type Scanner interface {
+	// Scan lê o conteúdo e informa se ele está infectado. Um erro indica
+	// que a verificação não pôde ser concluída.
+	Scan(ctx context.Context, r io.Reader) (Verdict, error)
+}
+
+// DefaultTimeout é o tempo máximo de uma verificação por comando externo
+const DefaultTimeout = 30 * time.Second
+
+// Open cria o scanner selecionado na configuração. Driver vazio desativa a
+// verificação e retorna nil.
+func Open(cfg config.ScanConfig) (Scanner, error) {
+	switch cfg.Driver {
+	case "":
+		return nil, nil
+	case "eicar":
+		return EICAR{}, nil
+	case "command":
+		if len(cfg.Command) == 0 {
+			return nil, errors.New("scanner: comando não configurado")
+		}
+		timeout := cfg.Timeout
+		if timeout <= 0 {
+			timeout = DefaultTimeout
+		}
+		return &Command{Args: cfg.Command, Timeout: timeout}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownDriver, cfg.Driver)
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"echo-playground/pkg/config"
+)
+
+func TestEICAR_Scan(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		infected bool
+	}{
+		{"limpo", "conteúdo comum", false},
+		{"assinatura", EICARSignature, true},
+		{"assinatura no meio", strings.Repeat("a", 40000) + EICARSignature + "fim", true},
+		{"assinatura incompleta", EICARSignature[:40], false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// OneByteReader divide a assinatura entre várias leituras
+			verdict, err := EICAR{}.Scan(context.Background(), iotest.OneByteReader(strings.NewReader(tt.content)))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if verdict.Infected != tt.infected {
+				t.Errorf("Expected infected=%v, got %+v", tt.infected, verdict)
+			}
+		})
+	}
+}
+
+func TestCommand_Scan(t *testing.T) {
+	s := &Command{
+		Args:    []string{"sh", "-c", `if grep -q VIRUS; then echo "stream: Teste.Virus FOUND"; exit 1; fi`},
+		Timeout: 5 * time.Second,
+	}
+
+	verdict, err := s.Scan(context.Background(), strings.NewReader("arquivo limpo"))
+	if err != nil || verdict.Infected {
+		t.Errorf("Expected clean verdict, got %+v, %v", verdict, err)
+	}
+
+	verdict, err = s.Scan(context.Background(), strings.NewReader("contém VIRUS"))
+	if err != nil || !verdict.Infected || verdict.Threat != "Teste.Virus" {
+		t.Errorf("Expected Teste.Virus, got %+v, %v", verdict, err)
+	}
+
+	failing := &Command{Args: []string{"sh", "-c", "echo erro >&2; exit 2"}}
+	if _, err := failing.Scan(context.Background(), strings.NewReader("x")); err == nil || !strings.Contains(err.Error(), "erro") {
+		t.Errorf("Expected scan error with command output, got %v", err)
+	}
+
+	slow := &Command{Args: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond}
+	if _, err := slow.Scan(context.Background(), strings.NewReader("x")); err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	if s, err := Open(config.ScanConfig{}); s != nil || err != nil {
+		t.Errorf("Expected disabled scanner, got %v, %v", s, err)
+	}
+	if s, _ := Open(config.ScanConfig{Driver: "eicar"}); s == nil {
+		t.Error("Expected EICAR scanner")
+	}
+	if _, err := Open(config.ScanConfig{Driver: "command"}); err == nil {
+		t.Error("Expected error without command")
+	}
+	if _, err := Open(config.ScanConfig{Driver: "outro"}); !errors.Is(err, ErrUnknownDriver) {
+		t.Errorf("Expected ErrUnknownDriver, got %v", err)
+	}
+}
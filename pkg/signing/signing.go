@@ -0,0 +1,155 @@
+// Package signing assina links temporários com HMAC-SHA256, permitindo que
+// um recurso seja acessado sem autenticação até a data de expiração.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Parâmetros de query de um link assinado
+const (
+	ParamExpires   = "expires"
+	ParamNonce     = "nonce"
+	ParamSignature = "signature"
+)
+
+// Erros retornados por Verify
+var (
+	ErrInvalidSignature = errors.New("signing: assinatura inválida")
+	ErrExpired          = errors.New("signing: link expirado")
+	ErrAlreadyUsed      = errors.New("signing: link de uso único já utilizado")
+)
+
+// Signer assina e valida links. Links de uso único carregam um nonce, que é
+// registrado por Consume e mantido em memória até expirar.
+type Signer struct {
+	key []byte
+	now func() time.Time
+
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+// NewSigner cria um Signer com a chave informada
+func NewSigner(key []byte) *Signer {
+	return &Signer{key: key, now: time.Now, used: make(map[string]time.Time)}
+}
+
+// RandomKey gera uma chave aleatória de 32 bytes. Links assinados com ela
+// deixam de valer quando o processo é reiniciado.
+func RandomKey() ([]byte, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Signed informa se a query contém uma assinatura
+func Signed(query url.Values) bool {
+	return query.Get(ParamSignature) != ""
+}
+
+// Sign retorna os parâmetros de query que autorizam o acesso ao recurso até
+// expires. Com singleUse, o link só é aceito uma vez.
+func (s *Signer) Sign(resource string, expires time.Time, singleUse bool) (url.Values, error) {
+	query := url.Values{}
+	query.Set(ParamExpires, strconv.FormatInt(expires.Unix(), 10))
+
+	nonce := ""
+	if singleUse {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		nonce = hex.EncodeToString(b)
+		query.Set(ParamNonce, nonce)
+	}
+
+	query.Set(ParamSignature, s.signature(resource, query.Get(ParamExpires), nonce))
+	return query, nil
+}
+
+// Verify confere a assinatura e a validade dos parâmetros para o recurso.
+// Um link de uso único já consumido é recusado; Verify não o consome.
+func (s *Signer) Verify(resource string, query url.Values) error {
+	expiresParam, nonce := query.Get(ParamExpires), query.Get(ParamNonce)
+	expected := s.signature(resource, expiresParam, nonce)
+	if !hmac.Equal([]byte(query.Get(ParamSignature)), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(expiresParam, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !s.now().Before(time.Unix(unix, 0)) {
+		return ErrExpired
+	}
+
+	if nonce == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, used := s.used[nonce]; used {
+		return ErrAlreadyUsed
+	}
+	return nil
+}
+
+// Consume marca um link de uso único como utilizado, retornando
+// ErrAlreadyUsed se outra requisição já o consumiu. Links sem nonce não
+// são afetados. Deve ser chamado depois de Verify.
+func (s *Signer) Consume(query url.Values) error {
+	nonce := query.Get(ParamNonce)
+	if nonce == "" {
+		return nil
+	}
+	unix, err := strconv.ParseInt(query.Get(ParamExpires), 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for n, exp := range s.used {
+		if !now.Before(exp) {
+			delete(s.used, n)
+		}
+	}
+	if _, used := s.used[nonce]; used {
+		return ErrAlreadyUsed
+	}
+	s.used[nonce] = time.Unix(unix, 0)
+	return nil
+}
+
+// Release devolve um link consumido por Consume, para que seja aceito
+// novamente, como quando o download não chega a ser enviado
+func (s *Signer) Release(query url.Values) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.used, query.Get(ParamNonce))
+}
+
+// signature calcula o HMAC dos campos do link, separados por quebra de linha
+func (s *Signer) signature(resource, expires, nonce string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(resource + "\n" + expires + "\n" + nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
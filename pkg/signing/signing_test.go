@@ -0,0 +1,93 @@
+package signing
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	s := NewSigner([]byte("chave"))
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	query, err := s.Sign("file:abc", now.Add(time.Hour), false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !Signed(query) {
+		t.Fatal("Expected query to be signed")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := s.Verify("file:abc", query); err != nil {
+			t.Errorf("Expected reusable link to be valid, got %v", err)
+		}
+	}
+
+	if err := s.Verify("file:outro", query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for another resource, got %v", err)
+	}
+
+	tampered := cloneQuery(query)
+	tampered.Set(ParamExpires, "9999999999")
+	if err := s.Verify("file:abc", tampered); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature for tampered expiry, got %v", err)
+	}
+
+	if err := NewSigner([]byte("outra chave")).Verify("file:abc", query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature with another key, got %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := s.Verify("file:abc", query); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+}
+
+func TestSigner_SingleUse(t *testing.T) {
+	s := NewSigner([]byte("chave"))
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	query, _ := s.Sign("file:abc", now.Add(time.Minute), true)
+	for i := 0; i < 2; i++ {
+		if err := s.Verify("file:abc", query); err != nil {
+			t.Fatalf("Expected link to be valid until consumed, got %v", err)
+		}
+	}
+	if err := s.Consume(query); err != nil {
+		t.Fatalf("Expected first use to be valid, got %v", err)
+	}
+	if err := s.Verify("file:abc", query); !errors.Is(err, ErrAlreadyUsed) {
+		t.Errorf("Expected ErrAlreadyUsed, got %v", err)
+	}
+	if err := s.Consume(query); !errors.Is(err, ErrAlreadyUsed) {
+		t.Errorf("Expected ErrAlreadyUsed on second consume, got %v", err)
+	}
+
+	s.Release(query)
+	if err := s.Verify("file:abc", query); err != nil {
+		t.Errorf("Expected released link to be valid, got %v", err)
+	}
+	_ = s.Consume(query)
+
+	withoutNonce := cloneQuery(query)
+	withoutNonce.Del(ParamNonce)
+	if err := s.Verify("file:abc", withoutNonce); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected ErrInvalidSignature without nonce, got %v", err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	other, _ := s.Sign("file:abc", now.Add(time.Minute), true)
+	_ = s.Consume(other)
+	if len(s.used) != 1 {
+		t.Errorf("Expected expired nonces to be purged, got %d", len(s.used))
+	}
+}
+
+func cloneQuery(q url.Values) url.Values {
+	cp, _ := url.ParseQuery(q.Encode())
+	return cp
+}
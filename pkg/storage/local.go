@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Local armazena os objetos como arquivos abaixo de um diretório raiz
+type Local struct {
+	root string
+}
+
+// NewLocal cria um driver local, criando o diretório raiz se necessário
+func NewLocal(root string) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	abs, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+	return &Local{root: abs}, nil
+}
+
+// Put grava o conteúdo em um arquivo temporário e o renomeia para o destino,
+// de forma que leitores nunca vejam um arquivo incompleto
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (*Object, error) {
+	path, err := l.path(key, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 && written != size {
+		return nil, fmt.Errorf("storage: tamanho esperado %d, recebido %d", size, written)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return l.Stat(ctx, key)
+}
+
+// Get abre o arquivo para leitura
+func (l *Local) Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error) {
+	path, err := l.path(key, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, notFound(err)
+	}
+	info, err := f.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+	return f, l.object(key, info), nil
+}
+
+// Stat retorna os metadados do arquivo
+func (l *Local) Stat(ctx context.Context, key string) (*Object, error) {
+	path, err := l.path(key, true)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, ErrNotFound
+	}
+	return l.object(key, info), nil
+}
+
+// Delete remove o arquivo
+func (l *Local) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key, true)
+	if err != nil {
+		return err
+	}
+	return notFound(os.Remove(path))
+}
+
+// List percorre o diretório raiz e retorna os arquivos com o prefixo
+func (l *Local) List(ctx context.Context, prefix string) ([]Object, error) {
+	objects := []Object{}
+	err := filepath.WalkDir(l.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != l.root {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(l.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *l.object(key, info))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// path converte a chave em um caminho dentro da raiz. Quando existing é
+// verdadeiro, o caminho é resolvido com links simbólicos e precisa
+// continuar dentro da raiz; caso contrário, o diretório pai é verificado.
+func (l *Local) path(key string, existing bool) (string, error) {
+	if !ValidKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	path := filepath.Join(l.root, filepath.FromSlash(key))
+	check := path
+	if !existing {
+		check = filepath.Dir(path)
+		for {
+			if _, err := os.Lstat(check); err == nil || check == l.root {
+				break
+			}
+			check = filepath.Dir(check)
+		}
+	}
+
+	resolved, err := filepath.EvalSymlinks(check)
+	if err != nil {
+		return "", notFound(err)
+	}
+	if !l.inside(resolved) {
+		return "", ErrNotFound
+	}
+	if existing {
+		return resolved, nil
+	}
+	return path, nil
+}
+
+// inside informa se o caminho resolvido está dentro da raiz
+func (l *Local) inside(path string) bool {
+	if path == l.root {
+		return true
+	}
+	rel, err := filepath.Rel(l.root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// object monta os metadados a partir das informações do arquivo
+func (l *Local) object(key string, info fs.FileInfo) *Object {
+	return &Object{
+		Key:         key,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     info.ModTime(),
+		ETag:        fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size()),
+	}
+}
+
+// notFound converte erros de arquivo inexistente em ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+
+	"echo-playground/pkg/config"
+)
+
+// Open cria o driver selecionado na configuração. As credenciais S3 vazias
+// são lidas de AWS_ACCESS_KEY_ID e AWS_SECRET_ACCESS_KEY.
+func Open(cfg config.StorageConfig, uploadDir string) (Storage, error) {
+	switch cfg.Driver {
+	case "", "local":
+		return NewLocal(uploadDir)
+	case "s3":
+		s3 := S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+		}
+		if s3.AccessKey == "" {
+			s3.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		}
+		if s3.SecretKey == "" {
+			s3.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		}
+		return NewS3(s3, nil)
+	}
+	return nil, fmt.Errorf("storage: driver desconhecido: %q", cfg.Driver)
+}
@@ -0,0 +1,61 @@
+// Package storage define o armazenamento dos arquivos enviados, com drivers
+// para o sistema de arquivos local e para serviços compatíveis com S3.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Erros retornados pelos drivers de armazenamento
+var (
+	ErrNotFound   = errors.New("storage: objeto não encontrado")
+	ErrInvalidKey = errors.New("storage: chave inválida")
+)
+
+// Object descreve um objeto armazenado
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+	ETag        string
+}
+
+// Storage é implementado pelos drivers de armazenamento. As chaves usam "/"
+// como separador e não podem conter segmentos vazios, "." ou "..".
+type Storage interface {
+	// Put grava o conteúdo sob a chave informada, substituindo o objeto
+	// existente. size pode ser -1 quando o tamanho não é conhecido.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (*Object, error)
+
+	// Get abre o objeto para leitura. O reader permite Seek, o que viabiliza
+	// respostas com Range.
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error)
+
+	// Stat retorna os metadados do objeto
+	Stat(ctx context.Context, key string) (*Object, error)
+
+	// Delete remove o objeto
+	Delete(ctx context.Context, key string) error
+
+	// List retorna os objetos cujas chaves começam com o prefixo, em ordem
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// ValidKey informa se a chave é aceita pelos drivers. Segmentos iniciados
+// por "." são reservados para arquivos temporários.
+func ValidKey(key string) bool {
+	if key == "" || strings.Contains(key, "\\") || strings.ContainsRune(key, 0) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	return true
+}
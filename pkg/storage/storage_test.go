@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testStorage verifica o comportamento comum a todos os drivers
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	obj, err := s.Put(ctx, "docs/relatório 1.txt", strings.NewReader("0123456789"), 10, "text/plain")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if obj.Size != 10 || obj.ETag == "" || obj.ModTime.IsZero() {
+		t.Errorf("Unexpected object: %+v", obj)
+	}
+
+	if _, err := s.Put(ctx, "b.txt", strings.NewReader("tamanho desconhecido"), -1, "text/plain"); err != nil {
+		t.Fatalf("Expected no error for unknown size, got %v", err)
+	}
+	if _, err := s.Put(ctx, "c.txt", strings.NewReader(""), 0, "text/plain"); err != nil {
+		t.Fatalf("Expected no error for empty object, got %v", err)
+	}
+
+	r, _, err := s.Get(ctx, "docs/relatório 1.txt")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := r.Seek(6, io.SeekStart); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "6789" {
+		t.Errorf("Expected 6789 after seek, got %q", data)
+	}
+
+	objects, err := s.List(ctx, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(objects) != 3 || objects[2].Key != "docs/relatório 1.txt" {
+		t.Errorf("Unexpected listing: %+v", objects)
+	}
+
+	objects, _ = s.List(ctx, "docs/")
+	if len(objects) != 1 {
+		t.Errorf("Expected 1 object with prefix, got %d", len(objects))
+	}
+
+	if err := s.Delete(ctx, "b.txt"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := s.Stat(ctx, "b.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+	if err := s.Delete(ctx, "b.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	for _, key := range []string{"", "../x", "a/../b", "/abs", `a\b`, ".oculto", "a//b"} {
+		if _, err := s.Stat(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Expected ErrInvalidKey for %q, got %v", key, err)
+		}
+	}
+}
+
+func TestLocal(t *testing.T) {
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create local storage: %v", err)
+	}
+	testStorage(t, s)
+}
+
+func TestLocal_SymlinkOutsideRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	s, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("Failed to create local storage: %v", err)
+	}
+
+	secret := filepath.Join(parent, "segredo.txt")
+	_ = os.WriteFile(secret, []byte("segredo"), 0o644)
+	_ = os.Symlink(secret, filepath.Join(root, "link.txt"))
+	_ = os.Symlink(parent, filepath.Join(root, "dir"))
+
+	if _, _, err := s.Get(context.Background(), "link.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for symlink outside root, got %v", err)
+	}
+	if _, err := s.Put(context.Background(), "dir/novo.txt", strings.NewReader("x"), 1, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected Put through symlinked dir to fail, got %v", err)
+	}
+}
+
+func TestS3(t *testing.T) {
+	s, fake := newFakeS3(t)
+	testStorage(t, s)
+
+	if fake.ranges == 0 {
+		t.Error("Expected Seek to be served with a Range request")
+	}
+}
+
+func TestS3_BadCredentials(t *testing.T) {
+	s, _ := newFakeS3(t)
+	s.signer.secretKey = "errado"
+
+	if _, err := s.Stat(context.Background(), "a.txt"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected signature error, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"echo-playground/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// traced envolve um driver, abrindo um span para cada operação
+type traced struct {
+	next   Storage
+	driver string
+}
+
+// WithTracing retorna o driver com um span por operação ("storage.Put",
+// "storage.Get"...), com o nome do driver e a chave como atributos. Em Get,
+// o span cobre apenas a abertura do objeto, não a leitura do conteúdo.
+// Operações fora de uma trace, como as dos workers, não geram spans.
+func WithTracing(s Storage, driver string) Storage {
+	return &traced{next: s, driver: driver}
+}
+
+func (t *traced) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("storage.driver", t.driver), attribute.String("storage.operation", operation))
+	return tracing.StartChild(ctx, "storage."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (t *traced) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (*Object, error) {
+	ctx, span := t.start(ctx, "Put", attribute.String("storage.key", key), attribute.Int64("storage.size", size))
+	obj, err := t.next.Put(ctx, key, r, size, contentType)
+	tracing.End(span, err)
+	return obj, err
+}
+
+func (t *traced) Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error) {
+	ctx, span := t.start(ctx, "Get", attribute.String("storage.key", key))
+	rc, obj, err := t.next.Get(ctx, key)
+	tracing.End(span, err)
+	return rc, obj, err
+}
+
+func (t *traced) Stat(ctx context.Context, key string) (*Object, error) {
+	ctx, span := t.start(ctx, "Stat", attribute.String("storage.key", key))
+	obj, err := t.next.Stat(ctx, key)
+	tracing.End(span, err)
+	return obj, err
+}
+
+func (t *traced) Delete(ctx context.Context, key string) error {
+	ctx, span := t.start(ctx, "Delete", attribute.String("storage.key", key))
+	err := t.next.Delete(ctx, key)
+	tracing.End(span, err)
+	return err
+}
+
+func (t *traced) List(ctx context.Context, prefix string) ([]Object, error) {
+	ctx, span := t.start(ctx, "List", attribute.String("storage.prefix", prefix))
+	objects, err := t.next.List(ctx, prefix)
+	tracing.End(span, err)
+	return objects, err
+}
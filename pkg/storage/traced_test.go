@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"echo-playground/pkg/tracing"
+	"echo-playground/pkg/tracing/tracingtest"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestWithTracing(t *testing.T) {
+	local, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// O driver envolvido continua cumprindo o contrato
+	testStorage(t, WithTracing(local, "local"))
+
+	recorder := tracingtest.Record(t)
+	s := WithTracing(local, "local")
+
+	// Fora de uma trace, nenhum span é criado
+	if _, err := s.Stat(context.Background(), "a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if n := len(recorder.Ended()); n != 0 {
+		t.Fatalf("Expected no spans outside a trace, got %d", n)
+	}
+
+	ctx, parent := tracing.Start(context.Background(), "requisição")
+	if _, err := s.Put(ctx, "a.txt", strings.NewReader("abc"), 3, "text/plain"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_, _ = s.Stat(ctx, "faltando.txt")
+	parent.End()
+
+	put := tracingtest.Find(recorder, "storage.Put")
+	if put == nil || put.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("Expected storage.Put child span")
+	}
+	if tracingtest.Attr(put, "storage.key") != "a.txt" || tracingtest.Attr(put, "storage.driver") != "local" || tracingtest.Attr(put, "storage.size") != "3" {
+		t.Errorf("Unexpected attributes: %v", put.Attributes())
+	}
+
+	stat := tracingtest.Find(recorder, "storage.Stat")
+	if stat == nil || stat.Status().Code != codes.Error {
+		t.Errorf("Expected failed storage.Stat span, got %v", stat)
+	}
+}
@@ -0,0 +1,129 @@
+// Package tracing configura o OpenTelemetry da aplicação: o exportador dos
+// spans (stdout ou OTLP), a amostragem e a propagação W3C traceparent.
+// Com o tracing desativado, os spans não são registrados, mas o contexto
+// recebido no traceparent continua sendo repassado adiante.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"echo-playground/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exportadores aceitos em tracing.exporter
+const (
+	ExporterNone   = ""
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// InstrumentationName identifica os spans criados pela aplicação
+const InstrumentationName = "echo-playground"
+
+// propagator lê e escreve os headers traceparent, tracestate e baggage
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Setup registra o provedor de traces global com o exportador configurado.
+// O exportador stdout escreve um JSON por span em w. A função retornada
+// envia os spans pendentes e encerra o exportador; deve ser chamada ao
+// desligar o servidor.
+func Setup(ctx context.Context, cfg config.TracingConfig, w io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagator)
+
+	if cfg.SampleRatio < 0 || cfg.SampleRatio > 1 {
+		return nil, fmt.Errorf("tracing: sample_ratio deve estar entre 0 e 1, recebido %v", cfg.SampleRatio)
+	}
+
+	var processor sdktrace.SpanProcessor
+	switch strings.ToLower(cfg.Exporter) {
+	case ExporterNone, "none":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, err
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	default:
+		return nil, fmt.Errorf("tracing: exportador desconhecido %q", cfg.Exporter)
+	}
+
+	name := cfg.ServiceName
+	if name == "" {
+		name = InstrumentationName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", name)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(processor),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start abre um span filho do span do contexto com o tracer da aplicação
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, opts...)
+}
+
+// StartChild abre um span apenas se o contexto já pertence a uma trace;
+// caso contrário, retorna o contexto e um span que não registra nada. Evita
+// traces de uma operação só para chamadas feitas fora de requisições.
+func StartChild(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return Start(ctx, name, opts...)
+}
+
+// End registra o erro no span, se houver, e o encerra
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract retorna o contexto com o span remoto lido do carrier, como os
+// headers de uma requisição recebida
+func Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return propagator.Extract(ctx, carrier)
+}
+
+// Inject escreve no carrier o traceparent do span do contexto, como nos
+// headers de uma requisição enviada
+func Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	propagator.Inject(ctx, carrier)
+}
@@ -0,0 +1,113 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"echo-playground/pkg/config"
+	"echo-playground/pkg/tracing/tracingtest"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestSetup_Stdout(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Exporter: "stdout", ServiceName: "teste", SampleRatio: 1}, &buf)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, span := Start(context.Background(), "operação")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("Expected no error on shutdown, got %v", err)
+	}
+
+	var exported struct {
+		Name     string
+		Resource []struct {
+			Key   string
+			Value struct{ Value interface{} }
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &exported); err != nil {
+		t.Fatalf("Expected span as JSON, got %q", buf.String())
+	}
+	if exported.Name != "operação" {
+		t.Errorf("Expected span name operação, got %q", exported.Name)
+	}
+	found := false
+	for _, kv := range exported.Resource {
+		if kv.Key == "service.name" && kv.Value.Value == "teste" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected service.name in resource, got %+v", exported.Resource)
+	}
+}
+
+func TestSetup_Invalid(t *testing.T) {
+	tests := []config.TracingConfig{
+		{Exporter: "jaeger", SampleRatio: 1},
+		{Exporter: "stdout", SampleRatio: 1.5},
+		{Exporter: "stdout", SampleRatio: -0.1},
+	}
+	for _, cfg := range tests {
+		if _, err := Setup(context.Background(), cfg, &bytes.Buffer{}); err == nil {
+			t.Errorf("%+v: expected error", cfg)
+		}
+	}
+
+	shutdown, err := Setup(context.Background(), config.TracingConfig{}, &bytes.Buffer{})
+	if err != nil || shutdown(context.Background()) != nil {
+		t.Errorf("Expected disabled tracing to be accepted, got %v", err)
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	tracingtest.Record(t)
+
+	header := http.Header{}
+	header.Set("traceparent", testTraceparent)
+	ctx := Extract(context.Background(), propagation.HeaderCarrier(header))
+	ctx, span := Start(ctx, "filho")
+	defer span.End()
+
+	out := http.Header{}
+	Inject(ctx, propagation.HeaderCarrier(out))
+	got := out.Get("traceparent")
+	if !strings.HasPrefix(got, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || strings.Contains(got, "00f067aa0ba902b7") {
+		t.Errorf("Expected same trace with the child span ID, got %q", got)
+	}
+}
+
+func TestStartChild(t *testing.T) {
+	recorder := tracingtest.Record(t)
+
+	ctx, span := StartChild(context.Background(), "órfão")
+	span.End()
+	if trace.SpanContextFromContext(ctx).IsValid() || len(recorder.Ended()) != 0 {
+		t.Error("Expected no span outside a trace")
+	}
+
+	parentCtx, parent := Start(context.Background(), "pai")
+	_, child := StartChild(parentCtx, "filho")
+	child.End()
+	parent.End()
+
+	if s := tracingtest.Find(recorder, "filho"); s == nil || s.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("Expected child span of pai, got %v", s)
+	}
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// NewID gera um identificador aleatório em hexadecimal com 32 caracteres
+func NewID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("utils: falha ao gerar ID aleatório: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
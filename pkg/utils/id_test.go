@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestNewID(t *testing.T) {
+	id := NewID()
+
+	if len(id) != 32 {
+		t.Errorf("Expected ID length 32, got %d", len(id))
+	}
+
+	if _, err := hex.DecodeString(id); err != nil {
+		t.Errorf("Expected hex ID, got %s", id)
+	}
+}
+
+func TestNewID_Unique(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := NewID()
+		if seen[id] {
+			t.Fatalf("Duplicate ID generated: %s", id)
+		}
+		seen[id] = true
+	}
+}
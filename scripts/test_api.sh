@@ -107,10 +107,10 @@ test_endpoint "GET" "/products"
 test_endpoint "GET" "/products/1"
 
 # Criar produto
-test_endpoint "POST" "/products" '{"name":"Teclado Mecânico","price":299.99,"description":"Teclado mecânico RGB","category":"Acessórios"}' "Content-Type: application/json"
+test_endpoint "POST" "/products" '{"name":"Teclado Mecânico","price":{"amount":29999,"currency":"BRL"},"description":"Teclado mecânico RGB","category":"Acessórios"}' "Content-Type: application/json"
 
 # Atualizar produto
-test_endpoint "PUT" "/products/1" '{"name":"Laptop Atualizado","price":3499.99,"description":"Laptop de alta performance atualizado","category":"Eletrônicos"}' "Content-Type: application/json"
+test_endpoint "PUT" "/products/1" '{"name":"Laptop Atualizado","price":{"amount":349999,"currency":"BRL"},"description":"Laptop de alta performance atualizado","category":"Eletrônicos"}' "Content-Type: application/json"
 
 # Deletar produto
 test_endpoint "DELETE" "/products/1"